@@ -26,17 +26,17 @@ const (
 var (
 	// path to input data set
 	input string
-	// coma separated map dimensions: 2D only [for now]
+	// coma separated map dimensions: 1D, 2D or 3D
 	dims string
-	// map grid type: planar
+	// map grid type: planar, toroid, cylinder, sphere
 	grid string
-	// map unit shape: hexagon, rectangle
+	// map unit shape: hexagon, rectangle, triangle
 	ushape string
 	// initial unit neihbourhood radius
 	radius float64
 	// radius decay strategy: lin, exp
 	rdecay string
-	// neighbourhood func: gaussian, bubble, mexican
+	// neighbourhood func: gaussian, bubble, cutgauss, epanechnikov, mexican, dog
 	neighb string
 	// initial learning rate
 	lrate float64
@@ -50,8 +50,6 @@ var (
 	training string
 	// number of training iterations
 	iters int
-	// NeighbFuncs maps neighbourhood functions to their implemenbtations
-	NeighbFuncs map[string]som.NeighbFunc
 )
 
 func init() {
@@ -63,6 +61,7 @@ func init() {
 	flag.StringVar(&rdecay, "rdecay", "lin", "Radius decay strategy")
 	flag.Float64Var(&lrate, "lrate", 0.0, "SOM initial learning rate")
 	flag.StringVar(&ldecay, "ldecay", "lin", "Learning rate decay strategy")
+	flag.StringVar(&neighb, "neighb", "gaussian", "SOM neighbourhood function")
 	flag.StringVar(&umatrix, "umatrix", "", "Path to u-matrix output visualization")
 	flag.StringVar(&output, "output", "", "Path to store trained SOM model")
 	flag.StringVar(&training, "training", "seq", "SOM training method")
@@ -220,12 +219,18 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nERROR: %s\n", err)
 		os.Exit(1)
 	}
+	// neighbourhood function
+	neighbFn, err := som.NeighbFuncByName(neighb)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nERROR: %s\n", err)
+		os.Exit(1)
+	}
 	// training configuration
 	trainCfg := &som.TrainConfig{
 		Algorithm: training,
 		Radius:    radius,
 		RDecay:    rdecay,
-		NeighbFn:  som.Gaussian,
+		NeighbFn:  neighbFn,
 		LRate:     lrate,
 		LDecay:    ldecay,
 	}
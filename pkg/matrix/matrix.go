@@ -67,6 +67,24 @@ func MakeRandom(rows, cols int, min, max float64) (*mat64.Dense, error) {
 	})
 }
 
+// MakeRandomSource creates a new matrix with provided number of rows and columns
+// which is initialized to random numbers uniformly distributed in interval [min, max]
+// generated by the provided random number source.
+// MakeRandomSource fails if non-positive matrix dimensions are requested or if src is nil.
+func MakeRandomSource(rows, cols int, min, max float64, src rand.Source) (*mat64.Dense, error) {
+	if src == nil {
+		return nil, fmt.Errorf("invalid random number source: %v", src)
+	}
+	return withValidDims(rows, cols, func() (*mat64.Dense, error) {
+		r := rand.New(src)
+		randVals := make([]float64, rows*cols)
+		for i := range randVals {
+			randVals[i] = r.Float64()*(max-min) + min
+		}
+		return mat64.NewDense(rows, cols, randVals), nil
+	})
+}
+
 // MakeConstant returns a matrix of rows x cols whose each element is set to val.
 // MakeConstant fails if invalid matrix dimensions are requested.
 func MakeConstant(rows, cols int, val float64) (*mat64.Dense, error) {
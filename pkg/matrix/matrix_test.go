@@ -2,6 +2,7 @@ package matrix
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/gonum/floats"
@@ -172,6 +173,29 @@ func TestMakeRandom(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestMakeRandomSource(t *testing.T) {
+	assert := assert.New(t)
+
+	rows, cols := 2, 3
+	min, max := 1.0, 2.0
+	randMx, err := MakeRandomSource(rows, cols, min, max, rand.NewSource(7))
+	assert.NotNil(randMx)
+	assert.NoError(err)
+	assert.True(max >= mat64.Max(randMx))
+	assert.True(min <= mat64.Min(randMx))
+	// the same source seed generates the same matrix
+	sameMx, err := MakeRandomSource(rows, cols, min, max, rand.NewSource(7))
+	assert.NoError(err)
+	assert.True(mat64.Equal(randMx, sameMx))
+	// Can't create new matrix
+	randMx, err = MakeRandomSource(rows, -6, min, max, rand.NewSource(7))
+	assert.Nil(randMx)
+	assert.Error(err)
+	randMx, err = MakeRandomSource(rows, cols, min, max, nil)
+	assert.Nil(randMx)
+	assert.Error(err)
+}
+
 func TestMakeConstant(t *testing.T) {
 	assert := assert.New(t)
 
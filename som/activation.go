@@ -0,0 +1,38 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ActivationMap returns a slice which contains distances of sample to all codebook vectors stored
+// in codebook rows computed using the supplied distance metric, i.e. the response surface of the map
+// to the sample. i-th slice item holds the distance to the codebook vector stored in i-th codebook row.
+// It returns error if either codebook or sample are nil or if their dimensions are mismatched.
+func ActivationMap(codebook *mat64.Dense, sample *mat64.Vector, metric string) ([]float64, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if sample == nil {
+		return nil, fmt.Errorf("invalid sample supplied: %v", sample)
+	}
+	v := mat64.Col(nil, 0, sample)
+	rows, _ := codebook.Dims()
+	dists := make([]float64, rows)
+	for i := range dists {
+		d, err := Distance(metric, v, codebook.RawRowView(i))
+		if err != nil {
+			return nil, err
+		}
+		dists[i] = d
+	}
+
+	return dists, nil
+}
+
+// ActivationMap returns distances of sample to all map units computed using the map distance metric.
+// It returns error if sample is nil or if its dimension is different from the map codebook dimension.
+func (m Map) ActivationMap(sample *mat64.Vector) ([]float64, error) {
+	return ActivationMap(m.codebook, sample, m.metric)
+}
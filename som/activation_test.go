@@ -0,0 +1,38 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivationMap(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2,
+		[]float64{0.0, 0.0,
+			3.0, 4.0,
+			3.0, 5.0})
+	sample := mat64.NewVector(2, []float64{3.0, 4.0})
+	dists, err := ActivationMap(cbook, sample, "euclidean")
+	assert.NoError(err)
+	assert.InDeltaSlice([]float64{5.0, 0.0, 1.0}, dists, 1e-9)
+	// nil parameters
+	dists, err = ActivationMap(nil, sample, "euclidean")
+	assert.Nil(dists)
+	assert.Error(err)
+	dists, err = ActivationMap(cbook, nil, "euclidean")
+	assert.Nil(dists)
+	assert.Error(err)
+	// map activation
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "manhattan"}
+	dists, err = m.ActivationMap(sample)
+	assert.NoError(err)
+	assert.InDeltaSlice([]float64{7.0, 0.0, 1.0}, dists, 1e-9)
+	dists, err = m.ActivationMap(mat64.NewVector(3, nil))
+	assert.Nil(dists)
+	assert.Error(err)
+}
@@ -0,0 +1,148 @@
+package som
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// defaultFrameDur is the duration of animation frames used if no duration is specified
+const defaultFrameDur = 500 * time.Millisecond
+
+// svgAnimate is the SMIL animation of an attribute of its parent SVG element
+type svgAnimate struct {
+	XMLName       xml.Name `xml:"animate"`
+	AttributeName string   `xml:"attributeName,attr"`
+	Values        string   `xml:"values,attr"`
+	Dur           string   `xml:"dur,attr"`
+	CalcMode      string   `xml:"calcMode,attr"`
+	RepeatCount   string   `xml:"repeatCount,attr"`
+}
+
+// TrainAnimation holds the U-Matrix of the map recorded during the training
+type TrainAnimation struct {
+	// UMatrix holds U-Matrix values of the map units recorded before the training and at the end of every epoch
+	UMatrix [][]float64
+	// grid and render are the grid and rendering configuration of the trained map
+	grid   *Grid
+	render *RenderConfig
+}
+
+// Frames returns the number of recorded animation frames
+func (a TrainAnimation) Frames() int {
+	return len(a.UMatrix)
+}
+
+// TrainWithAnimation runs SOM training in the same way as Train and records the U-Matrix of the map before
+// the training and at the end of every training epoch: every iteration of batch training and every as many
+// iterations of sequential training as there are data rows. The U-Matrix is smoothed if the map render
+// configuration requests it. It returns the recorded animation or fails with error if the training fails.
+// The frames recorded until the failure are returned along with the error.
+func (m *Map) TrainWithAnimation(c *TrainConfig, data *mat64.Dense, iters int) (*TrainAnimation, error) {
+	a := &TrainAnimation{
+		UMatrix: [][]float64{},
+		grid:    m.grid,
+		render:  m.render,
+	}
+	s := m.render.style()
+	record := func() error {
+		umatrix, _, _, err := s.umatrixValues(m.codebook, m.grid, m.metric)
+		if err != nil {
+			return err
+		}
+		a.UMatrix = append(a.UMatrix, umatrix)
+		return nil
+	}
+	if err := record(); err != nil {
+		return a, err
+	}
+	epochEnd := func(epoch, iteration int) (bool, error) {
+		if err := record(); err != nil {
+			return true, err
+		}
+		return false, nil
+	}
+	if err := m.train(context.Background(), c, data, iters, epochEnd); err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
+
+// SVG writes an animated SVG of the recorded U-Matrix to w: its units are filled with the colors of the frames
+// in turn, every frame is shown for frameDur and the animation repeats indefinitely. The colors of all frames
+// share the same scale. Viewers which don't support SVG animations show the last frame.
+// If frameDur is 0, every frame is shown for half a second.
+// It fails with error if no frames are recorded, frameDur is negative or if the SVG could not be written.
+func (a TrainAnimation) SVG(w io.Writer, title string, frameDur time.Duration) error {
+	if len(a.UMatrix) == 0 {
+		return fmt.Errorf("no animation frames recorded")
+	}
+	if frameDur < 0 {
+		return fmt.Errorf("invalid frame duration: %v", frameDur)
+	}
+	if frameDur == 0 {
+		frameDur = defaultFrameDur
+	}
+	minValue, maxValue := a.valueRange()
+	s := a.render.style()
+	s.frames, s.frameDur = a.UMatrix, frameDur
+
+	return valuesSVG(a.grid, a.UMatrix[len(a.UMatrix)-1], minValue, maxValue, title, w, s)
+}
+
+// Frame writes i-th recorded U-Matrix frame in a given format to w. The frames share the same color scale,
+// so a sequence of frames shows the evolution of the map. Currently only "svg" and "png" formats are supported.
+// It fails with error if the frame has not been recorded or if the frame could not be rendered.
+func (a TrainAnimation) Frame(w io.Writer, i int, format, title string) error {
+	if i < 0 || i >= len(a.UMatrix) {
+		return fmt.Errorf("invalid animation frame: %d", i)
+	}
+	minValue, maxValue := a.valueRange()
+	switch format {
+	case "svg":
+		return valuesSVG(a.grid, a.UMatrix[i], minValue, maxValue, title, w, a.render.style())
+	case "png":
+		return valuesPNG(a.grid, a.UMatrix[i], minValue, maxValue, w, a.render.style())
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// valueRange returns the minimum and maximum U-Matrix value across all recorded frames
+func (a TrainAnimation) valueRange() (float64, float64) {
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, umatrix := range a.UMatrix {
+		minValue, maxValue = math.Min(minValue, floats.Min(umatrix)), math.Max(maxValue, floats.Max(umatrix))
+	}
+	return minValue, maxValue
+}
+
+// unitAnimation returns the animation of the fill color of the unit stored in row through the frames of s.
+// No animation is returned if s has less than two frames.
+func (s unitStyle) unitAnimation(row int, minValue, maxValue float64) []svgAnimate {
+	if len(s.frames) < 2 {
+		return nil
+	}
+	values := make([]string, len(s.frames))
+	for i, frame := range s.frames {
+		r, g, b := s.unitRGB(row, frame, minValue, maxValue)
+		values[i] = fmt.Sprintf("rgb(%d,%d,%d)", r, g, b)
+	}
+	dur := time.Duration(len(s.frames)) * s.frameDur
+
+	return []svgAnimate{{
+		AttributeName: "fill",
+		Values:        strings.Join(values, ";"),
+		Dur:           fmt.Sprintf("%gs", dur.Seconds()),
+		CalcMode:      "discrete",
+		RepeatCount:   "indefinite",
+	}}
+}
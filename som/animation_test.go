@@ -0,0 +1,74 @@
+package som
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrainWithAnimation(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	tc := *tSom
+	tc.Algorithm = "batch"
+	a, err := m.TrainWithAnimation(&tc, dataMx, 5)
+	assert.NoError(err)
+	// the initial map is the first frame
+	assert.Equal(6, a.Frames())
+	// the last frame matches the trained map
+	umatrix, err := m.UMatrixValues()
+	assert.NoError(err)
+	assert.Equal(umatrix, a.UMatrix[5])
+	// sequential epoch is as long as the data set
+	rows, _ := dataMx.Dims()
+	tc.Algorithm = "seq"
+	a, err = m.TrainWithAnimation(&tc, dataMx, 2*rows)
+	assert.NoError(err)
+	assert.Equal(3, a.Frames())
+	// invalid training parameters
+	a, err = m.TrainWithAnimation(&tc, dataMx, 0)
+	assert.Equal(1, a.Frames())
+	assert.Error(err)
+}
+
+func TestTrainAnimationSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	tc := *tSom
+	tc.Algorithm = "batch"
+	a, err := m.TrainWithAnimation(&tc, dataMx, 3)
+	assert.NoError(err)
+	units := m.Grid().Units()
+	// every unit is animated through all frames
+	var buf bytes.Buffer
+	assert.NoError(a.SVG(&buf, "Training", 0))
+	out := buf.String()
+	assert.Equal(units, strings.Count(out, `<animate attributeName="fill"`))
+	assert.Equal(units, strings.Count(out, `dur="2s" calcMode="discrete" repeatCount="indefinite"`))
+	assert.Contains(out, "<title>Training</title>")
+	assert.NoError(xml.Unmarshal(buf.Bytes(), new(interface{})))
+	buf.Reset()
+	assert.NoError(a.SVG(&buf, "Training", 250*time.Millisecond))
+	assert.Contains(buf.String(), `dur="1s"`)
+	// frames with the same scale
+	buf.Reset()
+	assert.NoError(a.Frame(&buf, 0, "svg", "Frame"))
+	assert.NotContains(buf.String(), "<animate")
+	buf.Reset()
+	assert.NoError(a.Frame(&buf, 3, "png", "Frame"))
+	assert.True(buf.Len() > 0)
+	// invalid parameters
+	assert.Error(a.SVG(&buf, "Training", -time.Second))
+	assert.Error(a.Frame(&buf, 4, "svg", "Frame"))
+	assert.Error(a.Frame(&buf, -1, "svg", "Frame"))
+	assert.Error(a.Frame(&buf, 0, "bmp", "Frame"))
+	assert.Error(TrainAnimation{}.SVG(&buf, "Training", 0))
+}
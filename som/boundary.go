@@ -0,0 +1,104 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ClusterUMatrixSVG creates an SVG representation of the U-Matrix of the given codebook whose units are colored
+// by their clusters, e.g. as returned by KMeans, and whose cells belonging to different clusters are separated
+// by thick boundary strokes. Units with negative cluster IDs are rendered in shades of gray.
+// It accepts the same parameters as UMatrixSVG and clusters - i-th item holds the cluster of i-th codebook vector.
+// It fails with error if the number of clusters is different from the number of codebook vectors
+// or if the grid coordinates could not be computed.
+func ClusterUMatrixSVG(codebook *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, clusters []int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	if rows, _ := coords.Dims(); len(clusters) != rows {
+		return fmt.Errorf("invalid number of unit clusters: %d, expected: %d", len(clusters), rows)
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return umatrixSVG(codebook, grid, metric, title, writer, clusterStyle(unitStyle{}, clusters))
+}
+
+// clusterStyle returns s with units colored by clusters and with the cluster boundaries drawn
+func clusterStyle(s unitStyle, clusters []int) unitStyle {
+	unitClusters := make(map[int]int)
+	for unit, cluster := range clusters {
+		if cluster >= 0 {
+			unitClusters[unit] = cluster
+		}
+	}
+	s.classes = unitClusters
+	s.clusters = clusters
+	return s
+}
+
+// boundaryWidth returns the width of the cluster boundary strokes: three times the width of the unit outlines
+func (s unitStyle) boundaryWidth() float64 {
+	return 3.0 * math.Max(1.0, s.strokeWidth)
+}
+
+// boundaryEdges returns the polygon edges shared by units of different clusters.
+// polygons maps units to their closed polygons. Edges are returned in the order of units and their polygon vertices.
+func (s unitStyle) boundaryEdges(polygons map[int][][2]float64) [][2][2]float64 {
+	if len(s.clusters) == 0 {
+		return nil
+	}
+	units := make([]int, 0, len(polygons))
+	for unit := range polygons {
+		units = append(units, unit)
+	}
+	sort.Ints(units)
+	// edges are identified by their end points regardless of their direction
+	edgeKey := func(a, b [2]float64) string {
+		ka, kb := fmt.Sprintf("%.4f,%.4f", a[0], a[1]), fmt.Sprintf("%.4f,%.4f", b[0], b[1])
+		if kb < ka {
+			ka, kb = kb, ka
+		}
+		return ka + " " + kb
+	}
+	owners := make(map[string]int)
+	var edges [][2][2]float64
+	for _, unit := range units {
+		if unit >= len(s.clusters) {
+			continue
+		}
+		vertices := polygons[unit]
+		for i := 1; i < len(vertices); i++ {
+			key := edgeKey(vertices[i-1], vertices[i])
+			owner, ok := owners[key]
+			if !ok {
+				owners[key] = unit
+				continue
+			}
+			if s.clusters[owner] != s.clusters[unit] {
+				edges = append(edges, [2][2]float64{vertices[i-1], vertices[i]})
+			}
+		}
+	}
+	return edges
+}
+
+// boundarySVG returns SVG lines of the edges shared by units of different clusters
+func (s unitStyle) boundarySVG(polygons map[int][][2]float64) []interface{} {
+	var elems []interface{}
+	style := fmt.Sprintf("stroke:%s;stroke-width:%g;stroke-linecap:round", svgColor(s.strokeColor()), s.boundaryWidth())
+	for _, edge := range s.boundaryEdges(polygons) {
+		a, b := edge[0], edge[1]
+		elems = append(elems, line{X1: a[0], Y1: a[1], X2: b[0], Y2: b[1], Class: "boundary", Style: style})
+	}
+	return elems
+}
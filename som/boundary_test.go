@@ -0,0 +1,53 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterUMatrixSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 0.0, 1.0, 1.0})
+	var buf bytes.Buffer
+	// the two map columns belong to different clusters
+	err := ClusterUMatrixSVG(cbook, []int{2, 2}, "rectangle", "euclidean", "Clusters", &buf, []int{0, 0, 1, 1})
+	assert.NoError(err)
+	svg := buf.String()
+	assert.Equal(2, strings.Count(svg, "<line "))
+	assert.True(strings.Contains(svg, `<line x1="60" y1="10" x2="60" y2="60" style="stroke:rgb(0,0,0);stroke-width:3;stroke-linecap:round"></line>`))
+	assert.True(strings.Contains(svg, `<line x1="60" y1="60" x2="60" y2="110" style="stroke:rgb(0,0,0);stroke-width:3;stroke-linecap:round"></line>`))
+	// hexagonal cells share edges with their diagonal neighbours
+	buf.Reset()
+	err = ClusterUMatrixSVG(cbook, []int{2, 2}, "hexagon", "euclidean", "Clusters", &buf, []int{0, 1, 0, 1})
+	assert.NoError(err)
+	assert.Equal(3, strings.Count(buf.String(), "<line "))
+	// single cluster has no boundaries
+	buf.Reset()
+	err = ClusterUMatrixSVG(cbook, []int{2, 2}, "rectangle", "euclidean", "Clusters", &buf, []int{0, 0, 0, 0})
+	assert.NoError(err)
+	assert.Equal(0, strings.Count(buf.String(), "<line "))
+	// invalid parameters
+	assert.Error(ClusterUMatrixSVG(cbook, []int{2, 2}, "rectangle", "euclidean", "Clusters", &buf, []int{0, 1}))
+	assert.Error(ClusterUMatrixSVG(cbook, []int{2, 2}, "foobar", "euclidean", "Clusters", &buf, []int{0, 0, 1, 1}))
+}
+
+func TestBoundaryEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	polygons := map[int][][2]float64{
+		0: {{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+		1: {{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}},
+	}
+	s := unitStyle{clusters: []int{0, 1}}
+	assert.Equal([][2][2]float64{{{1, 1}, {1, 0}}}, s.boundaryEdges(polygons))
+	assert.Nil(unitStyle{}.boundaryEdges(polygons))
+	assert.Nil(unitStyle{clusters: []int{1, 1}}.boundaryEdges(polygons))
+	// boundaries are thicker than the unit outlines
+	assert.Equal(3.0, unitStyle{}.boundaryWidth())
+	assert.Equal(6.0, unitStyle{strokeWidth: 2.0}.boundaryWidth())
+}
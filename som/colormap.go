@@ -0,0 +1,94 @@
+package som
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// viridisColors holds evenly spaced samples of the viridis color map
+var viridisColors = [][3]float64{
+	{68, 1, 84}, {71, 44, 122}, {59, 81, 139}, {44, 113, 142}, {33, 144, 141},
+	{39, 173, 129}, {92, 200, 99}, {170, 220, 50}, {253, 231, 37},
+}
+
+// heatColors holds the black-red-yellow-white heat color map
+var heatColors = [][3]float64{
+	{0, 0, 0}, {255, 0, 0}, {255, 255, 0}, {255, 255, 255},
+}
+
+// ylGnBuColors holds the colorblind-safe sequential yellow-green-blue ColorBrewer color scheme
+var ylGnBuColors = [][3]float64{
+	{255, 255, 217}, {237, 248, 177}, {199, 233, 180}, {127, 205, 187}, {65, 182, 196},
+	{29, 145, 192}, {34, 94, 168}, {37, 52, 148}, {8, 29, 88},
+}
+
+// puOrColors holds the colorblind-safe diverging orange-purple ColorBrewer color scheme
+var puOrColors = [][3]float64{
+	{127, 59, 8}, {179, 88, 6}, {224, 130, 20}, {253, 184, 99}, {254, 224, 182}, {247, 247, 247},
+	{216, 218, 235}, {178, 171, 210}, {128, 115, 172}, {84, 39, 136}, {45, 0, 75},
+}
+
+// ColorFuncByName returns the color map of the given name: grayscale, inverted, viridis, heat, ylgnbu, puor.
+// It fails with error if the requested color map is not supported.
+func ColorFuncByName(name string) (ColorFunc, error) {
+	cFn, ok := colorMaps[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported color map: %s", name)
+	}
+	return cFn, nil
+}
+
+// Grayscale maps v to shades of gray: 0 is white and 1 is black.
+func Grayscale(v float64) color.RGBA {
+	c := uint8((1.0 - clampUnit(v)) * 255)
+	return color.RGBA{R: c, G: c, B: c, A: 255}
+}
+
+// InvertedGrayscale maps v to shades of gray: 0 is black and 1 is white.
+func InvertedGrayscale(v float64) color.RGBA {
+	c := uint8(clampUnit(v) * 255)
+	return color.RGBA{R: c, G: c, B: c, A: 255}
+}
+
+// Viridis maps v to the perceptually uniform viridis color map: 0 is dark purple and 1 is yellow.
+func Viridis(v float64) color.RGBA {
+	return interpColors(viridisColors, v)
+}
+
+// Heat maps v to the heat color map: 0 is black, 1 is white and the values in between go through red and yellow.
+func Heat(v float64) color.RGBA {
+	return interpColors(heatColors, v)
+}
+
+// YlGnBu maps v to the colorblind-safe sequential color map: 0 is light yellow and 1 is dark blue
+// going through green. It suits values which grow from low to high such as U-Matrix distances.
+func YlGnBu(v float64) color.RGBA {
+	return interpColors(ylGnBuColors, v)
+}
+
+// PuOr maps v to the colorblind-safe diverging color map: 0 is dark orange, 0.5 is light gray and 1 is dark purple.
+// It suits values which deviate in both directions from a meaningful midpoint such as centered component planes.
+func PuOr(v float64) color.RGBA {
+	return interpColors(puOrColors, v)
+}
+
+// interpColors linearly interpolates evenly spaced colors at v
+func interpColors(table [][3]float64, v float64) color.RGBA {
+	pos := clampUnit(v) * float64(len(table)-1)
+	i := int(math.Min(pos, float64(len(table)-2)))
+	t := pos - float64(i)
+	c := [3]uint8{}
+	for j := range c {
+		c[j] = uint8(math.Floor(table[i][j] + t*(table[i+1][j]-table[i][j]) + 0.5))
+	}
+	return color.RGBA{R: c[0], G: c[1], B: c[2], A: 255}
+}
+
+// clampUnit clamps v to [0,1] interval. NaN is mapped to 0.
+func clampUnit(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0.0
+	}
+	return math.Max(0.0, math.Min(1.0, v))
+}
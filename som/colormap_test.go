@@ -0,0 +1,94 @@
+package som
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, Grayscale(0.0))
+	assert.Equal(color.RGBA{R: 0, G: 0, B: 0, A: 255}, Grayscale(1.0))
+	assert.Equal(color.RGBA{R: 0, G: 0, B: 0, A: 255}, InvertedGrayscale(0.0))
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, InvertedGrayscale(1.0))
+	assert.Equal(color.RGBA{R: 68, G: 1, B: 84, A: 255}, Viridis(0.0))
+	assert.Equal(color.RGBA{R: 33, G: 144, B: 141, A: 255}, Viridis(0.5))
+	assert.Equal(color.RGBA{R: 253, G: 231, B: 37, A: 255}, Viridis(1.0))
+	assert.Equal(color.RGBA{R: 0, G: 0, B: 0, A: 255}, Heat(0.0))
+	assert.Equal(color.RGBA{R: 255, G: 128, B: 0, A: 255}, Heat(0.5))
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, Heat(1.0))
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 217, A: 255}, YlGnBu(0.0))
+	assert.Equal(color.RGBA{R: 65, G: 182, B: 196, A: 255}, YlGnBu(0.5))
+	assert.Equal(color.RGBA{R: 8, G: 29, B: 88, A: 255}, YlGnBu(1.0))
+	assert.Equal(color.RGBA{R: 127, G: 59, B: 8, A: 255}, PuOr(0.0))
+	assert.Equal(color.RGBA{R: 247, G: 247, B: 247, A: 255}, PuOr(0.5))
+	assert.Equal(color.RGBA{R: 45, G: 0, B: 75, A: 255}, PuOr(1.0))
+	// values out of range are clamped
+	assert.Equal(Heat(1.0), Heat(2.0))
+	assert.Equal(Viridis(0.0), Viridis(-1.0))
+	assert.Equal(Grayscale(0.0), Grayscale(math.NaN()))
+}
+
+func TestColorFuncByName(t *testing.T) {
+	assert := assert.New(t)
+
+	for name := range colorMaps {
+		cFn, err := ColorFuncByName(name)
+		assert.NoError(err)
+		assert.NotNil(cFn)
+	}
+	// colorblind-safe color maps are selectable by name
+	cFn, err := ColorFuncByName("puor")
+	assert.NoError(err)
+	assert.Equal(PuOr(0.2), cFn(0.2))
+	cFn, err = ColorFuncByName("ylgnbu")
+	assert.NoError(err)
+	assert.Equal(YlGnBu(0.2), cFn(0.2))
+	cFn, err = ColorFuncByName("foobar")
+	assert.Nil(cFn)
+	assert.EqualError(err, "unsupported color map: foobar")
+}
+
+func TestRenderConfigColorFunc(t *testing.T) {
+	assert := assert.New(t)
+
+	var c *RenderConfig
+	assert.Equal(Grayscale(0.3), c.colorFunc()(0.3))
+	c = &RenderConfig{}
+	assert.Equal(Grayscale(0.3), c.colorFunc()(0.3))
+	c.ColorMap = "viridis"
+	assert.Equal(Viridis(0.3), c.colorFunc()(0.3))
+	// custom color map is preferred
+	c.ColorFunc = func(float64) color.RGBA { return color.RGBA{R: 1, G: 2, B: 3, A: 255} }
+	assert.Equal(color.RGBA{R: 1, G: 2, B: 3, A: 255}, c.colorFunc()(0.3))
+}
+
+func TestMapUMatrixColorMap(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(3, 1, []float64{0.0, 1.0, 3.0}),
+		grid:     grid,
+		metric:   "euclidean",
+		render:   &RenderConfig{ColorMap: "heat"},
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Heat"))
+	svg := buf.String()
+	assert.True(strings.Contains(svg, "fill:rgb(0,0,0)"))
+	assert.True(strings.Contains(svg, "fill:rgb(255,255,255)"))
+	// custom color map
+	m.render = &RenderConfig{ColorFunc: func(float64) color.RGBA { return color.RGBA{R: 1, G: 2, B: 3, A: 255} }}
+	buf.Reset()
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Custom"))
+	assert.Equal(3, strings.Count(buf.String(), "fill:rgb(1,2,3)"))
+}
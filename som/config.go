@@ -2,6 +2,10 @@ package som
 
 import (
 	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
 
 	"github.com/gonum/matrix/mat64"
 )
@@ -10,11 +14,15 @@ import (
 var uShapes = map[string]bool{
 	"hexagon":   true,
 	"rectangle": true,
+	"triangle":  true,
 }
 
-// gridTypes maps supported grid types
+// coordsInitFns maps supported grid types to their coordinates initialization functions
 var coordsInitFns = map[string]coordsInitFunc{
-	"planar": GridCoords,
+	"planar":   GridCoords,
+	"toroid":   GridCoords,
+	"cylinder": GridCoords,
+	"sphere":   SphereCoords,
 }
 
 // decays maps supported decay strategies
@@ -22,6 +30,29 @@ var decays = map[string]bool{
 	"lin": true,
 	"exp": true,
 	"inv": true,
+	"pow": true,
+}
+
+// neighbFns maps supported neighbourhood functions to their names
+var neighbFns = map[string]NeighbFunc{
+	"gaussian":     Gaussian,
+	"bubble":       Bubble,
+	"cutgauss":     CutGaussian,
+	"epanechnikov": Epanechnikov,
+	"mexican":      MexicanHat,
+	"dog":          DoG,
+}
+
+// metrics maps supported distance metrics
+var metrics = map[string]bool{
+	"euclidean":   true,
+	"cosine":      true,
+	"manhattan":   true,
+	"chebyshev":   true,
+	"correlation": true,
+	"canberra":    true,
+	"hamming":     true,
+	"jaccard":     true,
 }
 
 // trainings maps supported training algorithms
@@ -30,23 +61,129 @@ var trainingAlgs = map[string]bool{
 	"batch": true,
 }
 
+// shuffles maps supported sequential training sample orders
+var shuffles = map[string]bool{
+	"random":     true,
+	"none":       true,
+	"shuffle":    true,
+	"stratified": true,
+}
+
+// deadPolicies maps supported dead unit reinitialization policies
+var deadPolicies = map[string]bool{
+	"error":  true,
+	"random": true,
+}
+
+// tieBreaks maps supported unit labeling tie-breaking strategies
+var tieBreaks = map[string]bool{
+	"smallest": true,
+	"nearest":  true,
+	"none":     true,
+}
+
+// linkages maps supported hierarchical clustering linkages
+var linkages = map[string]bool{
+	"single":   true,
+	"complete": true,
+	"average":  true,
+	"ward":     true,
+}
+
+// kSelections maps supported cluster count selection methods
+var kSelections = map[string]bool{
+	"elbow": true,
+	"gap":   true,
+}
+
+// lvqAlgs maps supported LVQ fine-tuning algorithms
+var lvqAlgs = map[string]bool{
+	"lvq1":   true,
+	"lvq2.1": true,
+	"lvq3":   true,
+}
+
+// colorMaps maps supported color maps to their names
+var colorMaps = map[string]ColorFunc{
+	"grayscale": Grayscale,
+	"inverted":  InvertedGrayscale,
+	"viridis":   Viridis,
+	"heat":      Heat,
+	"ylgnbu":    YlGnBu,
+	"puor":      PuOr,
+}
+
 // coordsInitFunc defines SOM grid coordinates initialization function
 type coordsInitFunc func(string, []int) (*mat64.Dense, error)
 
 // NeighbFunc defines SOM neighbourhood function
 type NeighbFunc func(float64, float64) float64
 
+// Weight calls f(gridDist, radius) so that NeighbFunc implements Neighbourhood
+func (f NeighbFunc) Weight(gridDist, radius float64) float64 {
+	return f(gridDist, radius)
+}
+
+// Neighbourhood defines SOM neighbourhood kernel.
+// Weight returns the update weight of a unit at grid distance gridDist from BMU given the radius.
+type Neighbourhood interface {
+	Weight(gridDist, radius float64) float64
+}
+
+// DecayFunc defines training parameter decay function
+type DecayFunc func(int, int) float64
+
+// Value calls f(iteration, totalIterations) so that DecayFunc implements DecaySchedule
+func (f DecayFunc) Value(iteration, totalIterations int) float64 {
+	return f(iteration, totalIterations)
+}
+
+// DecaySchedule defines training parameter decay schedule.
+// Value returns the parameter value at the given iteration out of totalIterations training iterations.
+type DecaySchedule interface {
+	Value(iteration, totalIterations int) float64
+}
+
+// ColorFunc defines color map which maps values normalized to [0,1] interval to colors
+type ColorFunc func(float64) color.RGBA
+
+// DistanceFunc defines distance function between two vectors
+type DistanceFunc func(a, b []float64) (float64, error)
+
 // CbInitFunc defines SOM codebook initialization function
 type CbInitFunc func(*mat64.Dense, []int) (*mat64.Dense, error)
 
+// Init calls f(data, dims) so that CbInitFunc implements Initializer
+func (f CbInitFunc) Init(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+	return f(data, dims)
+}
+
+// Initializer defines SOM codebook initialization scheme.
+// Init returns codebook with product(dims) rows initialized from data or fails with error.
+type Initializer interface {
+	Init(data *mat64.Dense, dims []int) (*mat64.Dense, error)
+}
+
+// initializer returns codebook initializer: Initializer if it is set, InitFunc otherwise
+func (c *CbConfig) initializer() Initializer {
+	if c.Initializer != nil {
+		return c.Initializer
+	}
+	return c.InitFunc
+}
+
 // GridConfig holds SOM grid configuration
 type GridConfig struct {
-	// Size specifies SOM grid dimensions
+	// Size specifies SOM grid dimensions: [n] for 1D chains, [y, x] for 2D grids or [y, x, z] for 3D grids.
+	// Spherical grid size is specified by its subdivision frequency: [freq]. Graph grid size is ignored
 	Size []int
-	// Type specifies the type of SOM grid: planar
+	// Type specifies the type of SOM grid: planar, toroid, cylinder, sphere, graph
 	Type string
-	// UShape specifies SOM unit shape: hexagon, rectangle
+	// UShape specifies SOM unit shape: hexagon, rectangle, triangle
 	UShape string
+	// Graph specifies the units of graph grid: a square symmetric matrix of edge lengths between the units.
+	// Zero elements mean the units are not connected. Graph is ignored by other grid types
+	Graph *mat64.Dense
 }
 
 // CbConfig holds SOM codebook configuration
@@ -55,6 +192,11 @@ type CbConfig struct {
 	Dim int
 	// InitFunc specifies codebook initialization function
 	InitFunc CbInitFunc
+	// Initializer specifies custom codebook initialization. If it is not nil, it is used instead of InitFunc
+	Initializer Initializer
+	// Metric specifies codebook distance metric: euclidean, cosine, manhattan, chebyshev, correlation,
+	// canberra, hamming, jaccard, minkowski:p or a name of registered custom metric. If no metric is specified, euclidean metric is used
+	Metric string
 }
 
 // MapConfig holds SOM configuration
@@ -63,6 +205,62 @@ type MapConfig struct {
 	Grid *GridConfig
 	// Codebook holds SOM codebook configuration
 	Cb *CbConfig
+	// Render holds SOM rendering configuration. If it is nil, default rendering configuration is used
+	Render *RenderConfig
+}
+
+// RenderConfig holds SOM rendering configuration
+type RenderConfig struct {
+	// ColorMap specifies the color map of map units: grayscale, inverted, viridis, heat and colorblind-safe ylgnbu, puor.
+	// If no color map is specified, grayscale is used
+	ColorMap string
+	// ColorFunc specifies custom color map. If it is not nil, it is used instead of ColorMap
+	ColorFunc ColorFunc
+	// Legend enables rendering of the color legend strip which shows the mapping of colors to values
+	Legend bool
+	// CellSize specifies the size of the rendered map units in pixels. If it is 0, 50 pixels big units are rendered
+	CellSize float64
+	// Margin specifies the margin around the rendered map in pixels. If it is 0, 10 pixels margin is used
+	Margin float64
+	// StrokeWidth specifies the width of the unit outlines in pixels. If it is 0, 1 pixel wide outlines are drawn
+	StrokeWidth float64
+	// StrokeColor specifies the color of the unit outlines. If it is nil, black outlines are drawn
+	StrokeColor color.Color
+	// Background specifies the background color. If it is nil, SVG output has no background and PNG output is white
+	Background color.Color
+	// Smooth specifies the sigma of the Gaussian kernel in grid units used to smooth the U-Matrix before rendering.
+	// If it is 0, the U-Matrix is not smoothed
+	Smooth float64
+	// CSS enables SVG output in which elements carry class attributes instead of inline styles.
+	// The classes are defined in a <style> element of the document which can be edited to restyle the map.
+	// Elements are also marked by their kind, e.g. unit, label, boundary or trajectory, to ease styling
+	CSS bool
+}
+
+// style returns the style of map units rendered using the configuration
+func (c *RenderConfig) style() unitStyle {
+	s := unitStyle{color: c.colorFunc()}
+	if c != nil {
+		s.legend = c.Legend
+		s.size, s.off = c.CellSize, c.Margin
+		s.strokeWidth, s.stroke = c.StrokeWidth, c.StrokeColor
+		s.background = c.Background
+		s.smooth = c.Smooth
+		s.css = c.CSS
+	}
+	return s
+}
+
+// colorFunc returns the color map of map units: ColorFunc if it is set, ColorMap otherwise.
+// Grayscale is returned for nil config or if no color map is specified.
+func (c *RenderConfig) colorFunc() ColorFunc {
+	switch {
+	case c == nil || (c.ColorFunc == nil && c.ColorMap == ""):
+		return Grayscale
+	case c.ColorFunc != nil:
+		return c.ColorFunc
+	}
+	return colorMaps[c.ColorMap]
 }
 
 // TrainConfig holds SOM training configuration
@@ -71,22 +269,324 @@ type TrainConfig struct {
 	Algorithm string
 	// Radius specifies initial SOM units radius
 	Radius float64
-	// RDecay specifies radius decay strategy: lin, exp
+	// RDecay specifies radius decay strategy: lin, exp, inv, pow
 	RDecay string
-	// NeighbFn specifies SOM neighbourhood function: gaussian, bubble, mexican
+	// RDecayTime specifies radius exp and inv decay time constant i.e. radius is Radius*exp(-t/RDecayTime)
+	// or Radius/(1+t/RDecayTime). If it is 0, the radius decays to MinRadius at the last training iteration
+	RDecayTime float64
+	// RSchedule specifies custom radius decay schedule. If it is not nil, it is used instead of RDecay
+	RSchedule DecaySchedule
+	// NeighbFn specifies SOM neighbourhood function: gaussian, bubble, cutgauss, epanechnikov, mexican, dog
 	NeighbFn NeighbFunc
+	// Neighb specifies custom SOM neighbourhood. If it is not nil, it is used instead of NeighbFn
+	Neighb Neighbourhood
 	// LRate specifies initial SOM learning rate
 	LRate float64
-	// LDecay specifies learning rate decay strategy: lin, exp
+	// LDecay specifies learning rate decay strategy: lin, exp, inv, pow
 	LDecay string
+	// LDecayTime specifies learning rate exp and inv decay time constant i.e. learning rate is LRate*exp(-t/LDecayTime)
+	// or LRate/(1+t/LDecayTime). If it is 0, the learning rate decays to MinLRate at the last training iteration
+	LDecayTime float64
+	// LSchedule specifies custom learning rate decay schedule. If it is not nil, it is used instead of LDecay
+	LSchedule DecaySchedule
+	// Weights specifies optional weights of data rows. Rows with higher weights
+	// pull the codebook vectors more strongly. If it is nil, all rows have weight 1
+	Weights []float64
+	// Shuffle specifies order of sequential training samples: random, none, shuffle, stratified.
+	// random picks every sample randomly, none presents data rows in their order, shuffle presents
+	// data rows in a new random order every epoch and stratified additionally spreads samples of
+	// every class in Classes evenly over the epoch. If it is empty, random order is used
+	Shuffle string
+	// Classes specifies data row classes used by stratified shuffling
+	Classes map[int]int
+	// Conscience specifies optional conscience mechanism used by sequential training
+	Conscience *ConscienceConfig
+	// DeadUnits specifies optional dead unit reinitialization
+	DeadUnits *DeadUnitConfig
+	// Source specifies random number source used to pick sequential training samples.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+	// OnEpochEnd specifies optional hook called at the end of every training epoch: every iteration
+	// of batch training and every as many iterations of sequential training as there are data rows.
+	// Returning ErrStopTraining stops the training without error, any other error aborts the training
+	OnEpochEnd func(epoch int, stats TrainStats) error
+	// Workers specifies number of goroutines batch training is distributed across.
+	// If it is 0, the number of CPUs is used
+	Workers int
+}
+
+// ConscienceConfig holds conscience mechanism configuration.
+// Every unit tracks its winning frequency p; BMU is then the unit with the smallest d - Bias*(1/N - p)
+// where d is the unit distance to the sample and N is the number of map units.
+type ConscienceConfig struct {
+	// Bias specifies how strongly frequently winning units are penalized
+	Bias float64
+	// Rate specifies winning frequency update rate
+	Rate float64
+}
+
+// DeadUnitConfig holds dead unit reinitialization configuration.
+// A unit is dead if it has not been BMU of any data sample for Epochs consecutive training epochs.
+type DeadUnitConfig struct {
+	// Epochs specifies number of epochs without a win after which a unit is reinitialized
+	Epochs int
+	// Policy specifies reinitialization policy: error, random
+	Policy string
+}
+
+// EarlyStopConfig holds early stopping configuration
+type EarlyStopConfig struct {
+	// MinImprovement specifies minimum relative improvement of quantization error between two epochs
+	MinImprovement float64
+	// Patience specifies number of consecutive epochs without sufficient improvement the training stops after
+	Patience int
+}
+
+// GrowConfig holds Growing Grid training configuration
+type GrowConfig struct {
+	// QError specifies target quantization error; the grid stops growing once it is reached
+	QError float64
+	// MaxUnits specifies maximum number of grid units; the grid stops growing before exceeding it
+	MaxUnits int
+	// Iters specifies number of training iterations run after each grid growth
+	Iters int
+}
+
+// SupervisedConfig holds supervised SOM configuration
+type SupervisedConfig struct {
+	// Map specifies SOM configuration; codebook vectors are extended by class indicator dimensions
+	Map *MapConfig
+	// Train specifies SOM training configuration
+	Train *TrainConfig
+	// Iters specifies number of training iterations
+	Iters int
+	// ClassWeight specifies value of class indicator dimension of the data sample class
+	ClassWeight float64
+}
+
+// RecSOMConfig holds Recursive SOM configuration
+type RecSOMConfig struct {
+	// Map specifies SOM grid and input weights configuration
+	Map *MapConfig
+	// Alpha specifies weight of the distance between input and unit weights
+	Alpha float64
+	// Beta specifies weight of the distance between previous map activation and unit context
+	Beta float64
+}
+
+// MSOMConfig holds Merge SOM configuration
+type MSOMConfig struct {
+	// Map specifies SOM grid and input weights configuration
+	Map *MapConfig
+	// Alpha specifies weight of the context distance; the input distance is weighted by 1-Alpha
+	Alpha float64
+	// Beta specifies merging parameter: the context descriptor is a (1-Beta) and Beta weighted
+	// sum of input weights and context of the previous BMU
+	Beta float64
+}
+
+// RelationalConfig holds relational SOM configuration
+type RelationalConfig struct {
+	// Grid specifies SOM grid configuration
+	Grid *GridConfig
+	// Source specifies random number source used to pick initial prototypes.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// LabelConfig holds majority vote unit labeling configuration
+type LabelConfig struct {
+	// Metric specifies distance metric used to find BMUs of the data samples. If it is empty, euclidean metric is used
+	Metric string
+	// TieBreak specifies how ties between the most frequent labels are resolved: smallest picks the
+	// smallest label, nearest picks the label of the tied sample closest to the unit, none leaves the unit
+	// unlabeled. If it is empty, smallest is used
+	TieBreak string
+	// MinSupport specifies minimum number of samples of the winning label a unit must be BMU of to be labeled
+	MinSupport int
+}
+
+// LVQConfig holds Learning Vector Quantization fine-tuning configuration
+type LVQConfig struct {
+	// Algorithm specifies LVQ algorithm: lvq1, lvq2.1, lvq3
+	Algorithm string
+	// LRate specifies initial learning rate; it decays linearly to zero
+	LRate float64
+	// Window specifies relative width of the window around the decision border used by lvq2.1 and lvq3
+	Window float64
+	// Epsilon specifies learning rate scale applied by lvq3 when both closest units are of the sample class
+	Epsilon float64
+	// Source specifies random number source used to pick training samples.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// KMeansConfig holds k-means clustering configuration
+type KMeansConfig struct {
+	// K specifies number of clusters
+	K int
+	// Iters specifies maximum number of k-means iterations
+	Iters int
+	// Source specifies random number source used by k-means++ seeding.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// SammonConfig holds Sammon mapping configuration
+type SammonConfig struct {
+	// Iters specifies number of Sammon mapping iterations
+	Iters int
+	// LRate specifies the step size of the pseudo-Newton updates, also known as magic factor. It must be in (0,1] interval
+	LRate float64
+	// Metric specifies distance metric of the projected vectors. If it is empty, euclidean metric is used
+	Metric string
+	// Source specifies random number source used to initialize the projection.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// FacetConfig holds multi-panel map rendering configuration
+type FacetConfig struct {
+	// UMatrix adds the U-Matrix panel in front of the component plane panels
+	UMatrix bool
+	// Features specifies the codebook features whose component planes are rendered
+	Features []int
+	// Cols specifies the number of panels in every row of the panel grid. If it is 0, all panels are placed in one row
+	Cols int
+	// SharedScale shades all panels using the common range of their values instead of the range of every panel.
+	// The color legend is only rendered if the scale is shared
+	SharedScale bool
+}
+
+// HierarchyConfig holds agglomerative hierarchical clustering configuration
+type HierarchyConfig struct {
+	// Linkage specifies distance between clusters: single, complete, average, ward
+	Linkage string
+	// Metric specifies distance metric between clustered vectors. If it is empty, euclidean metric is used.
+	// Ward linkage requires euclidean metric
+	Metric string
+	// UnitDist specifies optional grid unit distance matrix. If it is not nil,
+	// only clusters which contain neighbouring grid units are merged
+	UnitDist *mat64.Dense
+}
+
+// DBSCANConfig holds DBSCAN clustering configuration
+type DBSCANConfig struct {
+	// Eps specifies radius of vector neighbourhood
+	Eps float64
+	// MinPts specifies minimum number of vectors in the neighbourhood of a core vector, the vector itself included
+	MinPts int
+	// Metric specifies distance metric between clustered vectors. If it is empty, euclidean metric is used
+	Metric string
+}
+
+// KSelectConfig holds cluster count selection configuration
+type KSelectConfig struct {
+	// Method specifies cluster count selection method: elbow, gap
+	Method string
+	// MinK and MaxK specify the range of evaluated cluster counts
+	MinK, MaxK int
+	// Refs specifies number of uniformly distributed reference data sets used by gap statistic
+	Refs int
+	// Iters specifies maximum number of k-means iterations
+	Iters int
+	// Source specifies random number source used by k-means and reference data sets.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// PhaseConfig holds configuration of a single training phase
+type PhaseConfig struct {
+	// Radius specifies initial SOM neighbourhood radius of the phase
+	Radius float64
+	// LRate specifies initial SOM learning rate of the phase
+	LRate float64
+	// Iters specifies number of training iterations of the phase
+	Iters int
+}
+
+// TwoPhaseConfig holds two-phase SOM training configuration
+type TwoPhaseConfig struct {
+	// Train specifies training configuration shared by both phases.
+	// Its Radius and LRate are replaced by the radius and learning rate of each phase
+	Train *TrainConfig
+	// Ordering specifies the coarse ordering phase
+	Ordering PhaseConfig
+	// Tuning specifies the fine-tuning convergence phase
+	Tuning PhaseConfig
+}
+
+// GHSOMConfig holds Growing Hierarchical SOM configuration
+type GHSOMConfig struct {
+	// Map specifies configuration of every map in the hierarchy
+	Map *MapConfig
+	// Train specifies training configuration of every map in the hierarchy
+	Train *TrainConfig
+	// Iters specifies number of training iterations of every map
+	Iters int
+	// Tau1 specifies the fraction of parent unit quantization error every map grows to reach.
+	// If Tau1 is 0 the maps do not grow. Growing maps require 2D planar grids
+	Tau1 float64
+	// MaxUnits specifies maximum number of units of growing maps
+	MaxUnits int
+	// Tau2 specifies the fraction of data quantization error above which a unit spawns child map
+	Tau2 float64
+	// MaxDepth specifies maximum number of levels of the hierarchy
+	MaxDepth int
+	// MinSamples specifies minimum number of samples mapped to a unit required to spawn child map
+	MinSamples int
+}
+
+// GNGConfig holds Growing Neural Gas configuration
+type GNGConfig struct {
+	// MaxUnits specifies maximum number of GNG units
+	MaxUnits int
+	// Lambda specifies number of input signals after which a new unit is inserted
+	Lambda int
+	// WinnerRate specifies learning rate of the unit closest to input signal
+	WinnerRate float64
+	// NeighbRate specifies learning rate of the neighbours of the unit closest to input signal
+	NeighbRate float64
+	// MaxAge specifies maximum age of an edge; older edges are removed
+	MaxAge int
+	// Alpha specifies error decrease factor of units between which new unit is inserted
+	Alpha float64
+	// Decay specifies global error decay applied to all units after every input signal
+	Decay float64
+	// Metric specifies distance metric. If no metric is specified, euclidean metric is used
+	Metric string
+	// Source specifies random number source used to pick initial units and training samples.
+	// If it is nil, a source seeded by the current time is used
+	Source rand.Source
+}
+
+// neighbourhood returns the neighbourhood used by the training configuration
+func (c *TrainConfig) neighbourhood() Neighbourhood {
+	if c.Neighb != nil {
+		return c.Neighb
+	}
+	return c.NeighbFn
 }
 
 // validateGridConfig validates SOM grid configuration
 // It returns error if any of the config parameters are invalid
 func validateGridConfig(c *GridConfig) error {
-	// SOM must have 2 dimensions
-	// TODO: figure out 3D maps
-	if len(c.Size) != 2 {
+	// graph grid is specified by its unit graph
+	if c.Type == "graph" {
+		return validateGraph(c.Graph)
+	}
+	// spherical grid is specified by its subdivision frequency
+	if c.Type == "sphere" {
+		if len(c.Size) != 1 || c.Size[0] <= 0 {
+			return fmt.Errorf("incorrect SOM sphere grid dimensions supplied: %v", c.Size)
+		}
+		if _, ok := uShapes[c.UShape]; !ok {
+			return fmt.Errorf("unsupported SOM unit shape: %s", c.UShape)
+		}
+		return nil
+	}
+	// SOM must have 1, 2 or 3 dimensions
+	if len(c.Size) == 0 || len(c.Size) > 3 {
 		return fmt.Errorf("unsupported number of SOM grid dimensions supplied: %d", len(c.Size))
 	}
 	// check if the supplied dimensions are negative integers or if they are single node
@@ -109,6 +609,22 @@ func validateGridConfig(c *GridConfig) error {
 	if _, ok := uShapes[c.UShape]; !ok {
 		return fmt.Errorf("unsupported SOM unit shape: %s", c.UShape)
 	}
+	// hexagon and triangle units can only be used in 2D grids
+	if (c.UShape == "hexagon" || c.UShape == "triangle") && len(c.Size) > 2 {
+		return fmt.Errorf("unsupported number of %s grid dimensions supplied: %d", c.UShape, len(c.Size))
+	}
+	// 1D grids are chains of units regardless of the unit shape
+	if len(c.Size) == 1 {
+		return nil
+	}
+	// hexagon and triangle rows can only wrap around if there is even number of them
+	if c.Type == "toroid" && (c.UShape == "hexagon" || c.UShape == "triangle") && c.Size[0]%2 != 0 {
+		return fmt.Errorf("toroid %s grid requires even number of rows: %d", c.UShape, c.Size[0])
+	}
+	// triangle columns can only wrap around if there is even number of them
+	if (c.Type == "toroid" || c.Type == "cylinder") && c.UShape == "triangle" && c.Size[1]%2 != 0 {
+		return fmt.Errorf("%s triangle grid requires even number of columns: %d", c.Type, c.Size[1])
+	}
 
 	return nil
 }
@@ -121,9 +637,54 @@ func validateCbConfig(c *CbConfig) error {
 		return fmt.Errorf("incorrect SOM codebook dimension supplied: %v", c.Dim)
 	}
 	// check if the codebook init func is not nil
-	if c.InitFunc == nil {
+	if c.InitFunc == nil && c.Initializer == nil {
 		return fmt.Errorf("invalid InitFunc: %v", c.InitFunc)
 	}
+	// check if the supplied distance metric is supported
+	if c.Metric != "" {
+		if err := validateMetric(c.Metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRenderConfig validates SOM rendering configuration
+// It returns error if any of the config parameters are invalid
+func validateRenderConfig(c *RenderConfig) error {
+	// check if the color map is supported
+	if _, ok := colorMaps[c.ColorMap]; !ok && c.ColorMap != "" {
+		return fmt.Errorf("unsupported color map: %s", c.ColorMap)
+	}
+	// sizes can't be negative
+	if c.CellSize < 0 {
+		return fmt.Errorf("invalid cell size: %f", c.CellSize)
+	}
+	if c.Margin < 0 {
+		return fmt.Errorf("invalid margin: %f", c.Margin)
+	}
+	if c.StrokeWidth < 0 {
+		return fmt.Errorf("invalid stroke width: %f", c.StrokeWidth)
+	}
+	if c.Smooth < 0 {
+		return fmt.Errorf("invalid smoothing sigma: %f", c.Smooth)
+	}
+	return nil
+}
+
+// validateMetric validates distance metric.
+// It returns error if the metric is not supported or its parameters are invalid
+func validateMetric(metric string) error {
+	if strings.HasPrefix(metric, "minkowski") {
+		_, err := minkowskiExp(metric)
+		return err
+	}
+	if _, ok := metrics[metric]; ok {
+		return nil
+	}
+	if _, ok := registeredMetric(metric); !ok {
+		return fmt.Errorf("unsupported distance metric: %s", metric)
+	}
 	return nil
 }
 
@@ -138,21 +699,425 @@ func validateTrainConfig(c *TrainConfig) error {
 	if c.Radius < 0 {
 		return fmt.Errorf("invalid SOM unit radius: %f", c.Radius)
 	}
-	// check Radius decay strategy
-	if _, ok := decays[c.RDecay]; !ok {
+	// check Radius decay strategy unless custom schedule is supplied
+	if _, ok := decays[c.RDecay]; !ok && c.RSchedule == nil {
 		return fmt.Errorf("unsupported Radius decay strategy: %s", c.RDecay)
 	}
 	// check the supplied is not nil
-	if c.NeighbFn == nil {
+	if c.NeighbFn == nil && c.Neighb == nil {
 		return fmt.Errorf("invalid Neighbourhood function: %v", c.NeighbFn)
 	}
 	// initial SOM learning rate must be greater than zero
 	if c.LRate < 0 {
 		return fmt.Errorf("invalid SOM learning rate: %f", c.LRate)
 	}
-	// check Learning rate decay strategy
-	if _, ok := decays[c.LDecay]; !ok {
+	// check Learning rate decay strategy unless custom schedule is supplied
+	if _, ok := decays[c.LDecay]; !ok && c.LSchedule == nil {
 		return fmt.Errorf("unsupported Learning rate decay strategy: %s", c.LDecay)
 	}
+	// decay time constants can't be negative
+	if c.RDecayTime < 0 {
+		return fmt.Errorf("invalid Radius decay time: %f", c.RDecayTime)
+	}
+	if c.LDecayTime < 0 {
+		return fmt.Errorf("invalid Learning rate decay time: %f", c.LDecayTime)
+	}
+	// check conscience mechanism
+	if c.Conscience != nil {
+		if c.Conscience.Bias < 0 {
+			return fmt.Errorf("invalid conscience bias: %f", c.Conscience.Bias)
+		}
+		if c.Conscience.Rate <= 0 || c.Conscience.Rate > 1 {
+			return fmt.Errorf("invalid conscience rate: %f", c.Conscience.Rate)
+		}
+	}
+	// check dead unit reinitialization
+	if c.DeadUnits != nil {
+		if err := validateDeadUnitConfig(c.DeadUnits); err != nil {
+			return err
+		}
+	}
+	// check sample order
+	if _, ok := shuffles[c.Shuffle]; !ok && c.Shuffle != "" {
+		return fmt.Errorf("unsupported sample order: %s", c.Shuffle)
+	}
+	if c.Shuffle == "stratified" && len(c.Classes) == 0 {
+		return fmt.Errorf("invalid stratified shuffling classes: %v", c.Classes)
+	}
+	// number of workers can't be negative
+	if c.Workers < 0 {
+		return fmt.Errorf("invalid number of workers: %d", c.Workers)
+	}
+	return nil
+}
+
+// validateWeights validates training data row weights for the data with the given number of rows
+// It returns error if the number of weights does not match the number of rows or if the weights are invalid
+func validateWeights(weights []float64, rows int) error {
+	if len(weights) != rows {
+		return fmt.Errorf("invalid number of weights: %d", len(weights))
+	}
+	sum := 0.0
+	for _, w := range weights {
+		// weights can't be negative
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("invalid weight: %f", w)
+		}
+		sum += w
+	}
+	// at least one row must be used for training
+	if sum == 0 {
+		return fmt.Errorf("invalid weights: all weights are zero")
+	}
+	return nil
+}
+
+// validateEarlyStopConfig validates early stopping configuration
+// It returns error if any of the config parameters are invalid
+func validateEarlyStopConfig(c *EarlyStopConfig) error {
+	// minimum improvement can't be negative
+	if c.MinImprovement < 0 {
+		return fmt.Errorf("invalid minimum improvement: %f", c.MinImprovement)
+	}
+	// patience must be a positive integer
+	if c.Patience <= 0 {
+		return fmt.Errorf("invalid patience: %d", c.Patience)
+	}
+	return nil
+}
+
+// validateDeadUnitConfig validates dead unit reinitialization configuration
+// It returns error if any of the config parameters are invalid
+func validateDeadUnitConfig(c *DeadUnitConfig) error {
+	// number of epochs must be a positive integer
+	if c.Epochs <= 0 {
+		return fmt.Errorf("invalid number of dead unit epochs: %d", c.Epochs)
+	}
+	// reinitialization policy must be supported
+	if _, ok := deadPolicies[c.Policy]; !ok {
+		return fmt.Errorf("unsupported dead unit policy: %s", c.Policy)
+	}
+	return nil
+}
+
+// validateGrowConfig validates Growing Grid training configuration
+// It returns error if any of the config parameters are invalid
+func validateGrowConfig(c *GrowConfig) error {
+	// target quantization error can't be negative
+	if c.QError < 0 {
+		return fmt.Errorf("invalid target quantization error: %f", c.QError)
+	}
+	// maximum number of units must be a positive integer
+	if c.MaxUnits <= 0 {
+		return fmt.Errorf("invalid maximum number of units: %d", c.MaxUnits)
+	}
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	return nil
+}
+
+// validateSupervisedConfig validates supervised SOM configuration
+// It returns error if any of the config parameters are invalid
+func validateSupervisedConfig(c *SupervisedConfig) error {
+	// map and training configuration must be supplied
+	if c.Map == nil || c.Map.Cb == nil {
+		return fmt.Errorf("invalid map configuration: %v", c.Map)
+	}
+	if c.Train == nil {
+		return fmt.Errorf("invalid training configuration: %v", c.Train)
+	}
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	// class indicator weight must be greater than zero
+	if c.ClassWeight <= 0 {
+		return fmt.Errorf("invalid class weight: %f", c.ClassWeight)
+	}
+	return nil
+}
+
+// validateRecSOMConfig validates Recursive SOM configuration
+// It returns error if any of the config parameters are invalid
+func validateRecSOMConfig(c *RecSOMConfig) error {
+	// map configuration must be supplied
+	if c.Map == nil || c.Map.Cb == nil {
+		return fmt.Errorf("invalid map configuration: %v", c.Map)
+	}
+	// input weight must be greater than zero
+	if c.Alpha <= 0 {
+		return fmt.Errorf("invalid RecSOM alpha: %f", c.Alpha)
+	}
+	// context weight can't be negative
+	if c.Beta < 0 {
+		return fmt.Errorf("invalid RecSOM beta: %f", c.Beta)
+	}
+	return nil
+}
+
+// validateMSOMConfig validates Merge SOM configuration
+// It returns error if any of the config parameters are invalid
+func validateMSOMConfig(c *MSOMConfig) error {
+	// map configuration must be supplied
+	if c.Map == nil || c.Map.Cb == nil {
+		return fmt.Errorf("invalid map configuration: %v", c.Map)
+	}
+	// context weight must be in [0, 1) so that the input is always taken into account
+	if c.Alpha < 0 || c.Alpha >= 1 {
+		return fmt.Errorf("invalid MSOM alpha: %f", c.Alpha)
+	}
+	// merging parameter must be in [0, 1]
+	if c.Beta < 0 || c.Beta > 1 {
+		return fmt.Errorf("invalid MSOM beta: %f", c.Beta)
+	}
+	return nil
+}
+
+// validateLabelConfig validates unit labeling configuration
+// It returns error if any of the config parameters are invalid
+func validateLabelConfig(c *LabelConfig) error {
+	// check if the supplied distance metric is supported
+	if c.Metric != "" {
+		if err := validateMetric(c.Metric); err != nil {
+			return err
+		}
+	}
+	// check if the tie-breaking strategy is supported
+	if _, ok := tieBreaks[c.TieBreak]; !ok && c.TieBreak != "" {
+		return fmt.Errorf("unsupported tie-breaking strategy: %s", c.TieBreak)
+	}
+	// minimum support can't be negative
+	if c.MinSupport < 0 {
+		return fmt.Errorf("invalid minimum support: %d", c.MinSupport)
+	}
+	return nil
+}
+
+// validateLVQConfig validates LVQ fine-tuning configuration
+// It returns error if any of the config parameters are invalid
+func validateLVQConfig(c *LVQConfig) error {
+	// LVQ algorithm must be supported
+	if _, ok := lvqAlgs[c.Algorithm]; !ok {
+		return fmt.Errorf("invalid LVQ algorithm: %s", c.Algorithm)
+	}
+	// learning rate must be greater than zero
+	if c.LRate <= 0 {
+		return fmt.Errorf("invalid LVQ learning rate: %f", c.LRate)
+	}
+	// window is only used by lvq2.1 and lvq3
+	if c.Algorithm != "lvq1" && (c.Window <= 0 || c.Window >= 1) {
+		return fmt.Errorf("invalid LVQ window: %f", c.Window)
+	}
+	if c.Algorithm == "lvq3" && c.Epsilon <= 0 {
+		return fmt.Errorf("invalid LVQ epsilon: %f", c.Epsilon)
+	}
+	return nil
+}
+
+// validateKMeansConfig validates k-means clustering configuration
+// It returns error if any of the config parameters are invalid
+func validateKMeansConfig(c *KMeansConfig) error {
+	// number of clusters must be a positive integer
+	if c.K <= 0 {
+		return fmt.Errorf("invalid number of clusters: %d", c.K)
+	}
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	return nil
+}
+
+// validateFacetConfig validates multi-panel map rendering configuration
+// It returns error if any of the config parameters are invalid
+func validateFacetConfig(c *FacetConfig) error {
+	// at least one panel must be rendered
+	if !c.UMatrix && len(c.Features) == 0 {
+		return fmt.Errorf("no panels to render")
+	}
+	if c.Cols < 0 {
+		return fmt.Errorf("invalid number of panel columns: %d", c.Cols)
+	}
+	return nil
+}
+
+// validateSammonConfig validates Sammon mapping configuration
+// It returns error if any of the config parameters are invalid
+func validateSammonConfig(c *SammonConfig) error {
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	if c.LRate <= 0 || c.LRate > 1 {
+		return fmt.Errorf("invalid Sammon learning rate: %f", c.LRate)
+	}
+	// check if the supplied distance metric is supported
+	if c.Metric != "" {
+		if err := validateMetric(c.Metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateHierarchyConfig validates hierarchical clustering configuration
+// It returns error if any of the config parameters are invalid
+func validateHierarchyConfig(c *HierarchyConfig) error {
+	// linkage must be supported
+	if _, ok := linkages[c.Linkage]; !ok {
+		return fmt.Errorf("unsupported linkage: %s", c.Linkage)
+	}
+	// check if the supplied distance metric is supported
+	if c.Metric != "" {
+		if err := validateMetric(c.Metric); err != nil {
+			return err
+		}
+	}
+	// ward linkage minimizes variance which requires euclidean distances
+	if c.Linkage == "ward" && c.Metric != "" && c.Metric != "euclidean" {
+		return fmt.Errorf("ward linkage requires euclidean metric: %s", c.Metric)
+	}
+	return nil
+}
+
+// validateDBSCANConfig validates DBSCAN clustering configuration
+// It returns error if any of the config parameters are invalid
+func validateDBSCANConfig(c *DBSCANConfig) error {
+	// neighbourhood radius must be positive
+	if c.Eps <= 0 {
+		return fmt.Errorf("invalid DBSCAN eps: %f", c.Eps)
+	}
+	// minimum number of neighbours must be a positive integer
+	if c.MinPts <= 0 {
+		return fmt.Errorf("invalid DBSCAN minimum points: %d", c.MinPts)
+	}
+	// check if the supplied distance metric is supported
+	if c.Metric != "" {
+		if err := validateMetric(c.Metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKSelectConfig validates cluster count selection configuration
+// It returns error if any of the config parameters are invalid
+func validateKSelectConfig(c *KSelectConfig) error {
+	// selection method must be supported
+	if _, ok := kSelections[c.Method]; !ok {
+		return fmt.Errorf("unsupported cluster count selection method: %s", c.Method)
+	}
+	// cluster counts must be positive and form a range
+	if c.MinK <= 0 || c.MaxK <= c.MinK {
+		return fmt.Errorf("invalid cluster count range: [%d, %d]", c.MinK, c.MaxK)
+	}
+	// elbow can only be found on at least three points
+	if c.Method == "elbow" && c.MaxK-c.MinK < 2 {
+		return fmt.Errorf("elbow method requires at least three cluster counts: [%d, %d]", c.MinK, c.MaxK)
+	}
+	// gap statistic needs reference data sets
+	if c.Method == "gap" && c.Refs <= 0 {
+		return fmt.Errorf("invalid number of reference data sets: %d", c.Refs)
+	}
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	return nil
+}
+
+// validateTwoPhaseConfig validates two-phase training configuration
+// It returns error if any of the config parameters are invalid
+func validateTwoPhaseConfig(c *TwoPhaseConfig) error {
+	// training configuration must be supplied
+	if c.Train == nil {
+		return fmt.Errorf("invalid training configuration: %v", c.Train)
+	}
+	for _, p := range []struct {
+		name  string
+		phase PhaseConfig
+	}{{"ordering", c.Ordering}, {"tuning", c.Tuning}} {
+		// phase radius and learning rate must be greater than zero
+		if p.phase.Radius <= 0 {
+			return fmt.Errorf("invalid %s phase radius: %f", p.name, p.phase.Radius)
+		}
+		if p.phase.LRate <= 0 {
+			return fmt.Errorf("invalid %s phase learning rate: %f", p.name, p.phase.LRate)
+		}
+		// number of iterations must be a positive integer
+		if p.phase.Iters <= 0 {
+			return fmt.Errorf("invalid %s phase number of iterations: %d", p.name, p.phase.Iters)
+		}
+	}
+	return nil
+}
+
+// validateGNGConfig validates Growing Neural Gas configuration
+// It returns error if any of the config parameters are invalid
+func validateGNGConfig(c *GNGConfig) error {
+	// GNG starts with two units
+	if c.MaxUnits < 2 {
+		return fmt.Errorf("invalid maximum number of units: %d", c.MaxUnits)
+	}
+	if c.Lambda <= 0 {
+		return fmt.Errorf("invalid GNG insertion interval: %d", c.Lambda)
+	}
+	// learning rates must be from [0, 1] interval
+	if c.WinnerRate < 0 || c.WinnerRate > 1 {
+		return fmt.Errorf("invalid GNG winner learning rate: %f", c.WinnerRate)
+	}
+	if c.NeighbRate < 0 || c.NeighbRate > 1 {
+		return fmt.Errorf("invalid GNG neighbour learning rate: %f", c.NeighbRate)
+	}
+	if c.MaxAge <= 0 {
+		return fmt.Errorf("invalid GNG maximum edge age: %d", c.MaxAge)
+	}
+	// error factors must be from [0, 1] interval
+	if c.Alpha < 0 || c.Alpha > 1 {
+		return fmt.Errorf("invalid GNG error decrease factor: %f", c.Alpha)
+	}
+	if c.Decay < 0 || c.Decay > 1 {
+		return fmt.Errorf("invalid GNG error decay: %f", c.Decay)
+	}
+	if c.Metric != "" {
+		return validateMetric(c.Metric)
+	}
+	return nil
+}
+
+// validateGHSOMConfig validates Growing Hierarchical SOM configuration
+// It returns error if any of the config parameters are invalid
+func validateGHSOMConfig(c *GHSOMConfig) error {
+	if c.Map == nil {
+		return fmt.Errorf("invalid GHSOM map configuration: %v", c.Map)
+	}
+	if c.Train == nil {
+		return fmt.Errorf("invalid GHSOM training configuration: %v", c.Train)
+	}
+	if err := validateTrainConfig(c.Train); err != nil {
+		return err
+	}
+	// number of iterations must be a positive integer
+	if c.Iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", c.Iters)
+	}
+	if c.Tau1 < 0 || c.Tau1 >= 1 {
+		return fmt.Errorf("invalid GHSOM map growth factor: %f", c.Tau1)
+	}
+	// growing maps must be limited in size
+	if c.Tau1 > 0 && c.MaxUnits <= 0 {
+		return fmt.Errorf("invalid maximum number of units: %d", c.MaxUnits)
+	}
+	if c.Tau2 <= 0 || c.Tau2 > 1 {
+		return fmt.Errorf("invalid GHSOM hierarchy growth factor: %f", c.Tau2)
+	}
+	if c.MaxDepth <= 0 {
+		return fmt.Errorf("invalid GHSOM maximum depth: %d", c.MaxDepth)
+	}
+	if c.MinSamples <= 0 {
+		return fmt.Errorf("invalid GHSOM minimum number of samples: %d", c.MinSamples)
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/gonum/matrix/mat64"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,9 +50,11 @@ func TestValidateGridSize(t *testing.T) {
 		expErr bool
 		errStr string
 	}{
-		{[]int{1}, true, fmt.Sprintf(errDimLen, 1)},
+		{[]int{1}, true, fmt.Sprintf(errDimVal, []int{1})},
+		{[]int{3}, false, ""},
 		{[]int{}, true, fmt.Sprintf(errDimLen, 0)},
 		{[]int{1, 2}, false, ""},
+		{[]int{1, 2, 3, 4}, true, fmt.Sprintf(errDimLen, 4)},
 		{singDims, true, fmt.Sprintf(errDimVal, singDims)},
 		{wrongDims, true, fmt.Sprintf(errDimVal, wrongDims)},
 	}
@@ -79,6 +82,8 @@ func TestValidateGridType(t *testing.T) {
 		expErr bool
 	}{
 		{"planar", false},
+		{"toroid", false},
+		{"cylinder", false},
 		{"foobar", true},
 	}
 
@@ -95,6 +100,68 @@ func TestValidateGridType(t *testing.T) {
 	mc.Grid.Type = grid
 }
 
+func TestValidate3DGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	mc := makeDefaultMapCfg()
+	mc.Grid.Size = []int{2, 3, 4}
+	// hexagon units are not supported in 3D grids
+	errString := "unsupported number of hexagon grid dimensions supplied: %d"
+	assert.EqualError(validateGridConfig(mc.Grid), fmt.Sprintf(errString, 3))
+	// rectangle units are fine
+	mc.Grid.UShape = "rectangle"
+	assert.NoError(validateGridConfig(mc.Grid))
+}
+
+func TestValidateToroidHexagon(t *testing.T) {
+	assert := assert.New(t)
+
+	mc := makeDefaultMapCfg()
+	mc.Grid.Type = "toroid"
+	mc.Grid.UShape = "hexagon"
+	// even number of rows
+	mc.Grid.Size = []int{2, 3}
+	assert.NoError(validateGridConfig(mc.Grid))
+	// odd number of rows
+	mc.Grid.Size = []int{3, 2}
+	errString := "toroid hexagon grid requires even number of rows: %d"
+	assert.EqualError(validateGridConfig(mc.Grid), fmt.Sprintf(errString, 3))
+	// rectangle units can have any number of rows
+	mc.Grid.UShape = "rectangle"
+	assert.NoError(validateGridConfig(mc.Grid))
+	// cylinder does not wrap around rows
+	mc.Grid.Type = "cylinder"
+	mc.Grid.UShape = "hexagon"
+	assert.NoError(validateGridConfig(mc.Grid))
+}
+
+func TestValidateWrappedTriangle(t *testing.T) {
+	assert := assert.New(t)
+
+	mc := makeDefaultMapCfg()
+	mc.Grid.Type = "toroid"
+	mc.Grid.UShape = "triangle"
+	// even number of rows and columns
+	mc.Grid.Size = []int{2, 4}
+	assert.NoError(validateGridConfig(mc.Grid))
+	// odd number of rows
+	mc.Grid.Size = []int{3, 4}
+	assert.EqualError(validateGridConfig(mc.Grid), "toroid triangle grid requires even number of rows: 3")
+	// odd number of columns
+	mc.Grid.Size = []int{2, 3}
+	assert.EqualError(validateGridConfig(mc.Grid), "toroid triangle grid requires even number of columns: 3")
+	// cylinder only wraps around columns
+	mc.Grid.Type = "cylinder"
+	mc.Grid.Size = []int{3, 4}
+	assert.NoError(validateGridConfig(mc.Grid))
+	mc.Grid.Size = []int{2, 3}
+	assert.EqualError(validateGridConfig(mc.Grid), "cylinder triangle grid requires even number of columns: 3")
+	// triangle units can't be used in 3D grids
+	mc.Grid.Type = "planar"
+	mc.Grid.Size = []int{2, 2, 2}
+	assert.EqualError(validateGridConfig(mc.Grid), "unsupported number of triangle grid dimensions supplied: 3")
+}
+
 func TestValidateGridUshape(t *testing.T) {
 	assert := assert.New(t)
 
@@ -107,6 +174,7 @@ func TestValidateGridUshape(t *testing.T) {
 		{"hexagon", false},
 		{"foobar", true},
 		{"rectangle", false},
+		{"triangle", false},
 	}
 
 	uShape := mc.Grid.UShape
@@ -149,6 +217,62 @@ func TestValidateCbInitFunc(t *testing.T) {
 	mc.Cb.InitFunc = initFunc
 }
 
+func TestValidateCbMetric(t *testing.T) {
+	assert := assert.New(t)
+
+	mc := makeDefaultMapCfg()
+	errString := "unsupported distance metric: %s"
+	testCases := []struct {
+		metric string
+		expErr bool
+	}{
+		{"", false},
+		{"euclidean", false},
+		{"cosine", false},
+		{"manhattan", false},
+		{"chebyshev", false},
+		{"correlation", false},
+		{"canberra", false},
+		{"hamming", false},
+		{"jaccard", false},
+		{"foobar", true},
+	}
+
+	metric := mc.Cb.Metric
+	for _, tc := range testCases {
+		mc.Cb.Metric = tc.metric
+		err := validateCbConfig(mc.Cb)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, mc.Cb.Metric))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	// minkowski metric requires valid exponent
+	mkTestCases := []struct {
+		metric string
+		expErr bool
+	}{
+		{"minkowski:1.5", false},
+		{"minkowski:inf", false},
+		{"minkowski", true},
+		{"minkowski:", true},
+		{"minkowski:-1", true},
+		{"minkowski:foo", true},
+	}
+
+	for _, tc := range mkTestCases {
+		mc.Cb.Metric = tc.metric
+		err := validateCbConfig(mc.Cb)
+		if tc.expErr {
+			assert.Error(err)
+		} else {
+			assert.NoError(err)
+		}
+	}
+	mc.Cb.Metric = metric
+}
+
 func TestValidateAlgorithm(t *testing.T) {
 	assert := assert.New(t)
 
@@ -216,6 +340,7 @@ func TestValidateRDecay(t *testing.T) {
 		{"foobar", true},
 		{"exp", false},
 		{"inv", false},
+		{"pow", false},
 	}
 
 	origRDecay := tr.RDecay
@@ -243,7 +368,10 @@ func TestValidateNeighbFn(t *testing.T) {
 		{Gaussian, false},
 		{nil, true},
 		{Bubble, false},
+		{CutGaussian, false},
+		{Epanechnikov, false},
 		{MexicanHat, false},
+		{DoG, false},
 	}
 
 	origNeighbFn := tr.NeighbFn
@@ -257,6 +385,11 @@ func TestValidateNeighbFn(t *testing.T) {
 		}
 	}
 	tr.NeighbFn = origNeighbFn
+	// custom neighbourhood can be used instead of neighbourhood function
+	tr.NeighbFn = nil
+	tr.Neighb = NeighbFunc(Gaussian)
+	assert.NoError(validateTrainConfig(tr))
+	tr.NeighbFn = origNeighbFn
 }
 
 func TestValidateLRate(t *testing.T) {
@@ -286,6 +419,40 @@ func TestValidateLRate(t *testing.T) {
 	tr.LRate = origLRate
 }
 
+func TestValidateDecayTime(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := makeDefaultTrainConfig()
+	tr.RDecayTime, tr.LDecayTime = 10.0, 20.0
+	assert.NoError(validateTrainConfig(tr))
+	tr.RDecayTime = -1.0
+	assert.EqualError(validateTrainConfig(tr), fmt.Sprintf("invalid Radius decay time: %f", tr.RDecayTime))
+	tr.RDecayTime, tr.LDecayTime = 0.0, -1.0
+	assert.EqualError(validateTrainConfig(tr), fmt.Sprintf("invalid Learning rate decay time: %f", tr.LDecayTime))
+}
+
+func TestValidateDecaySchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := makeDefaultTrainConfig()
+	tr.RDecay, tr.LDecay = "", ""
+	assert.Error(validateTrainConfig(tr))
+	tr.RSchedule = DecayFunc(func(iteration, totalIterations int) float64 { return 1.0 })
+	assert.Error(validateTrainConfig(tr))
+	tr.LSchedule = tr.RSchedule
+	assert.NoError(validateTrainConfig(tr))
+}
+
+func TestValidateWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := makeDefaultTrainConfig()
+	tr.Workers = 4
+	assert.NoError(validateTrainConfig(tr))
+	tr.Workers = -1
+	assert.EqualError(validateTrainConfig(tr), "invalid number of workers: -1")
+}
+
 func TestValidateLDecay(t *testing.T) {
 	assert := assert.New(t)
 
@@ -299,6 +466,7 @@ func TestValidateLDecay(t *testing.T) {
 		{"exp", false},
 		{"foobar", true},
 		{"inv", false},
+		{"pow", false},
 	}
 
 	origLDecay := tr.LDecay
@@ -313,3 +481,147 @@ func TestValidateLDecay(t *testing.T) {
 	}
 	tr.LDecay = origLDecay
 }
+
+func TestValidateGrowConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		gc     *GrowConfig
+		expErr bool
+	}{
+		{&GrowConfig{QError: 0.1, MaxUnits: 100, Iters: 10}, false},
+		{&GrowConfig{QError: 0.0, MaxUnits: 100, Iters: 10}, false},
+		{&GrowConfig{QError: -0.1, MaxUnits: 100, Iters: 10}, true},
+		{&GrowConfig{QError: 0.1, MaxUnits: 0, Iters: 10}, true},
+		{&GrowConfig{QError: 0.1, MaxUnits: 100, Iters: 0}, true},
+	}
+
+	for _, tc := range testCases {
+		err := validateGrowConfig(tc.gc)
+		if tc.expErr {
+			assert.Error(err)
+		} else {
+			assert.NoError(err)
+		}
+	}
+}
+
+func TestValidateGNGConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := makeDefaultGNGConfig()
+	assert.NoError(validateGNGConfig(c))
+	testCases := []func(c *GNGConfig){
+		func(c *GNGConfig) { c.MaxUnits = 1 },
+		func(c *GNGConfig) { c.Lambda = 0 },
+		func(c *GNGConfig) { c.WinnerRate = 1.5 },
+		func(c *GNGConfig) { c.NeighbRate = -0.1 },
+		func(c *GNGConfig) { c.MaxAge = 0 },
+		func(c *GNGConfig) { c.Alpha = -1.0 },
+		func(c *GNGConfig) { c.Decay = 2.0 },
+		func(c *GNGConfig) { c.Metric = "foobar" },
+	}
+
+	for _, tc := range testCases {
+		c := makeDefaultGNGConfig()
+		tc(c)
+		assert.Error(validateGNGConfig(c))
+	}
+}
+
+func TestValidateGHSOMConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := makeDefaultGHSOMConfig()
+	assert.NoError(validateGHSOMConfig(c))
+	testCases := []func(c *GHSOMConfig){
+		func(c *GHSOMConfig) { c.Map = nil },
+		func(c *GHSOMConfig) { c.Train = nil },
+		func(c *GHSOMConfig) { c.Train.Algorithm = "foobar" },
+		func(c *GHSOMConfig) { c.Iters = 0 },
+		func(c *GHSOMConfig) { c.Tau1 = 1.0 },
+		func(c *GHSOMConfig) { c.Tau1, c.MaxUnits = 0.5, 0 },
+		func(c *GHSOMConfig) { c.Tau2 = 0.0 },
+		func(c *GHSOMConfig) { c.MaxDepth = 0 },
+		func(c *GHSOMConfig) { c.MinSamples = 0 },
+	}
+
+	for _, tc := range testCases {
+		c := makeDefaultGHSOMConfig()
+		tc(c)
+		assert.Error(validateGHSOMConfig(c))
+	}
+}
+
+func TestValidateLVQConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &LVQConfig{Algorithm: "lvq1", LRate: 0.1}
+	assert.NoError(validateLVQConfig(c))
+	c.LRate = 0.0
+	assert.EqualError(validateLVQConfig(c), "invalid LVQ learning rate: 0.000000")
+	c.LRate, c.Algorithm = 0.1, "lvq2.1"
+	assert.EqualError(validateLVQConfig(c), "invalid LVQ window: 0.000000")
+	c.Window = 0.3
+	assert.NoError(validateLVQConfig(c))
+	c.Algorithm = "lvq3"
+	assert.EqualError(validateLVQConfig(c), "invalid LVQ epsilon: 0.000000")
+	c.Epsilon = 0.2
+	assert.NoError(validateLVQConfig(c))
+}
+
+func TestValidateFacetConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FacetConfig{}
+	assert.EqualError(validateFacetConfig(c), "no panels to render")
+	c.UMatrix = true
+	assert.NoError(validateFacetConfig(c))
+	c.UMatrix, c.Features = false, []int{0}
+	assert.NoError(validateFacetConfig(c))
+	c.Cols = -1
+	assert.EqualError(validateFacetConfig(c), "invalid number of panel columns: -1")
+}
+
+func TestValidateRenderConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &RenderConfig{}
+	assert.NoError(validateRenderConfig(c))
+	c.ColorMap = "viridis"
+	assert.NoError(validateRenderConfig(c))
+	c.CellSize = -1.0
+	assert.EqualError(validateRenderConfig(c), "invalid cell size: -1.000000")
+	c.CellSize, c.Margin = 20.0, -1.0
+	assert.EqualError(validateRenderConfig(c), "invalid margin: -1.000000")
+	c.Margin, c.StrokeWidth = 0.0, -1.0
+	assert.EqualError(validateRenderConfig(c), "invalid stroke width: -1.000000")
+	c.StrokeWidth, c.Smooth = 2.0, -1.0
+	assert.EqualError(validateRenderConfig(c), "invalid smoothing sigma: -1.000000")
+	c.Smooth = 1.5
+	assert.NoError(validateRenderConfig(c))
+	c.ColorMap = "foobar"
+	assert.EqualError(validateRenderConfig(c), "unsupported color map: foobar")
+	// invalid render config fails map creation
+	mc := makeDefaultMapCfg()
+	mc.Render = c
+	m, err := NewMap(mc, mat64.NewDense(2, 5, nil))
+	assert.Nil(m)
+	assert.EqualError(err, "unsupported color map: foobar")
+}
+
+func TestValidateShuffle(t *testing.T) {
+	assert := assert.New(t)
+
+	tr := makeDefaultTrainConfig()
+	for _, shuffle := range []string{"", "random", "none", "shuffle"} {
+		tr.Shuffle = shuffle
+		assert.NoError(validateTrainConfig(tr))
+	}
+	tr.Shuffle = "foobar"
+	assert.EqualError(validateTrainConfig(tr), "unsupported sample order: foobar")
+	tr.Shuffle = "stratified"
+	assert.EqualError(validateTrainConfig(tr), "invalid stratified shuffling classes: map[]")
+	tr.Classes = map[int]int{0: 1}
+	assert.NoError(validateTrainConfig(tr))
+}
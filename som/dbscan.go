@@ -0,0 +1,82 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// DBSCAN clusters vectors stored in codebook rows using DBSCAN algorithm. Vectors with at least c.MinPts
+// vectors within c.Eps distance are core vectors: clusters are formed by core vectors reachable from each
+// other through their neighbourhoods along with the non-core vectors in their neighbourhoods.
+// Vectors which don't belong to any cluster are noise. It returns a slice which contains cluster IDs of
+// all codebook vectors: noise vectors have ID -1, clusters are numbered from 0 in the order of their first vector.
+// It returns error if the configuration is invalid or if codebook is nil.
+func DBSCAN(codebook *mat64.Dense, c *DBSCANConfig) ([]int, error) {
+	if err := validateDBSCANConfig(c); err != nil {
+		return nil, err
+	}
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	metric := c.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	dist, err := DistanceMx(metric, codebook)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := codebook.Dims()
+	// neighbourhoods of all vectors
+	neighbs := make([][]int, n)
+	for i := range neighbs {
+		for j, d := range dist.RawRowView(i) {
+			if d <= c.Eps {
+				neighbs[i] = append(neighbs[i], j)
+			}
+		}
+	}
+	clusters := make([]int, n)
+	for i := range clusters {
+		clusters[i] = -1
+	}
+	visited := make([]bool, n)
+	next := 0
+	for i := range clusters {
+		if visited[i] || len(neighbs[i]) < c.MinPts {
+			continue
+		}
+		// expand new cluster from core vector i
+		queue := []int{i}
+		visited[i] = true
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			clusters[j] = next
+			if len(neighbs[j]) < c.MinPts {
+				continue
+			}
+			for _, k := range neighbs[j] {
+				if !visited[k] {
+					visited[k] = true
+					queue = append(queue, k)
+				}
+			}
+		}
+		next++
+	}
+
+	return clusters, nil
+}
+
+// DBSCAN clusters the map codebook vectors using DBSCAN algorithm with the map distance metric and returns
+// cluster IDs of all map units: c.Metric is ignored. It fails in the same way as DBSCAN function.
+func (m Map) DBSCAN(c *DBSCANConfig) ([]int, error) {
+	if c == nil {
+		return nil, fmt.Errorf("invalid DBSCAN configuration: %v", c)
+	}
+	cfg := *c
+	cfg.Metric = m.metric
+	return DBSCAN(m.codebook, &cfg)
+}
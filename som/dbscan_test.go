@@ -0,0 +1,40 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBSCAN(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(6, 1, []float64{0.0, 0.5, 1.0, 10.0, 10.5, 50.0})
+	clusters, err := DBSCAN(cbook, &DBSCANConfig{Eps: 0.6, MinPts: 2})
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 1, 1, -1}, clusters)
+	// only the middle vector of the first group is a core vector
+	clusters, err = DBSCAN(cbook, &DBSCANConfig{Eps: 0.6, MinPts: 3})
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, -1, -1, -1}, clusters)
+	// invalid configuration
+	for _, c := range []*DBSCANConfig{{Eps: 0, MinPts: 1}, {Eps: 1, MinPts: 0}, {Eps: 1, MinPts: 1, Metric: "foo"}} {
+		clusters, err = DBSCAN(cbook, c)
+		assert.Nil(clusters)
+		assert.Error(err)
+	}
+	clusters, err = DBSCAN(nil, &DBSCANConfig{Eps: 1, MinPts: 1})
+	assert.Nil(clusters)
+	assert.Error(err)
+	// map clustering uses the map metric
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 6}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "manhattan"}
+	clusters, err = m.DBSCAN(&DBSCANConfig{Eps: 0.6, MinPts: 2, Metric: "foo"})
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 1, 1, -1}, clusters)
+	clusters, err = m.DBSCAN(nil)
+	assert.Nil(clusters)
+	assert.Error(err)
+}
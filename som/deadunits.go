@@ -0,0 +1,112 @@
+package som
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// DeadUnits returns indices of map units which are not BMU of any vector stored in data rows.
+// It returns error if the data dimension and map codebook dimensions are not the same.
+func (m Map) DeadUnits(data *mat64.Dense) ([]int, error) {
+	hits, err := m.HitMap(data)
+	if err != nil {
+		return nil, err
+	}
+	dead := []int{}
+	for j, h := range hits {
+		if h == 0 {
+			dead = append(dead, j)
+		}
+	}
+
+	return dead, nil
+}
+
+// deadUnitsEpochFunc returns epoch function which calls epochEnd, if it is not nil, and then
+// reinitializes units which have been dead for c.DeadUnits.Epochs consecutive epochs
+func (m *Map) deadUnitsEpochFunc(c *TrainConfig, data *mat64.Dense, epochEnd epochFunc) epochFunc {
+	r := rand.New(randSource(c.Source))
+	idle := make([]int, m.grid.Units())
+	return func(epoch, iteration int) (bool, error) {
+		if epochEnd != nil {
+			if stop, err := epochEnd(epoch, iteration); stop || err != nil {
+				return stop, err
+			}
+		}
+		dead, err := m.DeadUnits(data)
+		if err != nil {
+			return true, err
+		}
+		isDead := make([]bool, len(idle))
+		for _, j := range dead {
+			isDead[j] = true
+		}
+		expired := []int{}
+		for j := range idle {
+			// units which have won since the previous epoch are alive again
+			if !isDead[j] {
+				idle[j] = 0
+				continue
+			}
+			if idle[j]++; idle[j] >= c.DeadUnits.Epochs {
+				expired = append(expired, j)
+				idle[j] = 0
+			}
+		}
+		if len(expired) == 0 {
+			return false, nil
+		}
+		samples, err := m.reinitSamples(c.DeadUnits.Policy, data, len(expired), r)
+		if err != nil {
+			return true, err
+		}
+		// units left without a sample stay expired and are reinitialized in the next epoch
+		for _, j := range expired[len(samples):] {
+			idle[j] = c.DeadUnits.Epochs
+		}
+		for i, j := range expired[:len(samples)] {
+			m.codebook.SetRow(j, data.RawRowView(samples[i]))
+		}
+		return false, nil
+	}
+}
+
+// reinitSamples returns indices of n complete data rows dead units are moved to according to the policy.
+// At most as many samples as there are complete data rows are returned.
+// The error policy picks the samples with the highest quantization error, the random policy picks random samples.
+func (m Map) reinitSamples(policy string, data *mat64.Dense, n int, r *rand.Rand) ([]int, error) {
+	rows, _ := data.Dims()
+	// only complete data rows can be picked
+	samples := []int{}
+	for i := 0; i < rows; i++ {
+		if !floats.HasNaN(data.RawRowView(i)) {
+			samples = append(samples, i)
+		}
+	}
+	if n > len(samples) {
+		n = len(samples)
+	}
+	if policy == "random" {
+		perm := r.Perm(len(samples))
+		for i := range perm[:n] {
+			perm[i] = samples[perm[i]]
+		}
+		return perm[:n], nil
+	}
+	errs := make([]float64, rows)
+	for _, i := range samples {
+		bmu, err := ClosestVec(m.metric, data.RawRowView(i), m.codebook)
+		if err != nil {
+			return nil, err
+		}
+		if errs[i], err = Distance(m.metric, data.RawRowView(i), m.codebook.RawRowView(bmu)); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(samples, func(a, b int) bool { return errs[samples[a]] > errs[samples[b]] })
+
+	return samples[:n], nil
+}
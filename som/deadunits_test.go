@@ -0,0 +1,154 @@
+package som
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadUnits(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 5.0, 6.0, 10.0})
+	newMap := func() *Map {
+		return &Map{
+			codebook: mat64.NewDense(3, 1, []float64{0.0, 1.0, 100.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+	}
+	m := newMap()
+	dead, err := m.DeadUnits(data)
+	assert.NoError(err)
+	assert.Equal([]int{2}, dead)
+	// mismatched dimensions
+	dead, err = m.DeadUnits(mat64.NewDense(1, 2, nil))
+	assert.Error(err)
+	assert.Nil(dead)
+}
+
+func TestTrainDeadUnits(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	// the last unit is too far away to ever win
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 5.0, 6.0, 10.0})
+	tc := &TrainConfig{
+		Algorithm: "batch",
+		RDecay:    "lin",
+		NeighbFn:  Bubble,
+		RSchedule: DecayFunc(func(iteration, totalIterations int) float64 { return 0.5 }),
+		LRate:     0.5,
+		LDecay:    "lin",
+	}
+	newMap := func() *Map {
+		return &Map{
+			codebook: mat64.NewDense(3, 1, []float64{0.0, 1.0, 100.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+	}
+	m := newMap()
+	assert.NoError(m.Train(tc, data, 5))
+	assert.Equal(100.0, m.codebook.At(2, 0))
+	// the dead unit is moved to the sample with the highest error
+	tc.DeadUnits = &DeadUnitConfig{Epochs: 2, Policy: "error"}
+	m = newMap()
+	assert.NoError(m.Train(tc, data, 5))
+	assert.Equal(10.0, m.codebook.At(2, 0))
+	dead, err := m.DeadUnits(data)
+	assert.NoError(err)
+	assert.Empty(dead)
+	// the dead unit is moved to a random sample
+	tc.DeadUnits.Policy = "random"
+	tc.Source = rand.NewSource(10)
+	m = newMap()
+	assert.NoError(m.Train(tc, data, 5))
+	assert.True(m.codebook.At(2, 0) <= 10.0)
+	// invalid config
+	tc.DeadUnits = &DeadUnitConfig{Epochs: 0, Policy: "error"}
+	assert.EqualError(m.Train(tc, data, 5), "invalid number of dead unit epochs: 0")
+	tc.DeadUnits = &DeadUnitConfig{Epochs: 1, Policy: "foo"}
+	assert.EqualError(m.Train(tc, data, 5), "unsupported dead unit policy: foo")
+}
+
+func TestTrainDeadUnitsMoreThanRows(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{3, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	// at least 7 units are dead every epoch but there are only 2 samples to move them to
+	data := mat64.NewDense(2, 1, []float64{0.0, 1.0})
+	for _, policy := range []string{"error", "random"} {
+		m := &Map{
+			codebook: mat64.NewDense(9, 1, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc := &TrainConfig{
+			Algorithm: "batch",
+			Radius:    0.5,
+			RDecay:    "lin",
+			NeighbFn:  Bubble,
+			LRate:     0.5,
+			LDecay:    "lin",
+			DeadUnits: &DeadUnitConfig{Epochs: 1, Policy: policy},
+			Source:    rand.NewSource(1),
+		}
+		assert.NotPanics(func() { assert.NoError(m.Train(tc, data, 3)) }, policy)
+		// the remaining dead units have been moved to the samples in the following epochs
+		moved := 0
+		for i := 0; i < 9; i++ {
+			if v := m.codebook.At(i, 0); v == 0.0 || v == 1.0 {
+				moved++
+			}
+		}
+		assert.True(moved > 2, policy)
+	}
+}
+
+func TestTrainDeadUnitsMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	// the incomplete sample has the highest quantization error
+	nan := math.NaN()
+	data := mat64.NewDense(5, 2, []float64{
+		0.0, 0.0,
+		0.1, 0.1,
+		5.0, 5.0,
+		6.0, 6.0,
+		50.0, nan,
+	})
+	for _, policy := range []string{"error", "random"} {
+		m := &Map{
+			codebook: mat64.NewDense(3, 2, []float64{0.0, 0.0, 1.0, 1.0, -100.0, -100.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc := &TrainConfig{
+			Algorithm: "seq",
+			RDecay:    "lin",
+			NeighbFn:  Bubble,
+			RSchedule: DecayFunc(func(iteration, totalIterations int) float64 { return 0.5 }),
+			LRate:     0.5,
+			LDecay:    "lin",
+			DeadUnits: &DeadUnitConfig{Epochs: 1, Policy: policy},
+			Source:    rand.NewSource(1),
+		}
+		assert.NoError(m.Train(tc, data, 5))
+		// the dead unit is moved to a complete sample
+		assert.NotEqual(-100.0, m.codebook.At(2, 0), policy)
+		for i := 0; i < 3; i++ {
+			assert.False(floats.HasNaN(m.codebook.RawRowView(i)), policy)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package som
 import (
 	"encoding/xml"
 	"fmt"
+	"image/color"
 	"io"
 	"math"
+	"time"
 
+	"github.com/gonum/floats"
 	"github.com/gonum/matrix/mat64"
 )
 
@@ -14,15 +17,23 @@ type rowWithDist struct {
 	Dist float64
 }
 
-type h1 struct {
-	XMLName xml.Name `xml:"h1"`
-	Title   string   `xml:",innerxml"`
+type svgTitle struct {
+	XMLName xml.Name `xml:"title"`
+	Text    string   `xml:",chardata"`
+}
+
+type svgDesc struct {
+	XMLName xml.Name `xml:"desc"`
+	Text    string   `xml:",chardata"`
 }
 
 type polygon struct {
 	XMLName xml.Name `xml:"polygon"`
 	Points  []byte   `xml:"points,attr"`
-	Style   string   `xml:"style,attr"`
+	Class   string   `xml:"class,attr,omitempty"`
+	Style   string   `xml:"style,attr,omitempty"`
+	Title   string   `xml:"title,omitempty"`
+	Animate []svgAnimate
 }
 
 type svgElement struct {
@@ -32,142 +43,681 @@ type svgElement struct {
 	Polygons []interface{}
 }
 
+type circle struct {
+	XMLName xml.Name `xml:"circle"`
+	Cx      float64  `xml:"cx,attr"`
+	Cy      float64  `xml:"cy,attr"`
+	R       float64  `xml:"r,attr"`
+	Class   string   `xml:"class,attr,omitempty"`
+	Style   string   `xml:"style,attr,omitempty"`
+	Title   string   `xml:"title,omitempty"`
+	Animate []svgAnimate
+}
+
+type line struct {
+	XMLName xml.Name `xml:"line"`
+	X1      float64  `xml:"x1,attr"`
+	Y1      float64  `xml:"y1,attr"`
+	X2      float64  `xml:"x2,attr"`
+	Y2      float64  `xml:"y2,attr"`
+	Class   string   `xml:"class,attr,omitempty"`
+	Style   string   `xml:"style,attr,omitempty"`
+}
+
+type path struct {
+	XMLName xml.Name `xml:"path"`
+	D       string   `xml:"d,attr"`
+	Class   string   `xml:"class,attr,omitempty"`
+	Style   string   `xml:"style,attr,omitempty"`
+	Title   string   `xml:"title,omitempty"`
+}
+
 type textElement struct {
-	XMLName xml.Name `xml:"text"`
-	X       float64  `xml:"x,attr"`
-	Y       float64  `xml:"y,attr"`
-	Text    string   `xml:",innerxml"`
+	XMLName  xml.Name `xml:"text"`
+	X        float64  `xml:"x,attr"`
+	Y        float64  `xml:"y,attr"`
+	Anchor   string   `xml:"text-anchor,attr,omitempty"`
+	Baseline string   `xml:"dominant-baseline,attr,omitempty"`
+	Class    string   `xml:"class,attr,omitempty"`
+	Text     string   `xml:",chardata"`
 }
 
 var colors = [][]int{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {255, 255, 0}, {255, 0, 255}, {0, 255, 255}}
 
+// unitStyle holds the information about how the map units are drawn
+type unitStyle struct {
+	// color is the color map of units, grayscale is used if it is nil
+	color ColorFunc
+	// legend enables rendering of the color legend strip
+	legend bool
+	// classes maps units to their classes: class units are colored by their class and labeled with its number
+	classes map[int]int
+	// markers holds sizes of the unit markers relative to the unit size, units with zero size have no marker
+	markers []float64
+	// labels holds unit labels drawn in the unit centers, units with empty labels are not labeled
+	labels []string
+	// tooltips holds unit tooltips shown when hovering over the units
+	tooltips []string
+	// samples maps units to the markers of the samples they are BMUs of, the markers are drawn over the units
+	samples map[int][]SampleMarker
+	// pies holds class counts of the units drawn as pie charts inside the units, units without counts have no pie chart
+	pies []map[int]int
+	// clusters holds unit clusters: edges shared by units of different clusters are drawn with thicker strokes
+	clusters []int
+	// trajectory holds the ordered path of units drawn as a polyline with arrowheads over the units
+	trajectory []int
+	// size is the size of the rendered units, 50 is used if it is 0
+	size float64
+	// off is the margin around the rendered map, 10 is used if it is 0
+	off float64
+	// strokeWidth is the width of the unit outlines, 1 is used if it is 0
+	strokeWidth float64
+	// stroke is the color of the unit outlines, black is used if it is nil
+	stroke color.Color
+	// background is the background color, no background is drawn if it is nil
+	background color.Color
+	// smooth is the sigma of the Gaussian kernel the U-Matrix is smoothed with, it is not smoothed if it is 0
+	smooth float64
+	// css replaces inline styles of SVG elements by classes defined in a style sheet of the document
+	css bool
+	// frames holds unit values of the animation frames: units are filled with the colors of the frames in turn
+	frames [][]float64
+	// frameDur is the duration of every animation frame
+	frameDur time.Duration
+}
+
+// cellSize returns the size of the rendered units
+func (s unitStyle) cellSize() float64 {
+	if s.size > 0 {
+		return s.size
+	}
+	return 50.0
+}
+
+// margin returns the margin around the rendered map
+func (s unitStyle) margin() float64 {
+	if s.off > 0 {
+		return s.off
+	}
+	return 10.0
+}
+
+// strokeColor returns the color of the unit outlines
+func (s unitStyle) strokeColor() color.Color {
+	if s.stroke != nil {
+		return s.stroke
+	}
+	return color.Black
+}
+
+// strokeStyle returns SVG style of the unit outlines
+func (s unitStyle) strokeStyle() string {
+	if s.stroke == nil && s.strokeWidth == 0 {
+		return "stroke:black;stroke-width:1"
+	}
+	width := s.strokeWidth
+	if width == 0 {
+		width = 1.0
+	}
+	return fmt.Sprintf("stroke:%s;stroke-width:%g", svgColor(s.strokeColor()), width)
+}
+
+// fillStyle returns SVG style of the unit filled with r, g, b color
+func (s unitStyle) fillStyle(r, g, b int) string {
+	return fmt.Sprintf("fill:rgb(%d,%d,%d);%s", r, g, b, s.strokeStyle())
+}
+
+// svgColor returns SVG representation of color c
+func svgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("rgb(%d,%d,%d)", r>>8, g>>8, b>>8)
+}
+
+// tooltip returns the tooltip of the given unit or empty string if the unit has no tooltip
+func (s unitStyle) tooltip(unit int) string {
+	if unit < len(s.tooltips) {
+		return s.tooltips[unit]
+	}
+	return ""
+}
+
+// unitSVG returns the SVG elements drawn on top of the unit whose center is at x, y.
+// size is the size of the rendered units.
+func (s unitStyle) unitSVG(unit int, x, y, size float64) []interface{} {
+	elems := []interface{}{}
+	if unit < len(s.markers) && s.markers[unit] > 0.0 {
+		elems = append(elems, circle{
+			Cx:    x,
+			Cy:    y,
+			R:     0.4 * size * s.markers[unit],
+			Class: "marker",
+			Style: "fill:rgb(255,0,0);" + s.strokeStyle(),
+		})
+	}
+	// print class number
+	if class, ok := s.classes[unit]; ok {
+		elems = append(elems, textElement{
+			X:     x - 0.25*size,
+			Y:     y + 0.25*size,
+			Class: "class",
+			Text:  fmt.Sprintf("%d", class),
+		})
+	}
+	if unit < len(s.pies) && len(s.pies[unit]) > 0 {
+		elems = append(elems, pieSVG(s.pies[unit], x, y, 0.4*size, s.strokeStyle())...)
+	}
+	elems = append(elems, s.sampleSVG(unit, x, y, size)...)
+	if unit < len(s.labels) && s.labels[unit] != "" {
+		elems = append(elems, textElement{
+			X:        x,
+			Y:        y,
+			Anchor:   "middle",
+			Baseline: "middle",
+			Class:    "label",
+			Text:     s.labels[unit],
+		})
+	}
+
+	return elems
+}
+
 // UMatrixSVG creates an SVG representation of the U-Matrix of the given codebook.
 // It accepts the following parameters:
 // codebook - the codebook we're displaying the U-Matrix for
-// dims     - the dimensions of the map grid
-// uShape   - the shape of the map grid
+// dims     - the dimensions of the map grid; 1D grids are rendered as a ribbon and 3D grids as one SVG slice per z-layer
+// uShape   - the shape of the map grid units: hexagon, rectangle or triangle
+// metric   - the distance metric used to compute the distances between codebook vectors
 // title    - the title of the output SVG
 // writer   - the io.Writter to write the output SVG to.
 // classes  - if the classes are known (i.e. these are test data) they can be displayed providing the information in this map.
 // The map is: codebook vector row -> class number. When classes are not known (i.e. running with real data), just provide an empty map
-func UMatrixSVG(codebook *mat64.Dense, dims []int, uShape, title string, writer io.Writer, classes map[int]int) error {
-	xmlEncoder := xml.NewEncoder(writer)
-	// array to hold the xml elements
-	elems := []interface{}{h1{Title: title}}
+func UMatrixSVG(codebook *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
 
-	rows, _ := codebook.Dims()
-	distMat, err := DistanceMx("euclidean", codebook)
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{classes: classes})
+}
+
+// LabelUMatrixSVG creates an SVG representation of the U-Matrix of the given codebook with every unit labeled
+// by its label drawn in the unit center. It accepts the same parameters as UMatrixSVG and labels - the unit labels
+// indexed by codebook rows; units with empty labels are not labeled.
+// It fails with error if the number of labels is different from the number of codebook vectors or if the grid coordinates could not be computed.
+func LabelUMatrixSVG(codebook *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, labels []string) error {
+	coords, err := GridCoords(uShape, dims)
 	if err != nil {
 		return err
 	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	if codebook == nil {
+		return fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if rows, _ := codebook.Dims(); len(labels) != rows {
+		return fmt.Errorf("invalid number of unit labels: %d, expected: %d", len(labels), rows)
+	}
+
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{labels: labels})
+}
+
+// HitMapSVG creates an SVG representation of the U-Matrix of the given codebook with every unit overlaid with
+// a marker whose area is proportional to the number of data samples stored in data rows the unit is the Best Match Unit of.
+// It accepts the same parameters as UMatrixSVG and data - the data set whose hits are displayed.
+// It fails with error if the hits could not be computed or if the grid coordinates could not be computed.
+func HitMapSVG(codebook, data *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer) error {
 	coords, err := GridCoords(uShape, dims)
 	if err != nil {
 		return err
 	}
-	coordsDistMat, err := DistanceMx("euclidean", coords)
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	hits, err := HitMap(codebook, data, metric)
 	if err != nil {
 		return err
 	}
 
-	umatrix := make([]float64, rows)
-	maxDistance := -math.MaxFloat64
-	minDistance := math.MaxFloat64
-	for row := 0; row < rows; row++ {
-		avgDistance := 0.0
-		// this is a rough approximation of the notion of neighbor grid coords
-		allRowsInRadius := allRowsInRadius(row, math.Sqrt2*1.01, coordsDistMat)
-		for _, rwd := range allRowsInRadius {
-			if rwd.Dist > 0.0 {
-				avgDistance += distMat.At(row, rwd.Row)
-			}
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{markers: hitMarkers(hits)})
+}
+
+// hitMarkers returns the unit marker sizes for the given unit hits: marker areas are proportional to the hits
+// and the unit with the most hits has the biggest marker.
+func hitMarkers(hits []int) []float64 {
+	maxHits := 0
+	for _, h := range hits {
+		if h > maxHits {
+			maxHits = h
 		}
-		avgDistance /= float64(len(allRowsInRadius) - 1)
-		umatrix[row] = avgDistance
-		if avgDistance > maxDistance {
-			maxDistance = avgDistance
+	}
+	markers := make([]float64, len(hits))
+	if maxHits == 0 {
+		return markers
+	}
+	for unit, h := range hits {
+		markers[unit] = math.Sqrt(float64(h) / float64(maxHits))
+	}
+
+	return markers
+}
+
+// ComponentPlaneSVG creates an SVG representation of the component plane of the given codebook feature:
+// every unit is shaded by the value of the feature in its codebook vector, the higher the value the darker the unit.
+// It accepts the same parameters as UMatrixSVG and feature - the index of the codebook column to display.
+// It fails with error if the feature index is out of the codebook dimension range or if the grid coordinates could not be computed.
+func ComponentPlaneSVG(codebook *mat64.Dense, dims []int, uShape string, feature int, title string, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return componentPlaneSVG(codebook, grid, feature, title, writer, unitStyle{classes: classes})
+}
+
+// componentPlaneSVG creates an SVG representation of the component plane of the given codebook feature and grid.
+func componentPlaneSVG(codebook *mat64.Dense, grid *Grid, feature int, title string, writer io.Writer, s unitStyle) error {
+	values, err := componentValues(codebook, grid, feature)
+	if err != nil {
+		return err
+	}
+
+	return valuesSVG(grid, values, floats.Min(values), floats.Max(values), title, writer, s)
+}
+
+// componentValues returns the values of the given codebook feature of all grid units.
+// It fails with error if the feature index is out of range or if the codebook does not match the grid.
+func componentValues(codebook *mat64.Dense, grid *Grid, feature int) ([]float64, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	rows, cols := codebook.Dims()
+	if feature < 0 || feature >= cols {
+		return nil, fmt.Errorf("invalid feature index: %d", feature)
+	}
+	if rows != grid.Units() {
+		return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", rows, grid.Units())
+	}
+
+	return mat64.Col(nil, feature, codebook), nil
+}
+
+// umatrixSVG creates an SVG representation of the U-Matrix of the given codebook and grid.
+// Unit neighbourhoods are determined using grid unit distances so the grid topology is respected.
+func umatrixSVG(codebook *mat64.Dense, grid *Grid, metric, title string, writer io.Writer, s unitStyle) error {
+	umatrix, minDistance, maxDistance, err := s.umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return err
+	}
+
+	return valuesSVG(grid, umatrix, minDistance, maxDistance, title, writer, s)
+}
+
+// valuesSVG creates an SVG document which contains the grid whose units are shaded by values
+// normalized to [minValue, maxValue] range and writes it to writer. Planar grid units are
+// streamed to writer as they are rendered. It fails with error if the write to writer fails.
+func valuesSVG(grid *Grid, values []float64, minValue, maxValue float64, title string, writer io.Writer, s unitStyle) error {
+	st := newSVGStream(writer)
+	st.css = s.css
+	desc := fmt.Sprintf("%s %s grid %v, values in [%.4g, %.4g]", grid.gtype, grid.ushape, grid.size, minValue, maxValue)
+	panels, draw := valuesPanels(st, grid, values, minValue, maxValue, title, s)
+
+	if !s.legend {
+		st.document(title, desc, stackedRows(panels...), draw)
+		return st.flush()
+	}
+	legend := legendSVG(minValue, maxValue, s.color)
+	mapPanels := len(panels)
+	panels = append(panels, svgFrame{width: legend.Width, height: legend.Height})
+	st.document(title, desc, stackedRows(panels...), func(i int, frame svgFrame) {
+		if i == mapPanels {
+			st.element(frame, legend, unitStyle{})
+			return
 		}
-		if avgDistance < maxDistance {
-			minDistance = avgDistance
+		draw(i, frame)
+	})
+
+	return st.flush()
+}
+
+// valuesPanels returns the sizes of the SVG panels which contain the grid whose units are shaded by values
+// normalized to [minValue, maxValue] range and the function which streams i-th panel into its frame to st.
+// 3D grids are rendered as one panel per z-layer titled by title and the layer, other grids as a single panel.
+func valuesPanels(st *svgStream, grid *Grid, values []float64, minValue, maxValue float64, title string,
+	s unitStyle) ([]svgFrame, func(i int, frame svgFrame)) {
+	mul, off := s.cellSize(), s.margin()
+	// spherical grids are rendered using map projection, graph grids using their layout
+	// and 1D grids as a ribbon of units
+	switch {
+	case grid.gtype == "sphere" || grid.gtype == "graph":
+		var svgElem svgElement
+		if grid.gtype == "sphere" {
+			svgElem = sphereSVG(grid, values, minValue, maxValue, s)
+		} else {
+			svgElem = graphSVG(grid, values, minValue, maxValue, s)
+		}
+		return []svgFrame{{width: svgElem.Width, height: svgElem.Height}}, func(i int, frame svgFrame) {
+			st.element(frame, svgElem, s)
+		}
+	case len(grid.size) == 1:
+		return []svgFrame{{width: float64(grid.size[0])*mul + 2*off, height: mul + 2*off}}, func(i int, frame svgFrame) {
+			chainSVG(st, frame, grid, values, minValue, maxValue, s)
+		}
+	}
+	dims, uShape, coords := grid.size, grid.ushape, grid.coords
+	// 3D grids are rendered as one SVG panel per z-layer
+	layers := 1
+	if len(dims) == 3 {
+		layers = dims[2]
+	}
+	layerUnits := len(values) / layers
+	width, height, _, _ := layerBounds(dims, uShape, mul, off)
+	panels := make([]svgFrame, layers)
+	for layer := range panels {
+		panels[layer] = svgFrame{width: width, height: height}
+		if len(dims) == 3 {
+			panels[layer].title = fmt.Sprintf("%s (z=%d)", title, layer)
 		}
 	}
 
-	// function to scale the coord grid to something visible
-	const MUL = 50.0
+	return panels, func(i int, frame svgFrame) {
+		umatrixSVGLayer(st, frame, coords, dims, uShape, values, minValue, maxValue, i*layerUnits, layerUnits, s)
+	}
+}
+
+// legendSVG creates an SVG element which contains a color legend strip mapping colors of colorFn to values
+// in [minValue, maxValue] range. The strip is labeled by the minimum and maximum value on its ends.
+func legendSVG(minValue, maxValue float64, colorFn ColorFunc) svgElement {
 	const OFF = 10.0
-	scale := func(x float64) float64 { return MUL*x + OFF }
+	const WIDTH = 200.0
+	const HEIGHT = 20.0
+	const STRIPES = 20
+	if colorFn == nil {
+		colorFn = Grayscale
+	}
 
 	svgElem := svgElement{
-		Width:    float64(dims[1])*MUL + 2*OFF,
-		Height:   float64(dims[0])*MUL + 2*OFF,
-		Polygons: make([]interface{}, rows*2),
+		Width:    WIDTH + 2*OFF,
+		Height:   HEIGHT + 3*OFF + 10.0,
+		Polygons: make([]interface{}, 0, STRIPES+4),
 	}
-	for row := 0; row < rows; row++ {
-		coord := coords.RowView(row)
-		var colorMask []int
-		classID, classFound := classes[row]
-		// if no class information, just use shades of gray
-		if !classFound || classID == -1 {
-			colorMask = []int{255, 255, 255}
-		} else {
-			colorMask = colors[classes[row]%len(colors)]
+	stripe := WIDTH / STRIPES
+	for i := 0; i < STRIPES; i++ {
+		x := OFF + float64(i)*stripe
+		c := colorFn((float64(i) + 0.5) / STRIPES)
+		polygonCoords := ""
+		polygonCoords += fmt.Sprintf("%f,%f ", x, OFF)
+		polygonCoords += fmt.Sprintf("%f,%f ", x+stripe, OFF)
+		polygonCoords += fmt.Sprintf("%f,%f ", x+stripe, OFF+HEIGHT)
+		polygonCoords += fmt.Sprintf("%f,%f ", x, OFF+HEIGHT)
+		polygonCoords += fmt.Sprintf("%f,%f ", x, OFF)
+		svgElem.Polygons = append(svgElem.Polygons, polygon{
+			Points: []byte(polygonCoords),
+			Class:  "legend",
+			Style:  fmt.Sprintf("fill:rgb(%d,%d,%d);stroke:none", c.R, c.G, c.B),
+		})
+	}
+	// min and max ticks with their labels
+	for i, v := range []float64{minValue, maxValue} {
+		x, anchor := OFF, "start"
+		if i == 1 {
+			x, anchor = OFF+WIDTH, "end"
 		}
-		colorMul := 1.0 - (umatrix[row]-minDistance)/(maxDistance-minDistance)
-		r := int(colorMul * float64(colorMask[0]))
-		g := int(colorMul * float64(colorMask[1]))
-		b := int(colorMul * float64(colorMask[2]))
+		svgElem.Polygons = append(svgElem.Polygons, line{
+			X1:    x,
+			Y1:    OFF,
+			X2:    x,
+			Y2:    OFF + HEIGHT + 0.5*OFF,
+			Class: "legend",
+			Style: "stroke:black;stroke-width:1",
+		}, textElement{
+			X:      x,
+			Y:      OFF + HEIGHT + 2*OFF,
+			Anchor: anchor,
+			Class:  "legend",
+			Text:   fmt.Sprintf("%.4g", v),
+		})
+	}
+
+	return svgElem
+}
+
+// umatrixSVGLayer streams an SVG element described by frame which contains count units starting at unit from to st
+func umatrixSVGLayer(st *svgStream, frame svgFrame, coords *mat64.Dense, dims []int, uShape string, umatrix []float64,
+	minDistance, maxDistance float64, from, count int, s unitStyle) {
+	// scale the coord grid to something visible
+	mul, off := s.cellSize(), s.margin()
+
+	_, _, xOff, yOff := layerBounds(dims, uShape, mul, off)
+	st.start(frame, s)
+	// unit polygons and centers are only kept if the cluster boundaries or trajectory are drawn
+	polygons := make(map[int][][2]float64)
+	centers := make(map[int][2]float64)
+	for row := from; row < from+count; row++ {
+		coord := coords.RowView(row)
+		r, g, b := s.unitRGB(row, umatrix, minDistance, maxDistance)
+		x := mul*coord.At(0, 0) + xOff
+		y := mul*coord.At(1, 0) + yOff
+		vertices := unitPolygon(dims, uShape, row, x, y, mul)
 		polygonCoords := ""
-		x := scale(coord.At(0, 0))
-		y := scale(coord.At(1, 0))
-		// hexagon has a different yOffset
-		switch uShape {
-		case "hexagon":
-			{
-				xOffset := 0.5 * MUL
-				yBigOffset := math.Tan(math.Pi/6.0) * MUL
-				ySmallOffset := yBigOffset / 2.0
-				// draw a hexagon around the current coord
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y+ySmallOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x, y+yBigOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x-xOffset, y+ySmallOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x-xOffset, y-ySmallOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x, y-yBigOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y-ySmallOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y+ySmallOffset)
-			}
-		default:
-			{
-				xOffset := 0.5 * MUL
-				yOffset := 0.5 * MUL
-				// draw a box around the current coord
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y+yOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y-yOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x-xOffset, y-yOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x-xOffset, y+yOffset)
-				polygonCoords += fmt.Sprintf("%f,%f ", x+xOffset, y+yOffset)
-			}
+		for _, p := range vertices {
+			polygonCoords += fmt.Sprintf("%f,%f ", p[0], p[1])
 		}
 
-		svgElem.Polygons[row*2] = polygon{
-			Points: []byte(polygonCoords),
-			Style:  fmt.Sprintf("fill:rgb(%d,%d,%d);stroke:black;stroke-width:1", r, g, b),
+		st.encode(polygon{
+			Points:  []byte(polygonCoords),
+			Class:   "unit",
+			Style:   s.fillStyle(r, g, b),
+			Title:   s.tooltip(row),
+			Animate: s.unitAnimation(row, minDistance, maxDistance),
+		})
+		st.encode(s.unitSVG(row, x, y, mul)...)
+		if len(s.clusters) > 0 {
+			polygons[row] = vertices
+		}
+		if len(s.trajectory) > 0 {
+			centers[row] = [2]float64{x, y}
 		}
+	}
 
-		// print class number
-		if classFound {
-			svgElem.Polygons[row*2+1] = textElement{
-				X:    x - 0.25*MUL,
-				Y:    y + 0.25*MUL,
-				Text: fmt.Sprintf("%d", classes[row]),
-			}
+	st.encode(s.boundarySVG(polygons)...)
+	st.encode(s.trajectorySVG(centers, mul)...)
+	st.end()
+}
+
+// layerBounds returns width and height of the rendered 2D grid layer of units of size mul with margin off
+// and the offsets of the unit coordinates which fit the units into the layer.
+func layerBounds(dims []int, uShape string, mul, off float64) (float64, float64, float64, float64) {
+	switch uShape {
+	case "triangle":
+		// triangles are narrower and rows of triangles are taller than other units.
+		// Triangles are drawn around their centroids which are sqrt(0.75) wide and half of the unit high off their corners
+		width := (float64(dims[1])+1)*math.Sqrt(0.75)*mul + 2*off
+		height := float64(dims[0])*1.5*mul + 2*off
+		return width, height, off + math.Sqrt(0.75)*mul, off + 0.5*mul
+	case "hexagon":
+		// hexagons are drawn around their centres so the grid is shifted to fit them in.
+		// Odd rows are shifted by half of the hexagon width and the rows are sqrt(0.75) apart
+		width := float64(dims[1])*mul + 2*off
+		if dims[0] > 1 {
+			width += 0.5 * mul
+		}
+		height := (float64(dims[0]-1)*math.Sqrt(0.75)+2/math.Sqrt(3))*mul + 2*off
+		return width, height, off + 0.5*mul, off + mul/math.Sqrt(3)
+	}
+	// rectangles are drawn around their centres so the grid is shifted by half of the unit size to fit them in
+	return float64(dims[1])*mul + 2*off, float64(dims[0])*mul + 2*off, off + 0.5*mul, off + 0.5*mul
+}
+
+// unitPolygon returns the closed polygon of vertices of the unit stored in row whose center is at x, y.
+// size is the size of the rendered units.
+func unitPolygon(dims []int, uShape string, row int, x, y, size float64) [][2]float64 {
+	switch uShape {
+	case "hexagon":
+		xOffset := 0.5 * size
+		yBigOffset := math.Tan(math.Pi/6.0) * size
+		ySmallOffset := yBigOffset / 2.0
+		// draw a hexagon around the current coord
+		return [][2]float64{
+			{x + xOffset, y + ySmallOffset},
+			{x, y + yBigOffset},
+			{x - xOffset, y + ySmallOffset},
+			{x - xOffset, y - ySmallOffset},
+			{x, y - yBigOffset},
+			{x + xOffset, y - ySmallOffset},
+			{x + xOffset, y + ySmallOffset},
+		}
+	case "triangle":
+		xOffset := math.Sqrt(0.75) * size
+		yBigOffset := size
+		ySmallOffset := 0.5 * size
+		// every other triangle points down
+		if !triangleUp(row/dims[0], row%dims[0]) {
+			yBigOffset, ySmallOffset = -yBigOffset, -ySmallOffset
+		}
+		// draw a triangle around the current coord
+		return [][2]float64{
+			{x - xOffset, y - ySmallOffset},
+			{x + xOffset, y - ySmallOffset},
+			{x, y + yBigOffset},
+			{x - xOffset, y - ySmallOffset},
+		}
+	}
+	xOffset := 0.5 * size
+	yOffset := 0.5 * size
+	// draw a box around the current coord
+	return [][2]float64{
+		{x + xOffset, y + yOffset},
+		{x + xOffset, y - yOffset},
+		{x - xOffset, y - yOffset},
+		{x - xOffset, y + yOffset},
+		{x + xOffset, y + yOffset},
+	}
+}
+
+// chainSVG streams an SVG element described by frame which contains the U-Matrix of 1D grid units
+// drawn as a ribbon of adjacent squares to st
+func chainSVG(st *svgStream, frame svgFrame, grid *Grid, umatrix []float64, minDistance, maxDistance float64, s unitStyle) {
+	// scale the coord grid to something visible
+	mul, off := s.cellSize(), s.margin()
+	scale := func(x float64) float64 { return mul*x + off }
+
+	units := grid.size[0]
+	st.start(frame, s)
+	polygons := make(map[int][][2]float64)
+	centers := make(map[int][2]float64)
+	for row := 0; row < units; row++ {
+		x := scale(grid.coords.At(row, 0))
+		y := off
+		r, g, b := s.unitRGB(row, umatrix, minDistance, maxDistance)
+		// draw a square to the right of the current coord
+		vertices := [][2]float64{{x, y}, {x + mul, y}, {x + mul, y + mul}, {x, y + mul}, {x, y}}
+		polygonCoords := ""
+		for _, p := range vertices {
+			polygonCoords += fmt.Sprintf("%f,%f ", p[0], p[1])
+		}
+		st.encode(polygon{
+			Points:  []byte(polygonCoords),
+			Class:   "unit",
+			Style:   s.fillStyle(r, g, b),
+			Title:   s.tooltip(row),
+			Animate: s.unitAnimation(row, minDistance, maxDistance),
+		})
+		st.encode(s.unitSVG(row, x+0.5*mul, y+0.5*mul, mul)...)
+		if len(s.clusters) > 0 {
+			polygons[row] = vertices
+		}
+		if len(s.trajectory) > 0 {
+			centers[row] = [2]float64{x + 0.5*mul, y + 0.5*mul}
 		}
 	}
 
-	elems = append(elems, svgElem)
+	st.encode(s.boundarySVG(polygons)...)
+	st.encode(s.trajectorySVG(centers, mul)...)
+	st.end()
+}
+
+// unitRGB returns the fill color of the unit stored in row of the U-Matrix.
+// The color is given by the unit color map or it is a shade of the unit class color if the unit class is known.
+func (s unitStyle) unitRGB(row int, umatrix []float64, minDistance, maxDistance float64) (int, int, int) {
+	classID, classFound := s.classes[row]
+	// if no class information, just use the color map
+	if !classFound || classID == -1 {
+		colorFn := s.color
+		if colorFn == nil {
+			colorFn = Grayscale
+		}
+		c := colorFn(normValue(umatrix[row], minDistance, maxDistance))
+		return int(c.R), int(c.G), int(c.B)
+	}
+	colorMask := colors[classID%len(colors)]
+	colorMul := 1.0 - normValue(umatrix[row], minDistance, maxDistance)
+	r := int(colorMul * float64(colorMask[0]))
+	g := int(colorMul * float64(colorMask[1]))
+	b := int(colorMul * float64(colorMask[2]))
+	return r, g, b
+}
 
-	xmlEncoder.Encode(elems)
-	xmlEncoder.Flush()
+// normValue normalizes v from [minValue, maxValue] to [0,1] interval.
+// If the interval is empty, it returns 0.
+func normValue(v, minValue, maxValue float64) float64 {
+	if maxValue <= minValue {
+		return 0.0
+	}
+	return (v - minValue) / (maxValue - minValue)
+}
+
+// umatrixValues computes average distance of each codebook vector to codebook vectors of its
+// neighbouring grid units. It returns the computed values along with their minimum and maximum.
+// Only the distances to the neighbouring units are computed, so large maps can be rendered.
+func umatrixValues(codebook *mat64.Dense, grid *Grid, metric string) ([]float64, float64, float64, error) {
+	rows, _ := codebook.Dims()
+	neighbs, err := unitNeighbours(grid)
+	if err != nil {
+		return nil, 0.0, 0.0, err
+	}
+
+	umatrix := make([]float64, rows)
+	maxDistance := -math.MaxFloat64
+	minDistance := math.MaxFloat64
+	for row := 0; row < rows; row++ {
+		avgDistance := 0.0
+		for _, n := range neighbs[row] {
+			d, err := Distance(metric, codebook.RawRowView(row), codebook.RawRowView(n))
+			if err != nil {
+				return nil, 0.0, 0.0, err
+			}
+			avgDistance += d
+		}
+		avgDistance /= float64(len(neighbs[row]))
+		umatrix[row] = avgDistance
+		if avgDistance > maxDistance {
+			maxDistance = avgDistance
+		}
+		if avgDistance < minDistance {
+			minDistance = avgDistance
+		}
+	}
 
-	return nil
+	return umatrix, minDistance, maxDistance, nil
 }
 
 func allRowsInRadius(selectedRow int, radius float64, distMatrix *mat64.Dense) []rowWithDist {
@@ -2,6 +2,8 @@ package som
 
 import (
 	"bytes"
+	"fmt"
+	"image/color"
 	"strings"
 	"testing"
 
@@ -12,7 +14,7 @@ import (
 func TestUMatrixSVG(t *testing.T) {
 	assert := assert.New(t)
 
-	const svg = `<h1>Done</h1><svg width="120" height="120"><polygon points="35.000000,24.433757 10.000000,38.867513 -15.000000,24.433757 -15.000000,-4.433757 10.000000,-18.867513 35.000000,-4.433757 35.000000,24.433757 " style="fill:rgb(255,255,255);stroke:black;stroke-width:1"></polygon><polygon points="60.000000,67.735027 35.000000,82.168784 10.000000,67.735027 10.000000,38.867513 35.000000,24.433757 60.000000,38.867513 60.000000,67.735027 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><polygon points="85.000000,24.433757 60.000000,38.867513 35.000000,24.433757 35.000000,-4.433757 60.000000,-18.867513 85.000000,-4.433757 85.000000,24.433757 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><polygon points="110.000000,67.735027 85.000000,82.168784 60.000000,67.735027 60.000000,38.867513 85.000000,24.433757 110.000000,38.867513 110.000000,67.735027 " style="fill:rgb(255,255,255);stroke:black;stroke-width:1"></polygon></svg>`
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" width="145" height="121.03629710818451" viewBox="0 0 145 121.03629710818451"><title>Done</title><desc>planar hexagon grid [2 2], values in [0.7571, 0.9532]</desc><polygon points="60.000000,53.301270 35.000000,67.735027 10.000000,53.301270 10.000000,24.433757 35.000000,10.000000 60.000000,24.433757 60.000000,53.301270 " style="fill:rgb(255,255,255);stroke:black;stroke-width:1"></polygon><polygon points="85.000000,96.602540 60.000000,111.036297 35.000000,96.602540 35.000000,67.735027 60.000000,53.301270 85.000000,67.735027 85.000000,96.602540 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><polygon points="110.000000,53.301270 85.000000,67.735027 60.000000,53.301270 60.000000,24.433757 85.000000,10.000000 110.000000,24.433757 110.000000,53.301270 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><polygon points="135.000000,96.602540 110.000000,111.036297 85.000000,96.602540 85.000000,67.735027 110.000000,53.301270 135.000000,67.735027 135.000000,96.602540 " style="fill:rgb(255,255,255);stroke:black;stroke-width:1"></polygon></svg>`
 
 	mUnits := mat64.NewDense(4, 2, []float64{
 		0.0, 0.0,
@@ -25,7 +27,7 @@ func TestUMatrixSVG(t *testing.T) {
 	title := "Done"
 	writer := bytes.NewBufferString("")
 
-	UMatrixSVG(mUnits, coordDims, uShape, title, writer, make(map[int]int))
+	UMatrixSVG(mUnits, coordDims, uShape, "euclidean", title, writer, make(map[int]int))
 
 	assert.Equal(svg, writer.String())
 	// make sure there is at least one fully black element
@@ -37,7 +39,7 @@ func TestUMatrixSVG(t *testing.T) {
 func TestUMatrixSVGWithClusters(t *testing.T) {
 	assert := assert.New(t)
 
-	const svg = `<h1>Done</h1><svg width="70" height="120"><polygon points="35.000000,35.000000 35.000000,-15.000000 -15.000000,-15.000000 -15.000000,35.000000 35.000000,35.000000 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><text x="-2.5" y="22.5">0</text><polygon points="35.000000,85.000000 35.000000,35.000000 -15.000000,35.000000 -15.000000,85.000000 35.000000,85.000000 " style="fill:rgb(0,0,0);stroke:black;stroke-width:1"></polygon><text x="-2.5" y="72.5">1</text></svg>`
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" width="70" height="120" viewBox="0 0 70 120"><title>Done</title><desc>planar rectangle grid [2 1], values in [1.414, 1.414]</desc><polygon points="60.000000,60.000000 60.000000,10.000000 10.000000,10.000000 10.000000,60.000000 60.000000,60.000000 " style="fill:rgb(255,0,0);stroke:black;stroke-width:1"></polygon><text x="22.5" y="47.5">0</text><polygon points="60.000000,110.000000 60.000000,60.000000 10.000000,60.000000 10.000000,110.000000 60.000000,110.000000 " style="fill:rgb(0,255,0);stroke:black;stroke-width:1"></polygon><text x="22.5" y="97.5">1</text></svg>`
 
 	mUnits := mat64.NewDense(2, 2, []float64{
 		0.0, 0.0,
@@ -52,9 +54,208 @@ func TestUMatrixSVGWithClusters(t *testing.T) {
 		0: 0,
 		1: 1,
 	}
-	UMatrixSVG(mUnits, coordDims, uShape, title, writer, classes)
+	UMatrixSVG(mUnits, coordDims, uShape, "euclidean", title, writer, classes)
 
 	assert.Equal(svg, writer.String())
 	// make sure there is at least one text element
 	assert.True(strings.Contains(svg, "<text "))
 }
+
+func TestUMatrixSVGTriangle(t *testing.T) {
+	assert := assert.New(t)
+
+	mUnits := mat64.NewDense(4, 2, []float64{
+		0.0, 0.0,
+		0.0, 0.1,
+		1.0, 1.0,
+		1.0, 1.1,
+	})
+	writer := bytes.NewBufferString("")
+	err := UMatrixSVG(mUnits, []int{2, 2}, "triangle", "euclidean", "Done", writer, make(map[int]int))
+	assert.NoError(err)
+	svg := writer.String()
+	assert.Equal(4, strings.Count(svg, "<polygon "))
+	// unit 0 points up and unit 1 points down
+	assert.True(strings.Contains(svg, `points="10.000000,10.000000 96.602540,10.000000 53.301270,85.000000 10.000000,10.000000 "`))
+	assert.True(strings.Contains(svg, `points="10.000000,160.000000 96.602540,160.000000 53.301270,85.000000 10.000000,160.000000 "`))
+}
+
+func TestUMatrixSVGTriangleBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := []int{2, 3}
+	mUnits := mat64.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5})
+	var buf bytes.Buffer
+	assert.NoError(UMatrixSVG(mUnits, dims, "triangle", "euclidean", "Tri", &buf, make(map[int]int)))
+	// all triangles have three corners and fit in the svg
+	assertPolygonsInBounds(assert, buf.String(), 6, 4)
+}
+
+func TestUMatrixSVGHexagonBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := []int{3, 4}
+	mUnits := mat64.NewDense(12, 1, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	var buf bytes.Buffer
+	assert.NoError(UMatrixSVG(mUnits, dims, "hexagon", "euclidean", "Hex", &buf, make(map[int]int)))
+	// all hexagons have six corners and fit in the svg
+	assertPolygonsInBounds(assert, buf.String(), 12, 7)
+}
+
+func TestUMatrixSVGRectangleBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := []int{2, 3}
+	mUnits := mat64.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5})
+	var buf bytes.Buffer
+	assert.NoError(UMatrixSVG(mUnits, dims, "rectangle", "euclidean", "Rect", &buf, make(map[int]int)))
+	// all rectangles have four corners and fit in the svg
+	assertPolygonsInBounds(assert, buf.String(), 6, 5)
+	// the margin is kept around the units
+	assert.True(strings.Contains(buf.String(), `points="60.000000,60.000000 60.000000,10.000000 10.000000,10.000000 10.000000,60.000000 60.000000,60.000000 "`))
+}
+
+// assertPolygonsInBounds asserts svg document contains the given number of unit polygons
+// of the given number of closed polygon corners which all fit in the svg
+func assertPolygonsInBounds(assert *assert.Assertions, svg string, units, corners int) {
+	var width, height float64
+	_, err := fmt.Sscanf(svg, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\"", &width, &height)
+	assert.NoError(err)
+	polygons := strings.Split(svg, "points=\"")[1:]
+	assert.Len(polygons, units)
+	for _, p := range polygons {
+		points := strings.Fields(p[:strings.Index(p, "\"")])
+		assert.Len(points, corners)
+		for _, point := range points {
+			var x, y float64
+			_, err := fmt.Sscanf(point, "%g,%g", &x, &y)
+			assert.NoError(err)
+			assert.True(x >= 0 && x <= width, point)
+			assert.True(y >= 0 && y <= height, point)
+		}
+	}
+}
+
+func TestComponentPlaneSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	mUnits := mat64.NewDense(4, 2, []float64{
+		0.0, 5.0,
+		1.0, 5.0,
+		2.0, 5.0,
+		4.0, 5.0,
+	})
+	writer := bytes.NewBufferString("")
+	err := ComponentPlaneSVG(mUnits, []int{2, 2}, "rectangle", 0, "Feature 0", writer, make(map[int]int))
+	assert.NoError(err)
+	svg := writer.String()
+	assert.True(strings.Contains(svg, "Feature 0"))
+	assert.Equal(4, strings.Count(svg, "<polygon "))
+	// the smallest value is the lightest and the largest the darkest
+	assert.True(strings.Contains(svg, "rgb(255,255,255)"))
+	assert.True(strings.Contains(svg, "rgb(0,0,0)"))
+	// constant feature is rendered without NaN shades
+	writer.Reset()
+	err = ComponentPlaneSVG(mUnits, []int{2, 2}, "rectangle", 1, "Feature 1", writer, make(map[int]int))
+	assert.NoError(err)
+	assert.Equal(4, strings.Count(writer.String(), "rgb(255,255,255)"))
+	// invalid feature index
+	err = ComponentPlaneSVG(mUnits, []int{2, 2}, "rectangle", 2, "Feature 2", writer, make(map[int]int))
+	assert.EqualError(err, "invalid feature index: 2")
+	err = ComponentPlaneSVG(mUnits, []int{2, 2}, "rectangle", -1, "Feature -1", writer, make(map[int]int))
+	assert.EqualError(err, "invalid feature index: -1")
+	// codebook and grid mismatch
+	err = ComponentPlaneSVG(mUnits, []int{3, 3}, "rectangle", 0, "Feature 0", writer, make(map[int]int))
+	assert.Error(err)
+}
+
+func TestLabelUMatrixSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	mUnits := mat64.NewDense(3, 2, []float64{
+		0.0, 0.0,
+		0.0, 0.1,
+		1.0, 1.0,
+	})
+	writer := bytes.NewBufferString("")
+	err := LabelUMatrixSVG(mUnits, []int{1, 3}, "rectangle", "euclidean", "Labels", writer, []string{"setosa", "", "a<b"})
+	assert.NoError(err)
+	svg := writer.String()
+	// labels are centered in the units and escaped
+	assert.True(strings.Contains(svg, `<text x="35" y="35" text-anchor="middle" dominant-baseline="middle">setosa</text>`))
+	assert.True(strings.Contains(svg, `<text x="135" y="35" text-anchor="middle" dominant-baseline="middle">a&lt;b</text>`))
+	assert.Equal(2, strings.Count(svg, "<text "))
+	// map unit labels
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: mUnits, grid: grid, metric: "euclidean"}
+	var buf bytes.Buffer
+	assert.NoError(m.LabelUMatrix(&buf, []string{"setosa", "", "a<b"}, "svg", "Labels"))
+	assert.Equal(svg, buf.String())
+	// invalid number of labels
+	err = LabelUMatrixSVG(mUnits, []int{1, 3}, "rectangle", "euclidean", "Labels", writer, []string{"setosa"})
+	assert.EqualError(err, "invalid number of unit labels: 1, expected: 3")
+	assert.Error(m.LabelUMatrix(&buf, []string{"setosa"}, "svg", "Labels"))
+	assert.Error(m.LabelUMatrix(&buf, []string{"a", "b", "c"}, "png", "Labels"))
+}
+
+func TestLegendSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(3, 1, []float64{0.0, 1.0, 3.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Legend"))
+	assert.Equal(1, strings.Count(buf.String(), "<svg "))
+	// legend is rendered as a separate strip labeled by the minimum and maximum distance
+	m.render = &RenderConfig{Legend: true, ColorMap: "heat"}
+	buf.Reset()
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Legend"))
+	svg := buf.String()
+	assert.Equal(3, strings.Count(svg, "<svg "))
+	assert.True(strings.Contains(svg, `<svg x="0" y="70" width="220" height="60">`))
+	assert.True(strings.Contains(svg, `<text x="10" y="50" text-anchor="start">1</text>`))
+	assert.True(strings.Contains(svg, `<text x="210" y="50" text-anchor="end">2</text>`))
+	// the strip goes from the color of minimum to the color of maximum
+	assert.True(strings.Contains(svg, "fill:rgb(19,0,0);stroke:none"))
+	assert.True(strings.Contains(svg, "fill:rgb(255,255,236);stroke:none"))
+	assert.Equal(2, strings.Count(svg, "<line "))
+}
+
+func TestRenderConfigSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(2, 1, []float64{0.0, 1.0}),
+		grid:     grid,
+		metric:   "euclidean",
+		render: &RenderConfig{
+			CellSize:    20.0,
+			Margin:      5.0,
+			StrokeWidth: 2.0,
+			StrokeColor: color.RGBA{R: 255, A: 255},
+			Background:  color.White,
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Options"))
+	svg := buf.String()
+	assert.True(strings.Contains(svg, `width="50" height="30" viewBox="0 0 50 30">`))
+	// background is drawn below the units
+	assert.True(strings.Contains(svg, `</desc><polygon points="0,0 50.000000,0 50.000000,30.000000 0,30.000000 0,0 " style="fill:rgb(255,255,255);stroke:none">`))
+	assert.Equal(2, strings.Count(svg, "stroke:rgb(255,0,0);stroke-width:2"))
+	assert.True(strings.Contains(svg, `points="25.000000,25.000000 25.000000,5.000000 5.000000,5.000000 5.000000,25.000000 25.000000,25.000000 "`))
+	// default options
+	m.render = nil
+	buf.Reset()
+	assert.NoError(m.UMatrix(&buf, nil, nil, "svg", "Options"))
+	assert.True(strings.Contains(buf.String(), `width="120" height="70" viewBox="0 0 120 70">`))
+	assert.Equal(2, strings.Count(buf.String(), "stroke:black;stroke-width:1"))
+}
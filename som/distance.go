@@ -4,13 +4,40 @@ import (
 	"container/heap"
 	"fmt"
 	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gonum/matrix/mat64"
+	"github.com/gonum/stat"
 )
 
+// BinaryThreshold is the value at which vector components are considered to be set
+// when computing binary metrics such as hamming and jaccard. It allows to compare binary
+// data with real valued codebook vectors.
+const BinaryThreshold = 0.5
+
+// registry holds distance metrics registered under custom names
+var registry = struct {
+	sync.RWMutex
+	metrics map[string]DistanceFunc
+}{metrics: make(map[string]DistanceFunc)}
+
 // Distance calculates metric distance between vectors a and b.
-// If unsupported metric is requested Distance returns euclidean distance.
+// Supported metrics are euclidean, cosine, manhattan, chebyshev, correlation, canberra, hamming,
+// jaccard and minkowski. Hamming and jaccard treat vector components as binary values (see BinaryThreshold).
+// Minkowski metric requires an exponent p to be specified as "minkowski:p", e.g. "minkowski:1.5".
+// Distance also supports custom metrics registered via RegisterDistance.
+// Missing vector components encoded as NaN are ignored: the distance is computed over the components
+// observed in both vectors only. Distances of additive metrics, i.e. euclidean, manhattan, canberra, hamming
+// and minkowski, are rescaled to the number of all vector components so that the distances of vectors
+// with different numbers of missing components are comparable. The distance of vectors which have no
+// component observed in both of them is 0. Custom metrics are computed over the whole vectors whose
+// missing components are set to zero. If unsupported metric is requested Distance returns euclidean distance.
 // It returns error if the supplied vectors are either nil or have different dimensions
+// or if the minkowski exponent is invalid.
 func Distance(metric string, a, b []float64) (float64, error) {
 	if a == nil || b == nil {
 		return 0.0, fmt.Errorf("invalid vectors supplied. a: %v, b: %v", a, b)
@@ -19,30 +46,248 @@ func Distance(metric string, a, b []float64) (float64, error) {
 		return 0.0, fmt.Errorf("Incorrect vector dims. a: %d, b: %d", len(a), len(b))
 	}
 
-	switch metric {
-	case "euclidean":
-		return euclideanVec(a, b), nil
-	default:
-		return euclideanVec(a, b), nil
+	distFn, err := distanceFunc(metric)
+	if err != nil {
+		return 0.0, err
+	}
+	if !missing(a, b) {
+		return distFn(a, b)
+	}
+	// custom metrics may depend on the positions of vector components
+	if _, ok := registeredMetric(metric); ok {
+		a, b = zeroMissing(a, b)
+		return distFn(a, b)
+	}
+	oa, ob := observed(a, b)
+	if len(oa) == 0 {
+		return 0.0, nil
+	}
+	d, err := distFn(oa, ob)
+	if err != nil {
+		return 0.0, err
+	}
+
+	return rescaleObserved(metric, d, float64(len(a))/float64(len(oa))), nil
+}
+
+// missing returns true if any component of vectors a or b is missing
+func missing(a, b []float64) bool {
+	for i := 0; i < len(a); i++ {
+		if math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// observed returns vectors which only contain the components of vectors a and b observed in both of them
+func observed(a, b []float64) ([]float64, []float64) {
+	oa, ob := make([]float64, 0, len(a)), make([]float64, 0, len(b))
+	for i := 0; i < len(a); i++ {
+		if !math.IsNaN(a[i]) && !math.IsNaN(b[i]) {
+			oa, ob = append(oa, a[i]), append(ob, b[i])
+		}
+	}
+	return oa, ob
+}
+
+// zeroMissing returns copies of vectors a and b in which the components missing in either of them
+// are set to zero so that they don't contribute to additive distances between the vectors
+func zeroMissing(a, b []float64) ([]float64, []float64) {
+	za, zb := make([]float64, len(a)), make([]float64, len(b))
+	for i := 0; i < len(a); i++ {
+		if !math.IsNaN(a[i]) && !math.IsNaN(b[i]) {
+			za[i], zb[i] = a[i], b[i]
+		}
+	}
+	return za, zb
+}
+
+// rescaleObserved rescales distance d computed over the observed vector components using the supplied metric
+// to the number of all vector components. ratio is the ratio of the number of all components to the observed ones.
+// Sums of component differences are multiplied by ratio, distances of non-additive metrics are not rescaled.
+func rescaleObserved(metric string, d, ratio float64) float64 {
+	switch {
+	case metric == "cosine" || metric == "correlation" || metric == "chebyshev" || metric == "jaccard":
+		return d
+	case metric == "manhattan" || metric == "canberra" || metric == "hamming":
+		return d * ratio
+	case strings.HasPrefix(metric, "minkowski"):
+		// the exponent has already been validated
+		p, _ := minkowskiExp(metric)
+		return d * math.Pow(ratio, 1/p)
 	}
+	// unsupported metrics fall back to euclidean distance
+	return d * math.Sqrt(ratio)
 }
 
 // DistanceMx calculates metric distance matrix for the supplied matrix.
 // Distance matrix is also known in literature as dissimilarity matrix.
 // DistanceMx returns a hollow symmetric matrix where an item x_ij contains the distance between
-// vectors stored in rows i and j.  If an unknown metric is supplied Euclidean distance is computed.
-// It returns error if the supplied matrix is nil.
+// vectors stored in rows i and j. DistanceMx supports the same metrics as Distance.
+// If an unknown metric is supplied Euclidean distance is computed.
+// It returns error if the supplied matrix is nil or if the minkowski exponent is invalid.
 func DistanceMx(metric string, m *mat64.Dense) (*mat64.Dense, error) {
 	if m == nil {
 		return nil, fmt.Errorf("invalid matrix supplied: %v", m)
 	}
 
-	switch metric {
-	case "euclidean":
-		return euclideanMx(m), nil
-	default:
-		return euclideanMx(m), nil
+	distFn, err := distanceFunc(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return distanceMx(m, distFn)
+}
+
+// distanceFunc returns distance function for the requested metric.
+// If unsupported metric is requested it returns euclidean distance function.
+// It returns error if the metric parameters are invalid.
+func distanceFunc(metric string) (DistanceFunc, error) {
+	switch {
+	case metric == "euclidean":
+		return func(a, b []float64) (float64, error) { return euclideanVec(a, b), nil }, nil
+	case metric == "cosine":
+		return func(a, b []float64) (float64, error) { return cosineVec(a, b), nil }, nil
+	case metric == "manhattan":
+		return func(a, b []float64) (float64, error) { return manhattanVec(a, b), nil }, nil
+	case metric == "chebyshev":
+		return func(a, b []float64) (float64, error) { return chebyshevVec(a, b), nil }, nil
+	case metric == "correlation":
+		return func(a, b []float64) (float64, error) { return correlationVec(a, b), nil }, nil
+	case metric == "canberra":
+		return func(a, b []float64) (float64, error) { return canberraVec(a, b), nil }, nil
+	case metric == "hamming":
+		return func(a, b []float64) (float64, error) { return hammingVec(a, b), nil }, nil
+	case metric == "jaccard":
+		return func(a, b []float64) (float64, error) { return jaccardVec(a, b), nil }, nil
+	case strings.HasPrefix(metric, "minkowski"):
+		p, err := minkowskiExp(metric)
+		if err != nil {
+			return nil, err
+		}
+		return func(a, b []float64) (float64, error) { return minkowskiVec(a, b, p), nil }, nil
+	}
+	// look up registered metrics
+	if distFn, ok := registeredMetric(metric); ok {
+		return distFn, nil
+	}
+
+	return func(a, b []float64) (float64, error) { return euclideanVec(a, b), nil }, nil
+}
+
+// minkowskiExp parses minkowski metric exponent from metric string in "minkowski:p" format.
+// It returns error if the exponent is missing or it is not a positive number.
+func minkowskiExp(metric string) (float64, error) {
+	parts := strings.SplitN(metric, ":", 2)
+	if parts[0] != "minkowski" || len(parts) != 2 {
+		return 0.0, fmt.Errorf("invalid minkowski metric: %s", metric)
+	}
+	p, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || math.IsNaN(p) || p <= 0.0 {
+		return 0.0, fmt.Errorf("invalid minkowski exponent: %s", parts[1])
+	}
+
+	return p, nil
+}
+
+// RegisterMahalanobis registers Mahalanobis distance metric under the given name.
+// The registered metric can then be used anywhere a metric name is accepted.
+// If cov is not nil it is used as the covariance matrix, otherwise the covariance matrix is
+// estimated from data. RegisterMahalanobis returns error if the name is empty or clashes with
+// a builtin metric, if neither cov nor data is supplied or if the covariance matrix is not invertible.
+func RegisterMahalanobis(name string, cov mat64.Matrix, data *mat64.Dense) error {
+	if err := validateMetricName(name); err != nil {
+		return err
+	}
+	// estimate covariance matrix from data
+	if cov == nil {
+		if data == nil {
+			return fmt.Errorf("invalid data supplied: %v", data)
+		}
+		cov = stat.CovarianceMatrix(nil, data, nil)
 	}
+	rows, cols := cov.Dims()
+	if rows != cols {
+		return fmt.Errorf("Covariance matrix must be square: %d x %d", rows, cols)
+	}
+	invCov := new(mat64.Dense)
+	if err := invCov.Inverse(cov); err != nil {
+		return fmt.Errorf("Could not invert covariance matrix: %s", err)
+	}
+
+	return RegisterDistance(name, func(a, b []float64) (float64, error) {
+		if len(a) != rows {
+			return 0.0, fmt.Errorf("Incorrect vector dims. cov: %d, a: %d", rows, len(a))
+		}
+		return mahalanobisVec(a, b, invCov), nil
+	})
+}
+
+// RegisterWeightedEuclidean registers weighted euclidean distance metric under the given name.
+// Each squared coordinate difference is multiplied by the corresponding item in weights, so
+// the features can be emphasized or suppressed in BMU search and U-Matrix computation.
+// It returns error if the name is empty or clashes with a builtin metric or if weights
+// are empty or contain negative numbers.
+func RegisterWeightedEuclidean(name string, weights []float64) error {
+	if len(weights) == 0 {
+		return fmt.Errorf("invalid weights supplied: %v", weights)
+	}
+	for _, w := range weights {
+		if w < 0.0 || math.IsNaN(w) {
+			return fmt.Errorf("Negative weights supplied: %v", weights)
+		}
+	}
+	// avoid modifying the weights after registration
+	w := make([]float64, len(weights))
+	copy(w, weights)
+
+	return RegisterDistance(name, func(a, b []float64) (float64, error) {
+		if len(a) != len(w) {
+			return 0.0, fmt.Errorf("Incorrect vector dims. weights: %d, a: %d", len(w), len(a))
+		}
+		return weightedEuclideanVec(a, b, w), nil
+	})
+}
+
+// RegisterDistance registers custom distance function fn under the given name.
+// The registered metric can then be used anywhere a metric name is accepted: in codebook
+// configuration, BMU search or U-Matrix rendering. Registering a metric under the name which
+// has already been registered replaces the original distance function.
+// It returns error if fn is nil or if the name is empty or clashes with a builtin metric.
+func RegisterDistance(name string, fn DistanceFunc) error {
+	if err := validateMetricName(name); err != nil {
+		return err
+	}
+	if fn == nil {
+		return fmt.Errorf("invalid distance function: %v", fn)
+	}
+
+	registry.Lock()
+	registry.metrics[name] = fn
+	registry.Unlock()
+
+	return nil
+}
+
+// registeredMetric looks up distance function registered under name metric
+func registeredMetric(metric string) (DistanceFunc, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	distFn, ok := registry.metrics[metric]
+	return distFn, ok
+}
+
+// validateMetricName checks whether a custom metric can be registered under the given name.
+// It returns error if the name is empty or if it is reserved by a builtin metric.
+func validateMetricName(name string) error {
+	if name == "" {
+		return fmt.Errorf("invalid metric name: %s", name)
+	}
+	if _, ok := metrics[name]; ok || strings.HasPrefix(name, "minkowski") {
+		return fmt.Errorf("metric name reserved by builtin metric: %s", name)
+	}
+	return nil
 }
 
 // ClosestVec finds the closest vector to v in the list of vectors stored in m rows
@@ -133,6 +378,136 @@ func ClosestNVec(metric string, n int, v []float64, m *mat64.Dense) ([]int, erro
 // a particular data sample. If some data row has more than one BMU the index of the first one found is used.
 // It returns error if either the data or codebook are nil or if their dimensions are mismatched.
 func BMUs(data, codebook *mat64.Dense) ([]int, error) {
+	return bmus("euclidean", data, codebook)
+}
+
+// BMU finds the Best Match Unit (BMU) of sample among codebook vectors stored in codebook rows using
+// the supplied distance metric. It returns the BMU index and its distance to the sample.
+// If several units have the same distance to the sample, the index of the first one found is returned.
+// It returns error if either codebook or sample are nil or if their dimensions are mismatched.
+// When BMU fails with error the returned index is set to -1.
+func BMU(codebook *mat64.Dense, sample *mat64.Vector, metric string) (int, float64, error) {
+	if codebook == nil {
+		return -1, 0.0, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if sample == nil {
+		return -1, 0.0, fmt.Errorf("invalid sample supplied: %v", sample)
+	}
+	v := mat64.Col(nil, 0, sample)
+	unit, err := ClosestVec(metric, v, codebook)
+	if err != nil {
+		return -1, 0.0, err
+	}
+	dist, err := Distance(metric, v, codebook.RawRowView(unit))
+	if err != nil {
+		return -1, 0.0, err
+	}
+
+	return unit, dist, nil
+}
+
+// KBMU finds k Best Match Units of sample among codebook vectors stored in codebook rows using
+// the supplied distance metric. It returns a slice of unit indices ordered by their distance
+// to the sample, starting with the BMU, and a slice of the corresponding distances.
+// It returns error if either codebook or sample are nil, if their dimensions are mismatched or
+// if k is not a positive integer or is higher than the number of codebook vectors.
+func KBMU(codebook *mat64.Dense, sample *mat64.Vector, k int, metric string) ([]int, []float64, error) {
+	if codebook == nil {
+		return nil, nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if sample == nil {
+		return nil, nil, fmt.Errorf("invalid sample supplied: %v", sample)
+	}
+	v := mat64.Col(nil, 0, sample)
+	units, err := ClosestNVec(metric, k, v, codebook)
+	if err != nil {
+		return nil, nil, err
+	}
+	dists := make([]float64, k)
+	for i, unit := range units {
+		if dists[i], err = Distance(metric, v, codebook.RawRowView(unit)); err != nil {
+			return nil, nil, err
+		}
+	}
+	// ClosestNVec does not order the closest vectors
+	sort.Sort(unitsByDist{units: units, dists: dists})
+
+	return units, dists, nil
+}
+
+// unitsByDist sorts units by their distances, ties are broken by unit index
+type unitsByDist struct {
+	units []int
+	dists []float64
+}
+
+func (u unitsByDist) Len() int { return len(u.units) }
+
+func (u unitsByDist) Less(i, j int) bool {
+	if u.dists[i] == u.dists[j] {
+		return u.units[i] < u.units[j]
+	}
+	return u.dists[i] < u.dists[j]
+}
+
+func (u unitsByDist) Swap(i, j int) {
+	u.units[i], u.units[j] = u.units[j], u.units[i]
+	u.dists[i], u.dists[j] = u.dists[j], u.dists[i]
+}
+
+// MapRows finds the Best Match Unit (BMU) of every vector stored in data rows among codebook vectors
+// stored in codebook rows using the supplied distance metric. The data rows are split across
+// as many goroutines as there are CPUs. It returns a slice of BMU indices and a slice of BMU distances:
+// i-th item of each slice corresponds to i-th data row. It returns error if either data or codebook
+// are nil or if their dimensions are mismatched.
+func MapRows(codebook, data *mat64.Dense, metric string) ([]int, []float64, error) {
+	if data == nil {
+		return nil, nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if codebook == nil {
+		return nil, nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	rows, _ := data.Dims()
+	units := make([]int, rows)
+	dists := make([]float64, rows)
+	workers := runtime.NumCPU()
+	if workers > rows {
+		workers = rows
+	}
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// every worker maps a contiguous block of data rows
+			for i := w * rows / workers; i < (w+1)*rows/workers; i++ {
+				v := data.RawRowView(i)
+				unit, err := ClosestVec(metric, v, codebook)
+				if err != nil {
+					errs[w] = err
+					return
+				}
+				if dists[i], err = Distance(metric, v, codebook.RawRowView(unit)); err != nil {
+					errs[w] = err
+					return
+				}
+				units[i] = unit
+			}
+		}(w)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return units, dists, nil
+}
+
+// bmus returns a slice of BMU indices for each row in data using the supplied distance metric
+func bmus(metric string, data, codebook *mat64.Dense) ([]int, error) {
 	// data can't be nil
 	if data == nil {
 		return nil, fmt.Errorf("invalid data supplied: %v", data)
@@ -146,7 +521,7 @@ func BMUs(data, codebook *mat64.Dense) ([]int, error) {
 	bmus := make([]int, rows)
 	// loop through all data
 	for i := 0; i < rows; i++ {
-		idx, err := ClosestVec("euclidean", data.RawRowView(i), codebook)
+		idx, err := ClosestVec(metric, data.RawRowView(i), codebook)
 		if err != nil {
 			return nil, err
 		}
@@ -166,23 +541,195 @@ func euclideanVec(a, b []float64) float64 {
 	return math.Sqrt(d)
 }
 
-// euclideanMx computes a matrix of euclidean distances between each row in m
-func euclideanMx(m *mat64.Dense) *mat64.Dense {
+// manhattanVec computes manhattan (L1) distance between vectors a and b.
+func manhattanVec(a, b []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		d += math.Abs(a[i] - b[i])
+	}
+
+	return d
+}
+
+// minkowskiVec computes minkowski distance of order p between vectors a and b.
+// For p == 1 it is equal to manhattan distance, for p == 2 to euclidean distance
+// and for p == +Inf to chebyshev distance.
+func minkowskiVec(a, b []float64, p float64) float64 {
+	switch {
+	case p == 1.0:
+		return manhattanVec(a, b)
+	case p == 2.0:
+		return euclideanVec(a, b)
+	case math.IsInf(p, 1):
+		return chebyshevVec(a, b)
+	}
+
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		d += math.Pow(math.Abs(a[i]-b[i]), p)
+	}
+
+	return math.Pow(d, 1/p)
+}
+
+// weightedEuclideanVec computes weighted euclidean distance between vectors a and b.
+func weightedEuclideanVec(a, b, w []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		d += w[i] * (a[i] - b[i]) * (a[i] - b[i])
+	}
+
+	return math.Sqrt(d)
+}
+
+// mahalanobisVec computes mahalanobis distance between vectors a and b
+// given the inverse of covariance matrix invCov.
+func mahalanobisVec(a, b []float64, invCov *mat64.Dense) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		row := invCov.RawRowView(i)
+		for j := 0; j < len(a); j++ {
+			d += (a[i] - b[i]) * row[j] * (a[j] - b[j])
+		}
+	}
+	// guard against negative values caused by rounding errors
+	if d < 0.0 {
+		return 0.0
+	}
+
+	return math.Sqrt(d)
+}
+
+// chebyshevVec computes chebyshev (L-infinity) distance between vectors a and b
+// i.e. the maximum absolute difference between their coordinates.
+func chebyshevVec(a, b []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		if diff := math.Abs(a[i] - b[i]); diff > d {
+			d = diff
+		}
+	}
+
+	return d
+}
+
+// cosineVec computes cosine distance between vectors a and b i.e. 1 - cos(a, b).
+// If both vectors are zero vectors it returns 0; if only one of them is, it returns 1.
+func cosineVec(a, b []float64) float64 {
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for i := 0; i < len(a); i++ {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	switch {
+	case normA == 0.0 && normB == 0.0:
+		return 0.0
+	case normA == 0.0 || normB == 0.0:
+		return 1.0
+	}
+
+	return 1.0 - dot/(math.Sqrt(normA)*math.Sqrt(normB))
+}
+
+// correlationVec computes correlation distance between vectors a and b i.e. 1 - r(a, b)
+// where r is Pearson correlation coefficient. If both vectors are constant it returns 0;
+// if only one of them is, it returns 1.
+func correlationVec(a, b []float64) float64 {
+	n := float64(len(a))
+	meanA, meanB := 0.0, 0.0
+	for i := 0; i < len(a); i++ {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= n
+	meanB /= n
+
+	cov, varA, varB := 0.0, 0.0, 0.0
+	for i := 0; i < len(a); i++ {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+		varA += (a[i] - meanA) * (a[i] - meanA)
+		varB += (b[i] - meanB) * (b[i] - meanB)
+	}
+
+	switch {
+	case varA == 0.0 && varB == 0.0:
+		return 0.0
+	case varA == 0.0 || varB == 0.0:
+		return 1.0
+	}
+
+	return 1.0 - cov/(math.Sqrt(varA)*math.Sqrt(varB))
+}
+
+// canberraVec computes canberra distance between vectors a and b.
+// Components where both a and b are equal to zero do not contribute to the distance.
+func canberraVec(a, b []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		if denom := math.Abs(a[i]) + math.Abs(b[i]); denom > 0.0 {
+			d += math.Abs(a[i]-b[i]) / denom
+		}
+	}
+
+	return d
+}
+
+// hammingVec computes hamming distance between binary vectors a and b
+// i.e. the number of components in which the vectors differ.
+func hammingVec(a, b []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		if (a[i] >= BinaryThreshold) != (b[i] >= BinaryThreshold) {
+			d++
+		}
+	}
+
+	return d
+}
+
+// jaccardVec computes jaccard distance between binary vectors a and b i.e. 1 - |a & b| / |a | b|.
+// If neither of the vectors have any component set it returns 0.
+func jaccardVec(a, b []float64) float64 {
+	intersection, union := 0.0, 0.0
+	for i := 0; i < len(a); i++ {
+		setA, setB := a[i] >= BinaryThreshold, b[i] >= BinaryThreshold
+		if setA && setB {
+			intersection++
+		}
+		if setA || setB {
+			union++
+		}
+	}
+
+	if union == 0.0 {
+		return 0.0
+	}
+
+	return 1.0 - intersection/union
+}
+
+// distanceMx computes a matrix of distances between each row in m using distance function fn
+// It returns error if fn fails to compute the distance between any two rows of m
+func distanceMx(m *mat64.Dense, fn DistanceFunc) (*mat64.Dense, error) {
 	rows, _ := m.Dims()
 	out := mat64.NewDense(rows, rows, nil)
 
 	for row := 0; row < rows-1; row++ {
-		dist := 0.0
 		a := m.RawRowView(row)
 		for i := row + 1; i < rows; i++ {
 			if i != row {
 				b := m.RawRowView(i)
-				dist = euclideanVec(a, b)
+				dist, err := fn(a, b)
+				if err != nil {
+					return nil, err
+				}
 				out.Set(row, i, dist)
 				out.Set(i, row, dist)
 			}
 		}
 	}
 
-	return out
+	return out, nil
 }
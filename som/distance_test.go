@@ -2,6 +2,7 @@ package som
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"testing"
 
@@ -30,6 +31,174 @@ func TestDistance(t *testing.T) {
 		assert.InDelta(tc.expected, dist, 0.01)
 	}
 
+	// cosine distance
+	metric = "cosine"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{1.0, 0.0}, []float64{0.0, 1.0}, 1.0},
+		{metric, []float64{1.0, 1.0}, []float64{2.0, 2.0}, 0.0},
+		{metric, []float64{1.0, 0.0}, []float64{-1.0, 0.0}, 2.0},
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 0.0}, 0.0},
+		{metric, []float64{0.0, 0.0}, []float64{1.0, 0.0}, 1.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// manhattan distance
+	metric = "manhattan"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 1.0}, 1.0},
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 0.0}, 0.0},
+		{metric, []float64{3.0, 1.0}, []float64{1.0, 3.0}, 4.0},
+		{metric, []float64{-1.0, 2.0}, []float64{1.0, -2.0}, 6.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// chebyshev distance
+	metric = "chebyshev"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 1.0}, 1.0},
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 0.0}, 0.0},
+		{metric, []float64{3.0, 1.0}, []float64{1.0, 4.0}, 3.0},
+		{metric, []float64{-1.0, 2.0}, []float64{1.0, -2.0}, 4.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// correlation distance
+	metric = "correlation"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{1.0, 2.0, 3.0}, []float64{2.0, 4.0, 6.0}, 0.0},
+		{metric, []float64{1.0, 2.0, 3.0}, []float64{3.0, 2.0, 1.0}, 2.0},
+		{metric, []float64{1.0, 2.0, 3.0}, []float64{1.0, 3.0, 2.0}, 0.5},
+		{metric, []float64{1.0, 1.0, 1.0}, []float64{2.0, 2.0, 2.0}, 0.0},
+		{metric, []float64{1.0, 1.0, 1.0}, []float64{1.0, 2.0, 3.0}, 1.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// canberra distance
+	metric = "canberra"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{1.0, 2.0}, []float64{1.0, 2.0}, 0.0},
+		{metric, []float64{1.0, 0.0}, []float64{3.0, 0.0}, 0.5},
+		{metric, []float64{1.0, -1.0}, []float64{3.0, 1.0}, 1.5},
+		{metric, []float64{0.0, 0.0}, []float64{0.0, 0.0}, 0.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// hamming distance
+	metric = "hamming"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{1.0, 0.0, 1.0}, []float64{1.0, 0.0, 1.0}, 0.0},
+		{metric, []float64{1.0, 0.0, 1.0}, []float64{0.0, 1.0, 1.0}, 2.0},
+		{metric, []float64{1.0, 0.0, 1.0}, []float64{0.7, 0.2, 0.4}, 1.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// jaccard distance
+	metric = "jaccard"
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{metric, []float64{1.0, 0.0, 1.0}, []float64{1.0, 0.0, 1.0}, 0.0},
+		{metric, []float64{1.0, 0.0, 1.0}, []float64{0.0, 1.0, 1.0}, 0.667},
+		{metric, []float64{1.0, 0.0, 0.0}, []float64{0.0, 1.0, 0.0}, 1.0},
+		{metric, []float64{0.0, 0.0, 0.0}, []float64{0.0, 0.0, 0.0}, 0.0},
+		{metric, []float64{1.0, 1.0, 0.0}, []float64{0.9, 0.1, 0.0}, 0.5},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// minkowski distance
+	testCases = []struct {
+		metric   string
+		a        []float64
+		b        []float64
+		expected float64
+	}{
+		{"minkowski:1", []float64{3.0, 1.0}, []float64{1.0, 3.0}, 4.0},
+		{"minkowski:2", []float64{3.0, 1.0}, []float64{1.0, 3.0}, 2.828},
+		{"minkowski:3", []float64{3.0, 1.0}, []float64{1.0, 3.0}, 2.519},
+		{"minkowski:inf", []float64{3.0, 1.0}, []float64{1.0, 4.0}, 3.0},
+		{"minkowski:0.5", []float64{0.0, 0.0}, []float64{1.0, 1.0}, 4.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.01)
+	}
+
+	// invalid minkowski exponent returns error
+	for _, metric := range []string{"minkowski", "minkowski:0", "minkowski:foo"} {
+		_, err := Distance(metric, []float64{0.0}, []float64{1.0})
+		assert.Error(err)
+	}
+
 	// foobar metric returns euclidean distance
 	a := []float64{0.0, 0.0}
 	b := []float64{0.0, 1.0}
@@ -48,6 +217,53 @@ func TestDistance(t *testing.T) {
 	assert.Equal(0.0, d)
 }
 
+func TestDistanceMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	a := []float64{1.0, nan, 3.0}
+	b := []float64{4.0, 5.0, nan}
+	// only the first component is observed in both vectors, additive distances are rescaled to all components
+	dists := map[string]float64{
+		"euclidean":   3.0 * math.Sqrt(3.0),
+		"manhattan":   9.0,
+		"chebyshev":   3.0,
+		"minkowski:3": 3.0 * math.Cbrt(3.0),
+		"hamming":     0.0,
+		"canberra":    3.0 * 0.6,
+	}
+	for metric, dist := range dists {
+		d, err := Distance(metric, a, b)
+		assert.NoError(err)
+		assert.InDelta(dist, d, 0.0001, metric)
+	}
+	// correlation is computed over the observed components only
+	d, err := Distance("correlation", []float64{1.0, 2.0, nan, 3.0}, []float64{11.0, 12.0, 20.0, 13.0})
+	assert.NoError(err)
+	assert.InDelta(0.0, d, 0.0001)
+	// cosine distance is not rescaled
+	d, err = Distance("cosine", []float64{1.0, 0.0, nan}, []float64{1.0, 1.0, 7.0})
+	assert.NoError(err)
+	assert.InDelta(1.0-1.0/math.Sqrt(2.0), d, 0.0001)
+	// vectors without commonly observed components
+	d, err = Distance("correlation", []float64{1.0, nan}, []float64{nan, 2.0})
+	assert.NoError(err)
+	assert.Equal(0.0, d)
+	// custom metrics get whole vectors with missing components set to zero
+	assert.NoError(RegisterWeightedEuclidean("weighted-missing", []float64{1.0, 1.0, 1.0}))
+	d, err = Distance("weighted-missing", a, b)
+	assert.NoError(err)
+	assert.InDelta(3.0, d, 0.0001)
+	// supplied vectors are not modified
+	assert.True(math.IsNaN(a[1]))
+	assert.True(math.IsNaN(b[2]))
+	// closest vector ignores missing components
+	m := mat64.NewDense(2, 3, []float64{0.0, 10.0, 10.0, 1.0, 0.0, 0.0})
+	closest, err := ClosestVec("euclidean", []float64{1.0, nan, nan}, m)
+	assert.NoError(err)
+	assert.Equal(1, closest)
+}
+
 func TestDistanceMx(t *testing.T) {
 	assert := assert.New(t)
 
@@ -105,12 +321,225 @@ func TestDistanceMx(t *testing.T) {
 	assert.NoError(err)
 	assert.True(mat64.EqualApprox(negativeOutExpected, negativeOut, 0.01))
 
+	cosine := mat64.NewDense(3, 2, []float64{
+		1.0, 0.0,
+		0.0, 1.0,
+		2.0, 0.0,
+	})
+
+	cosineR, _ := cosine.Dims()
+	cosineOutExpected := mat64.NewDense(cosineR, cosineR, []float64{
+		0.0, 1.0, 0.0,
+		1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0,
+	})
+
+	cosineOut, err := DistanceMx("cosine", cosine)
+
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(cosineOutExpected, cosineOut, 0.01))
+
+	manhattan := mat64.NewDense(2, 3, []float64{
+		33.0, 33.0, 33.0,
+		83.0, 58.0, 58.0,
+	})
+
+	manhattanR, _ := manhattan.Dims()
+	manhattanOutExpected := mat64.NewDense(manhattanR, manhattanR, []float64{
+		0.0, 100.0,
+		100.0, 0.0,
+	})
+
+	manhattanOut, err := DistanceMx("manhattan", manhattan)
+
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(manhattanOutExpected, manhattanOut, 0.01))
+
+	chebyshevOutExpected := mat64.NewDense(manhattanR, manhattanR, []float64{
+		0.0, 50.0,
+		50.0, 0.0,
+	})
+
+	chebyshevOut, err := DistanceMx("chebyshev", manhattan)
+
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(chebyshevOutExpected, chebyshevOut, 0.01))
+
+	minkowskiOut, err := DistanceMx("minkowski:1", manhattan)
+
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(manhattanOutExpected, minkowskiOut, 0.01))
+
+	minkowskiOut, err = DistanceMx("minkowski:-2", manhattan)
+
+	assert.Error(err)
+	assert.Nil(minkowskiOut)
+
 	nilMatrix, err := DistanceMx("euclidean", nil)
 
 	assert.Error(err)
 	assert.Nil(nilMatrix)
 }
 
+func TestRegisterMahalanobis(t *testing.T) {
+	assert := assert.New(t)
+
+	// identity covariance matrix gives euclidean distance
+	cov := mat64.NewDense(2, 2, []float64{
+		1.0, 0.0,
+		0.0, 1.0,
+	})
+	err := RegisterMahalanobis("mahalanobis-eye", cov, nil)
+	assert.NoError(err)
+	d, err := Distance("mahalanobis-eye", []float64{3.0, 1.0}, []float64{1.0, 3.0})
+	assert.NoError(err)
+	assert.InDelta(2.828, d, 0.01)
+	// scaled covariance matrix
+	cov = mat64.NewDense(2, 2, []float64{
+		4.0, 0.0,
+		0.0, 1.0,
+	})
+	err = RegisterMahalanobis("mahalanobis-diag", cov, nil)
+	assert.NoError(err)
+	d, err = Distance("mahalanobis-diag", []float64{0.0, 0.0}, []float64{2.0, 0.0})
+	assert.NoError(err)
+	assert.InDelta(1.0, d, 0.01)
+	// registered metric can be used to compute distance matrix
+	m := mat64.NewDense(2, 2, []float64{
+		0.0, 0.0,
+		2.0, 1.0,
+	})
+	out, err := DistanceMx("mahalanobis-diag", m)
+	assert.NoError(err)
+	assert.InDelta(1.414, out.At(0, 1), 0.01)
+	assert.InDelta(1.414, out.At(1, 0), 0.01)
+	// mismatched dimensions return error
+	d, err = Distance("mahalanobis-diag", []float64{0.0}, []float64{2.0})
+	assert.Error(err)
+	// covariance matrix estimated from data
+	data := mat64.NewDense(4, 2, []float64{
+		1.0, 2.0,
+		2.0, 1.0,
+		3.0, 4.0,
+		4.0, 3.0,
+	})
+	err = RegisterMahalanobis("mahalanobis-data", nil, data)
+	assert.NoError(err)
+	d, err = Distance("mahalanobis-data", data.RawRowView(0), data.RawRowView(0))
+	assert.NoError(err)
+	assert.Equal(0.0, d)
+	d, err = Distance("mahalanobis-data", data.RawRowView(0), data.RawRowView(1))
+	assert.NoError(err)
+	assert.True(d > 0.0)
+	// registered metric can be used in codebook config
+	assert.NoError(validateMetric("mahalanobis-data"))
+	// empty name returns error
+	err = RegisterMahalanobis("", cov, nil)
+	assert.Error(err)
+	// builtin metric name returns error
+	errString := "metric name reserved by builtin metric: %s"
+	err = RegisterMahalanobis("euclidean", cov, nil)
+	assert.EqualError(err, fmt.Sprintf(errString, "euclidean"))
+	err = RegisterMahalanobis("minkowski:3", cov, nil)
+	assert.EqualError(err, fmt.Sprintf(errString, "minkowski:3"))
+	// missing covariance and data returns error
+	err = RegisterMahalanobis("mahalanobis-nil", nil, nil)
+	assert.Error(err)
+	// non-square covariance matrix returns error
+	err = RegisterMahalanobis("mahalanobis-nonsquare", mat64.NewDense(2, 3, nil), nil)
+	assert.Error(err)
+	// singular covariance matrix returns error
+	err = RegisterMahalanobis("mahalanobis-singular", mat64.NewDense(2, 2, nil), nil)
+	assert.Error(err)
+}
+
+func TestRegisterWeightedEuclidean(t *testing.T) {
+	assert := assert.New(t)
+
+	err := RegisterWeightedEuclidean("weighted", []float64{4.0, 0.0})
+	assert.NoError(err)
+	d, err := Distance("weighted", []float64{0.0, 0.0}, []float64{1.0, 5.0})
+	assert.NoError(err)
+	assert.InDelta(2.0, d, 0.01)
+	// suppressed feature does not affect BMU search
+	m := mat64.NewDense(2, 2, []float64{
+		0.0, 10.0,
+		1.0, 0.0,
+	})
+	closest, err := ClosestVec("weighted", []float64{0.0, 0.0}, m)
+	assert.NoError(err)
+	assert.Equal(0, closest)
+	// mismatched dimensions return error
+	_, err = Distance("weighted", []float64{0.0}, []float64{1.0})
+	assert.Error(err)
+	// empty weights return error
+	err = RegisterWeightedEuclidean("weighted-empty", nil)
+	assert.Error(err)
+	// negative weights return error
+	err = RegisterWeightedEuclidean("weighted-neg", []float64{1.0, -1.0})
+	assert.Error(err)
+	// builtin metric name returns error
+	err = RegisterWeightedEuclidean("euclidean", []float64{1.0})
+	assert.Error(err)
+}
+
+func TestRegisterDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	// squared euclidean distance
+	sqEuclidean := func(a, b []float64) (float64, error) {
+		d := 0.0
+		for i := range a {
+			d += (a[i] - b[i]) * (a[i] - b[i])
+		}
+		return d, nil
+	}
+	err := RegisterDistance("sqeuclidean", sqEuclidean)
+	assert.NoError(err)
+	d, err := Distance("sqeuclidean", []float64{3.0, 1.0}, []float64{1.0, 3.0})
+	assert.NoError(err)
+	assert.InDelta(8.0, d, 0.01)
+	// registered metric is used in distance matrix computation
+	m := mat64.NewDense(3, 2, []float64{
+		0.0, 0.0,
+		0.0, 2.0,
+		1.0, 1.0,
+	})
+	out, err := DistanceMx("sqeuclidean", m)
+	assert.NoError(err)
+	assert.InDelta(4.0, out.At(0, 1), 0.01)
+	assert.InDelta(2.0, out.At(2, 0), 0.01)
+	// registered metric is used in BMU search
+	closest, err := ClosestVec("sqeuclidean", []float64{0.9, 0.9}, m)
+	assert.NoError(err)
+	assert.Equal(2, closest)
+	// registered metric can be used in codebook config
+	assert.NoError(validateMetric("sqeuclidean"))
+	// errors returned by custom metric are propagated
+	errFn := func(a, b []float64) (float64, error) {
+		return 0.0, fmt.Errorf("custom error")
+	}
+	err = RegisterDistance("failing", errFn)
+	assert.NoError(err)
+	_, err = Distance("failing", []float64{0.0}, []float64{1.0})
+	assert.EqualError(err, "custom error")
+	out, err = DistanceMx("failing", m)
+	assert.EqualError(err, "custom error")
+	assert.Nil(out)
+	closest, err = ClosestVec("failing", []float64{0.9, 0.9}, m)
+	assert.EqualError(err, "custom error")
+	assert.Equal(-1, closest)
+	// nil distance function returns error
+	err = RegisterDistance("nilfunc", nil)
+	assert.Error(err)
+	// builtin metric name returns error
+	err = RegisterDistance("cosine", sqEuclidean)
+	assert.Error(err)
+	// empty name returns error
+	err = RegisterDistance("", sqEuclidean)
+	assert.Error(err)
+}
+
 func TestClosestVec(t *testing.T) {
 	assert := assert.New(t)
 
@@ -233,3 +662,106 @@ func TestBMUs(t *testing.T) {
 	assert.NotNil(bmus)
 	assert.Equal(rows, len(bmus))
 }
+
+func TestBMU(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2,
+		[]float64{0.0, 0.0,
+			3.0, 4.0,
+			10.0, 10.0})
+	sample := mat64.NewVector(2, []float64{3.0, 5.0})
+	unit, dist, err := BMU(cbook, sample, "euclidean")
+	assert.NoError(err)
+	assert.Equal(1, unit)
+	assert.InDelta(1.0, dist, 1e-9)
+	unit, dist, err = BMU(cbook, sample, "manhattan")
+	assert.NoError(err)
+	assert.Equal(1, unit)
+	assert.InDelta(1.0, dist, 1e-9)
+	// nil parameters
+	unit, _, err = BMU(nil, sample, "euclidean")
+	assert.Equal(-1, unit)
+	assert.Error(err)
+	unit, _, err = BMU(cbook, nil, "euclidean")
+	assert.Equal(-1, unit)
+	assert.Error(err)
+	// mismatched dimensions
+	unit, _, err = BMU(cbook, mat64.NewVector(3, nil), "euclidean")
+	assert.Equal(-1, unit)
+	assert.Error(err)
+}
+
+func TestMapRows(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2,
+		[]float64{0.0, 0.0,
+			3.0, 4.0,
+			10.0, 10.0})
+	rows := 100
+	data := mat64.NewDense(rows, 2, nil)
+	for i := 0; i < rows; i++ {
+		data.SetRow(i, []float64{float64(i % 12), float64(i % 12)})
+	}
+	units, dists, err := MapRows(cbook, data, "euclidean")
+	assert.NoError(err)
+	assert.Len(units, rows)
+	assert.Len(dists, rows)
+	for i := 0; i < rows; i++ {
+		unit, dist, err := BMU(cbook, data.RowView(i), "euclidean")
+		assert.NoError(err)
+		assert.Equal(unit, units[i])
+		assert.Equal(dist, dists[i])
+	}
+	// nil parameters
+	units, dists, err = MapRows(nil, data, "euclidean")
+	assert.Nil(units)
+	assert.Nil(dists)
+	assert.Error(err)
+	units, dists, err = MapRows(cbook, nil, "euclidean")
+	assert.Nil(units)
+	assert.Nil(dists)
+	assert.Error(err)
+	// mismatched dimensions
+	units, dists, err = MapRows(cbook, mat64.NewDense(5, 3, nil), "euclidean")
+	assert.Nil(units)
+	assert.Nil(dists)
+	assert.Error(err)
+}
+
+func TestKBMU(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(4, 2,
+		[]float64{0.0, 0.0,
+			3.0, 4.0,
+			10.0, 10.0,
+			3.0, 3.0})
+	sample := mat64.NewVector(2, []float64{3.0, 5.0})
+	units, dists, err := KBMU(cbook, sample, 3, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]int{1, 3, 0}, units)
+	assert.InDeltaSlice([]float64{1.0, 2.0, math.Sqrt(34.0)}, dists, 1e-9)
+	// single unit is the BMU
+	units, dists, err = KBMU(cbook, sample, 1, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]int{1}, units)
+	assert.InDeltaSlice([]float64{1.0}, dists, 1e-9)
+	// invalid k
+	for _, k := range []int{0, 5} {
+		units, dists, err = KBMU(cbook, sample, k, "euclidean")
+		assert.Nil(units)
+		assert.Nil(dists)
+		assert.EqualError(err, fmt.Sprintf("invalid number of closest vectors requested: %d", k))
+	}
+	// nil parameters
+	units, dists, err = KBMU(nil, sample, 1, "euclidean")
+	assert.Nil(units)
+	assert.Nil(dists)
+	assert.Error(err)
+	units, dists, err = KBMU(cbook, nil, 1, "euclidean")
+	assert.Nil(units)
+	assert.Nil(dists)
+	assert.Error(err)
+}
@@ -0,0 +1,43 @@
+package som
+
+import (
+	"context"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// TrainEarlyStop runs SOM training for at most iters iterations and monitors map quantization error
+// at the end of every training epoch. An epoch is every iteration of batch training and every as many
+// iterations of sequential training as there are data rows. The training stops once the relative
+// improvement of quantization error falls below es.MinImprovement for es.Patience consecutive epochs.
+// Radius and learning rate decay as if the training ran for all iters iterations.
+// It returns the number of completed epochs and quantization error of every epoch or fails with error
+// if the supplied configuration is invalid or if the training fails.
+func (m *Map) TrainEarlyStop(c *TrainConfig, es *EarlyStopConfig, data *mat64.Dense, iters int) (int, []float64, error) {
+	if err := validateEarlyStopConfig(es); err != nil {
+		return 0, nil, err
+	}
+	qErrs := []float64{}
+	stale := 0
+	epochEnd := func(epoch, iteration int) (bool, error) {
+		qErr, err := m.QuantError(data)
+		if err != nil {
+			return true, err
+		}
+		if n := len(qErrs); n > 0 {
+			prev := qErrs[n-1]
+			if prev == 0 || (prev-qErr)/prev < es.MinImprovement {
+				stale++
+			} else {
+				stale = 0
+			}
+		}
+		qErrs = append(qErrs, qErr)
+		return stale >= es.Patience, nil
+	}
+	if err := m.train(context.Background(), c, data, iters, epochEnd); err != nil {
+		return len(qErrs), qErrs, err
+	}
+
+	return len(qErrs), qErrs, nil
+}
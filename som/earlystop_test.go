@@ -0,0 +1,55 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrainEarlyStop(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	data := mat64.NewDense(2, 1, []float64{0.0, 10.0})
+	tc := &TrainConfig{
+		Algorithm: "batch",
+		Radius:    1.0,
+		RDecay:    "lin",
+		NeighbFn:  Bubble,
+		LRate:     0.5,
+		LDecay:    "lin",
+	}
+	es := &EarlyStopConfig{MinImprovement: 0.01, Patience: 2}
+	// batch training converges after the first epoch
+	m := &Map{
+		codebook: mat64.NewDense(2, 1, []float64{1.0, 9.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	epochs, qErrs, err := m.TrainEarlyStop(tc, es, data, 100)
+	assert.NoError(err)
+	assert.Equal(3, epochs)
+	assert.Len(qErrs, 3)
+	// sequential epoch is as long as the data set
+	tc.Algorithm = "seq"
+	es.Patience = 1000
+	epochs, qErrs, err = m.TrainEarlyStop(tc, es, data, 9)
+	assert.NoError(err)
+	assert.Equal(4, epochs)
+	assert.Len(qErrs, 4)
+	// invalid config
+	es.Patience = 0
+	epochs, qErrs, err = m.TrainEarlyStop(tc, es, data, 100)
+	assert.Equal(0, epochs)
+	assert.Nil(qErrs)
+	assert.EqualError(err, "invalid patience: 0")
+	es.Patience, es.MinImprovement = 1, -1.0
+	_, _, err = m.TrainEarlyStop(tc, es, data, 100)
+	assert.EqualError(err, "invalid minimum improvement: -1.000000")
+	// invalid training parameters
+	es.MinImprovement = 0.0
+	_, _, err = m.TrainEarlyStop(tc, es, data, 0)
+	assert.Error(err)
+}
@@ -0,0 +1,159 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ExpandedUMatrix computes the classic expanded U-Matrix of the given codebook whose units are laid out
+// on a planar 2D grid of the given dimensions and unit shape. The expanded U-Matrix has (2*dims[0]-1) rows
+// and (2*dims[1]-1) columns: the unit cells are placed at even positions and the cells between them hold
+// the distances between the neighbouring units computed using the supplied metric. Cells between diagonal
+// neighbours of rectangular grids hold the average of the two diagonal distances. Unit cells hold the median
+// of the surrounding cells. It fails with error if the grid is not a 2D grid of rectangles or hexagons
+// or if the codebook does not match the grid.
+func ExpandedUMatrix(codebook *mat64.Dense, dims []int, uShape, metric string) (*mat64.Dense, error) {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return nil, err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return expandedUMatrix(codebook, grid, metric)
+}
+
+// expandedUMatrix computes the expanded U-Matrix of the given codebook and grid
+func expandedUMatrix(codebook *mat64.Dense, grid *Grid, metric string) (*mat64.Dense, error) {
+	if grid.gtype != "planar" || len(grid.size) != 2 || grid.ushape == "triangle" {
+		return nil, fmt.Errorf("unsupported expanded U-Matrix grid: %s %s %v", grid.gtype, grid.ushape, grid.size)
+	}
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if rows, _ := codebook.Dims(); rows != grid.Units() {
+		return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", rows, grid.Units())
+	}
+	distMat, err := DistanceMx(metric, codebook)
+	if err != nil {
+		return nil, err
+	}
+	coordsDistMat, err := grid.UnitDist()
+	if err != nil {
+		return nil, err
+	}
+	// grid units are stored column by column
+	dims := grid.size
+	pos := func(unit int) (int, int) { return 2 * (unit % dims[0]), 2 * (unit / dims[0]) }
+	eRows, eCols := 2*dims[0]-1, 2*dims[1]-1
+	sum := mat64.NewDense(eRows, eCols, nil)
+	count := mat64.NewDense(eRows, eCols, nil)
+	units := grid.Units()
+	for i := 0; i < units; i++ {
+		for _, rwd := range allRowsInRadius(i, math.Sqrt2*1.01, coordsDistMat) {
+			if rwd.Row <= i {
+				continue
+			}
+			// the distance cell lies between the two units
+			r1, c1 := pos(i)
+			r2, c2 := pos(rwd.Row)
+			r, c := (r1+r2)/2, (c1+c2)/2
+			sum.Set(r, c, sum.At(r, c)+distMat.At(i, rwd.Row))
+			count.Set(r, c, count.At(r, c)+1)
+		}
+	}
+	umatrix := mat64.NewDense(eRows, eCols, nil)
+	for r := 0; r < eRows; r++ {
+		for c := 0; c < eCols; c++ {
+			if n := count.At(r, c); n > 0 {
+				umatrix.Set(r, c, sum.At(r, c)/n)
+			}
+		}
+	}
+	// unit cells hold the median of the surrounding distance cells
+	for unit := 0; unit < units; unit++ {
+		r, c := pos(unit)
+		vals := []float64{}
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				nr, nc := r+dr, c+dc
+				if (dr == 0 && dc == 0) || nr < 0 || nr >= eRows || nc < 0 || nc >= eCols {
+					continue
+				}
+				if count.At(nr, nc) > 0 {
+					vals = append(vals, umatrix.At(nr, nc))
+				}
+			}
+		}
+		umatrix.Set(r, c, median(vals))
+	}
+
+	return umatrix, nil
+}
+
+// median returns the median of values or 0 if there are no values
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// ExpandedUMatrix generates SOM expanded u-matrix in a given format and writes the output to w.
+// The expanded u-matrix cells are rendered as a grid of rectangles. Supported formats are svg and png.
+// It fails with error if the map grid is not a planar 2D grid of rectangles or hexagons or if the write to w fails.
+func (m Map) ExpandedUMatrix(w io.Writer, format, title string) error {
+	umatrix, err := expandedUMatrix(m.codebook, m.grid, m.metric)
+	if err != nil {
+		return err
+	}
+	grid, values, err := matrixGrid(umatrix)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "svg":
+		return valuesSVG(grid, values, mat64.Min(umatrix), mat64.Max(umatrix), title, w, m.render.style())
+	case "png":
+		return valuesPNG(grid, values, mat64.Min(umatrix), mat64.Max(umatrix), w, m.render.style())
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// matrixGrid returns planar grid of rectangles whose units are the cells of the matrix mx
+// along with the values of the cells ordered in the same way as the grid units.
+func matrixGrid(mx *mat64.Dense) (*Grid, []float64, error) {
+	rows, cols := mx.Dims()
+	dims := []int{rows, cols}
+	coords, err := GridCoords("rectangle", dims)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]float64, 0, rows*cols)
+	for c := 0; c < cols; c++ {
+		values = append(values, mat64.Col(nil, c, mx)...)
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: "rectangle",
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return grid, values, nil
+}
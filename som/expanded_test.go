@@ -0,0 +1,68 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandedUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	// units are stored column by column
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 1.0, 3.0, 4.0})
+	umatrix, err := ExpandedUMatrix(cbook, []int{2, 2}, "rectangle", "euclidean")
+	assert.NoError(err)
+	expected := mat64.NewDense(3, 3, []float64{
+		3.0, 3.0, 3.0,
+		1.0, 3.0, 1.0,
+		3.0, 3.0, 3.0,
+	})
+	assert.True(mat64.Equal(expected, umatrix))
+	// odd hexagon rows are shifted to the right
+	cbook = mat64.NewDense(4, 1, []float64{0.0, 1.0, 3.0, 7.0})
+	umatrix, err = ExpandedUMatrix(cbook, []int{2, 2}, "hexagon", "euclidean")
+	assert.NoError(err)
+	rows, cols := umatrix.Dims()
+	assert.Equal(3, rows)
+	assert.Equal(3, cols)
+	assert.Equal(3.0, umatrix.At(0, 1))
+	assert.Equal(1.0, umatrix.At(1, 0))
+	assert.Equal(2.0, umatrix.At(1, 1))
+	assert.Equal(6.0, umatrix.At(2, 1))
+	assert.Equal(4.0, umatrix.At(1, 2))
+	assert.Equal(2.0, umatrix.At(0, 0))
+	// unsupported grids
+	_, err = ExpandedUMatrix(mat64.NewDense(4, 1, nil), []int{2, 2}, "triangle", "euclidean")
+	assert.Error(err)
+	_, err = ExpandedUMatrix(mat64.NewDense(3, 1, nil), []int{3}, "rectangle", "euclidean")
+	assert.Error(err)
+	_, err = ExpandedUMatrix(mat64.NewDense(3, 1, nil), []int{2, 2}, "rectangle", "euclidean")
+	assert.Error(err)
+}
+
+func TestMapExpandedUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(4, 1, []float64{0.0, 1.0, 3.0, 4.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.ExpandedUMatrix(&buf, "svg", "Expanded"))
+	assert.Equal(9, strings.Count(buf.String(), "<polygon "))
+	buf.Reset()
+	assert.NoError(m.ExpandedUMatrix(&buf, "png", "Expanded"))
+	assert.Error(m.ExpandedUMatrix(&buf, "bmp", "Expanded"))
+	m.grid, err = NewGrid(&GridConfig{Size: []int{2, 2}, Type: "toroid", UShape: "rectangle"})
+	assert.NoError(err)
+	assert.Error(m.ExpandedUMatrix(&buf, "svg", "Expanded"))
+	assert.Equal(2.5, median([]float64{4.0, 1.0, 2.0, 3.0}))
+	assert.Equal(0.0, median(nil))
+}
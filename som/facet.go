@@ -0,0 +1,109 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// FacetSVG creates a single SVG document which contains the U-Matrix and the component planes of the given codebook
+// selected by c laid out in a grid of panels, and writes it to writer. All panels are rendered using the same unit size.
+// It accepts the same parameters as UMatrixSVG and c - the panel configuration.
+// It fails with error if c is invalid, if any of the selected features is out of range, if the grid coordinates
+// could not be computed or if the codebook does not match the grid.
+func FacetSVG(codebook *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, c *FacetConfig) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return facetSVG(codebook, grid, metric, title, writer, unitStyle{}, c)
+}
+
+// Facets renders the U-Matrix and the component planes of the map selected by c into a single document laid out
+// in a grid of panels and writes the output to w. Currently only "svg" format is supported.
+// It fails in the same way as FacetSVG.
+func (m Map) Facets(w io.Writer, c *FacetConfig, format, title string) error {
+	switch format {
+	case "svg":
+		return facetSVG(m.codebook, m.grid, m.metric, title, w, m.render.style(), c)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// facetSVG creates an SVG document which contains panels of the given codebook and grid selected by c
+func facetSVG(codebook *mat64.Dense, grid *Grid, metric, title string, writer io.Writer, s unitStyle, c *FacetConfig) error {
+	if c == nil {
+		return fmt.Errorf("invalid facet config: %v", c)
+	}
+	if err := validateFacetConfig(c); err != nil {
+		return err
+	}
+	if len(grid.size) == 3 {
+		return fmt.Errorf("unsupported facet grid: %s %v", grid.gtype, grid.size)
+	}
+	// panel titles and values
+	var titles []string
+	var values [][]float64
+	if c.UMatrix {
+		umatrix, _, _, err := s.umatrixValues(codebook, grid, metric)
+		if err != nil {
+			return err
+		}
+		titles, values = append(titles, "U-Matrix"), append(values, umatrix)
+	}
+	for _, feature := range c.Features {
+		component, err := componentValues(codebook, grid, feature)
+		if err != nil {
+			return err
+		}
+		titles, values = append(titles, fmt.Sprintf("Component %d", feature)), append(values, component)
+	}
+	minValue, maxValue := floats.Min(values[0]), floats.Max(values[0])
+	for _, v := range values[1:] {
+		minValue, maxValue = math.Min(minValue, floats.Min(v)), math.Max(maxValue, floats.Max(v))
+	}
+
+	st := newSVGStream(writer)
+	st.css = s.css
+	desc := fmt.Sprintf("%s %s grid %v, %d panels", grid.gtype, grid.ushape, grid.size, len(values))
+	cols := c.Cols
+	if cols == 0 {
+		cols = len(values)
+	}
+	var rows [][]svgFrame
+	draws := make([]func(int, svgFrame), len(values))
+	for i := range values {
+		lo, hi := minValue, maxValue
+		if !c.SharedScale {
+			lo, hi = floats.Min(values[i]), floats.Max(values[i])
+		}
+		panels, draw := valuesPanels(st, grid, values[i], lo, hi, titles[i], s)
+		panels[0].title, draws[i] = titles[i], draw
+		if i%cols == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], panels[0])
+	}
+	// shared scale legend is placed below the panels
+	if s.legend && c.SharedScale {
+		legend := legendSVG(minValue, maxValue, s.color)
+		rows = append(rows, []svgFrame{{width: legend.Width, height: legend.Height}})
+		draws = append(draws, func(i int, frame svgFrame) { st.element(frame, legend, unitStyle{}) })
+	}
+	st.document(title, desc, rows, func(i int, frame svgFrame) {
+		draws[i](0, frame)
+	})
+
+	return st.flush()
+}
@@ -0,0 +1,74 @@
+package som
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFacetSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2, []float64{0.0, 10.0, 1.0, 20.0, 3.0, 30.0})
+	var buf bytes.Buffer
+	c := &FacetConfig{UMatrix: true, Features: []int{0, 1}, Cols: 2}
+	assert.NoError(FacetSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Report", &buf, c))
+	svg := buf.String()
+	assert.NoError(xml.Unmarshal(buf.Bytes(), new(interface{})))
+	// three panels laid out in two rows of the same size
+	assert.Equal(4, strings.Count(svg, "<svg "))
+	assert.Equal(9, strings.Count(svg, "<polygon "))
+	assert.True(strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="340" height="140" viewBox="0 0 340 140"><title>Report</title>`))
+	assert.True(strings.Contains(svg, `<svg x="0" y="0" width="170" height="70"><title>U-Matrix</title>`))
+	assert.True(strings.Contains(svg, `<svg x="170" y="0" width="170" height="70"><title>Component 0</title>`))
+	assert.True(strings.Contains(svg, `<svg x="0" y="70" width="170" height="70"><title>Component 1</title>`))
+	// every panel has its own scale
+	assert.Equal(3, strings.Count(svg, "fill:rgb(0,0,0);"))
+	// shared scale makes the second component white and the others dark
+	buf.Reset()
+	c = &FacetConfig{Features: []int{0, 1}, SharedScale: true}
+	assert.NoError(FacetSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Report", &buf, c))
+	svg = buf.String()
+	assert.True(strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="340" height="70" viewBox="0 0 340 70">`))
+	assert.Equal(1, strings.Count(svg, "fill:rgb(255,255,255);"))
+	assert.Equal(1, strings.Count(svg, "fill:rgb(0,0,0);"))
+	// invalid parameters
+	assert.Error(FacetSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Report", &buf, nil))
+	assert.Error(FacetSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Report", &buf, &FacetConfig{}))
+	assert.Error(FacetSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Report", &buf, &FacetConfig{Features: []int{2}}))
+	assert.Error(FacetSVG(cbook, []int{2, 2}, "rectangle", "euclidean", "Report", &buf, &FacetConfig{UMatrix: true}))
+	assert.Error(FacetSVG(cbook, []int{1, 3}, "foobar", "euclidean", "Report", &buf, &FacetConfig{UMatrix: true}))
+}
+
+func TestMapFacets(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(3, 2, []float64{0.0, 10.0, 1.0, 20.0, 3.0, 30.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	c := &FacetConfig{UMatrix: true, Features: []int{1}}
+	var buf, expected bytes.Buffer
+	assert.NoError(m.Facets(&buf, c, "svg", "Report"))
+	assert.NoError(FacetSVG(m.codebook, []int{1, 3}, "rectangle", "euclidean", "Report", &expected, c))
+	assert.Equal(expected.String(), buf.String())
+	// shared scale legend is placed below the panels
+	m.render = &RenderConfig{Legend: true}
+	buf.Reset()
+	c.SharedScale = true
+	assert.NoError(m.Facets(&buf, c, "svg", "Report"))
+	assert.True(strings.Contains(buf.String(), `<svg x="0" y="70" width="220" height="60">`))
+	assert.Error(m.Facets(&buf, c, "png", "Report"))
+	// 3D grids are not supported
+	grid, err = NewGrid(&GridConfig{Size: []int{1, 3, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m.grid, m.codebook = grid, mat64.NewDense(6, 2, nil)
+	assert.Error(m.Facets(&buf, c, "svg", "Report"))
+}
@@ -0,0 +1,262 @@
+package som
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// GHSOM is a Growing Hierarchical Self Organizing Map
+type GHSOM struct {
+	// root is the top level node of the hierarchy
+	root *GHSOMNode
+}
+
+// GHSOMNode is a node of GHSOM hierarchy which holds a single SOM
+type GHSOMNode struct {
+	// m is SOM of the node
+	m *Map
+	// data holds data samples the node map has been trained on
+	data *mat64.Dense
+	// depth is the level of the node in the hierarchy
+	depth int
+	// unit is the index of parent map unit that spawned the node
+	unit int
+	// parent is the parent node
+	parent *GHSOMNode
+	// children maps the node map units to their child nodes
+	children map[int]*GHSOMNode
+}
+
+// NewGHSOM creates and trains new Growing Hierarchical SOM based on the provided configuration.
+// Every map of the hierarchy is trained on the data samples mapped to its parent unit and optionally
+// grows until its quantization error drops below Tau1 fraction of its parent unit error.
+// Every unit whose mean quantization error exceeds Tau2 fraction of the mean quantization error
+// of the whole data set then spawns a child map, until the maximum depth is reached.
+// NewGHSOM returns error if the provided configuration is not valid, the data is nil
+// or if any of the maps could not be created or trained.
+func NewGHSOM(c *GHSOMConfig, data *mat64.Dense) (*GHSOM, error) {
+	// if input data is empty throw error
+	if data == nil {
+		return nil, fmt.Errorf("invalid input data: %v", data)
+	}
+	if err := validateGHSOMConfig(c); err != nil {
+		return nil, err
+	}
+	// mean quantization error of the data set represented by its mean vector
+	rows, cols := data.Dims()
+	mean := make([]float64, cols)
+	for i := 0; i < cols; i++ {
+		mean[i] = mat64.Sum(data.ColView(i)) / float64(rows)
+	}
+	metric := c.Map.Cb.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	qErr0, err := meanQuantError(metric, data, mean)
+	if err != nil {
+		return nil, err
+	}
+	root, err := newGHSOMNode(c, data, qErr0, qErr0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GHSOM{root: root}, nil
+}
+
+// newGHSOMNode creates and trains the node map at the given depth and recursively spawns its child nodes.
+// parentErr is the quantization error of the parent unit and qErr0 is the quantization error of the data set.
+func newGHSOMNode(c *GHSOMConfig, data *mat64.Dense, parentErr, qErr0 float64, depth int) (*GHSOMNode, error) {
+	m, err := NewMap(c.Map, data)
+	if err != nil {
+		return nil, err
+	}
+	if c.Tau1 > 0 {
+		gc := &GrowConfig{
+			QError:   c.Tau1 * parentErr,
+			MaxUnits: c.MaxUnits,
+			Iters:    c.Iters,
+		}
+		err = m.Grow(c.Train, gc, data)
+	} else {
+		err = m.Train(c.Train, data, c.Iters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	node := &GHSOMNode{
+		m:        m,
+		data:     data,
+		depth:    depth,
+		unit:     -1,
+		children: make(map[int]*GHSOMNode),
+	}
+	// the maximum depth has been reached
+	if depth+1 >= c.MaxDepth {
+		return node, nil
+	}
+	bmus, err := m.BMUs(data)
+	if err != nil {
+		return nil, err
+	}
+	// data samples mapped to each unit
+	unitRows := make(map[int][]int)
+	for row, bmu := range bmus {
+		unitRows[bmu] = append(unitRows[bmu], row)
+	}
+	for unit, rows := range unitRows {
+		if len(rows) < c.MinSamples {
+			continue
+		}
+		unitData := dataRows(data, rows)
+		unitErr, err := meanQuantError(m.metric, unitData, m.codebook.RawRowView(unit))
+		if err != nil {
+			return nil, err
+		}
+		if unitErr <= c.Tau2*qErr0 {
+			continue
+		}
+		child, err := newGHSOMNode(c, unitData, unitErr, qErr0, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		child.unit = unit
+		child.parent = node
+		node.children[unit] = child
+	}
+
+	return node, nil
+}
+
+// Root returns the top level node of the hierarchy
+func (g GHSOM) Root() *GHSOMNode {
+	return g.root
+}
+
+// Depth returns the number of levels of the hierarchy
+func (g GHSOM) Depth() int {
+	depth := 0
+	g.Walk(func(n *GHSOMNode) error {
+		if n.depth+1 > depth {
+			depth = n.depth + 1
+		}
+		return nil
+	})
+	return depth
+}
+
+// Level returns all nodes at the given level of the hierarchy. The root node is at level 0.
+func (g GHSOM) Level(depth int) []*GHSOMNode {
+	nodes := []*GHSOMNode{}
+	g.Walk(func(n *GHSOMNode) error {
+		if n.depth == depth {
+			nodes = append(nodes, n)
+		}
+		return nil
+	})
+	return nodes
+}
+
+// Walk traverses the hierarchy depth first starting at the root node and calls fn for every node.
+// Child nodes are visited in the order of their parent units. Walk stops and returns the error
+// returned by fn if it fails.
+func (g GHSOM) Walk(fn func(*GHSOMNode) error) error {
+	return g.root.walk(fn)
+}
+
+// UMatrix generates u-matrices of all maps at the given level of the hierarchy in a given format
+// and writes the output to w. Every map is titled by its path from the root node.
+// It fails with error if the level does not exist or if the rendering of any map fails.
+func (g GHSOM) UMatrix(w io.Writer, depth int, format, title string) error {
+	nodes := g.Level(depth)
+	if len(nodes) == 0 {
+		return fmt.Errorf("invalid GHSOM level: %d", depth)
+	}
+	for _, n := range nodes {
+		if err := n.m.UMatrix(w, n.data, nil, format, fmt.Sprintf("%s %v", title, n.Path())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Map returns the node SOM
+func (n GHSOMNode) Map() *Map {
+	return n.m
+}
+
+// Data returns data samples the node map has been trained on
+func (n GHSOMNode) Data() *mat64.Dense {
+	return n.data
+}
+
+// Depth returns the level of the node in the hierarchy
+func (n GHSOMNode) Depth() int {
+	return n.depth
+}
+
+// Unit returns the index of the parent map unit that spawned the node or -1 for the root node
+func (n GHSOMNode) Unit() int {
+	return n.unit
+}
+
+// Parent returns the parent node or nil for the root node
+func (n GHSOMNode) Parent() *GHSOMNode {
+	return n.parent
+}
+
+// Children returns a map of the node map units to their child nodes
+func (n GHSOMNode) Children() map[int]*GHSOMNode {
+	return n.children
+}
+
+// Path returns indices of the units that lead from the root node to the node
+func (n *GHSOMNode) Path() []int {
+	path := []int{}
+	for node := n; node.parent != nil; node = node.parent {
+		path = append([]int{node.unit}, path...)
+	}
+	return path
+}
+
+// walk calls fn for the node and all of its descendants
+func (n *GHSOMNode) walk(fn func(*GHSOMNode) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	units := n.m.grid.Units()
+	for unit := 0; unit < units; unit++ {
+		if child, ok := n.children[unit]; ok {
+			if err := child.walk(fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// meanQuantError computes mean distance between data rows and vector vec
+func meanQuantError(metric string, data *mat64.Dense, vec []float64) (float64, error) {
+	rows, _ := data.Dims()
+	qErr := 0.0
+	for i := 0; i < rows; i++ {
+		d, err := Distance(metric, data.RawRowView(i), vec)
+		if err != nil {
+			return 0.0, err
+		}
+		qErr += d
+	}
+	return qErr / float64(rows), nil
+}
+
+// dataRows returns a new matrix which contains the given data rows
+func dataRows(data *mat64.Dense, rows []int) *mat64.Dense {
+	_, cols := data.Dims()
+	sub := mat64.NewDense(len(rows), cols, nil)
+	for i, row := range rows {
+		sub.SetRow(i, data.RawRowView(row))
+	}
+	return sub
+}
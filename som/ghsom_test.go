@@ -0,0 +1,122 @@
+package som
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeGHSOMData() *mat64.Dense {
+	// three well separated clusters
+	data := mat64.NewDense(30, 2, nil)
+	centers := [][]float64{{0.0, 0.0}, {10.0, 0.0}, {0.0, 10.0}}
+	for i := 0; i < 30; i++ {
+		c := centers[i%3]
+		data.SetRow(i, []float64{c[0] + float64(i%5)*0.3, c[1] + float64(i%7)*0.2})
+	}
+	return data
+}
+
+func makeDefaultGHSOMConfig() *GHSOMConfig {
+	return &GHSOMConfig{
+		Map: &MapConfig{
+			Grid: &GridConfig{
+				Size:   []int{2, 2},
+				Type:   "planar",
+				UShape: "rectangle",
+			},
+			Cb: &CbConfig{
+				Dim:      2,
+				InitFunc: RandInit,
+			},
+		},
+		Train:      makeDefaultTrainConfig(),
+		Iters:      100,
+		Tau2:       0.01,
+		MaxDepth:   2,
+		MinSamples: 2,
+	}
+}
+
+func TestNewGHSOM(t *testing.T) {
+	assert := assert.New(t)
+
+	data := makeGHSOMData()
+	c := makeDefaultGHSOMConfig()
+	g, err := NewGHSOM(c, data)
+	assert.NotNil(g)
+	assert.NoError(err)
+	// high error units spawn child maps
+	root := g.Root()
+	assert.Equal(0, root.Depth())
+	assert.Equal(-1, root.Unit())
+	assert.Nil(root.Parent())
+	assert.EqualValues([]int{}, root.Path())
+	assert.NotEmpty(root.Children())
+	assert.Equal(2, g.Depth())
+	// every child is trained on the data samples mapped to its parent unit
+	bmus, err := root.Map().BMUs(data)
+	assert.NoError(err)
+	for unit, child := range root.Children() {
+		assert.Equal(1, child.Depth())
+		assert.Equal(unit, child.Unit())
+		assert.Equal(root, child.Parent())
+		assert.EqualValues([]int{unit}, child.Path())
+		assert.Empty(child.Children())
+		count := 0
+		for _, bmu := range bmus {
+			if bmu == unit {
+				count++
+			}
+		}
+		rows, _ := child.Data().Dims()
+		assert.Equal(count, rows)
+	}
+	assert.Len(g.Level(0), 1)
+	assert.Len(g.Level(1), len(root.Children()))
+	assert.Empty(g.Level(2))
+	// walk visits all nodes
+	nodes := 0
+	err = g.Walk(func(n *GHSOMNode) error {
+		nodes++
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(1+len(root.Children()), nodes)
+	// walk stops on error
+	err = g.Walk(func(n *GHSOMNode) error {
+		return fmt.Errorf("stop")
+	})
+	assert.EqualError(err, "stop")
+	// every level can be rendered
+	var buf bytes.Buffer
+	err = g.UMatrix(&buf, 1, "svg", "GHSOM")
+	assert.NoError(err)
+	assert.Equal(len(root.Children()), strings.Count(buf.String(), "<svg "))
+	err = g.UMatrix(&buf, 5, "svg", "GHSOM")
+	assert.Error(err)
+	// maximum depth limits the hierarchy
+	c.MaxDepth = 1
+	g, err = NewGHSOM(c, data)
+	assert.NoError(err)
+	assert.Equal(1, g.Depth())
+	// growing maps
+	c.Tau1 = 0.5
+	c.MaxUnits = 9
+	g, err = NewGHSOM(c, data)
+	assert.NoError(err)
+	assert.True(g.Root().Map().Grid().Units() >= 4)
+	// nil data
+	g, err = NewGHSOM(c, nil)
+	assert.Nil(g)
+	assert.Error(err)
+	// invalid config
+	c.MaxDepth = 0
+	g, err = NewGHSOM(c, data)
+	assert.Nil(g)
+	assert.Error(err)
+}
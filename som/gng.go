@@ -0,0 +1,319 @@
+package som
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// gngEdge is an edge between two GNG units; a is always smaller than b
+type gngEdge struct {
+	a, b int
+}
+
+// newGNGEdge returns an edge between units i and j
+func newGNGEdge(i, j int) gngEdge {
+	if i > j {
+		i, j = j, i
+	}
+	return gngEdge{a: i, b: j}
+}
+
+// GNG is a Growing Neural Gas
+type GNG struct {
+	// units holds GNG unit vectors
+	units [][]float64
+	// errs holds accumulated errors of GNG units
+	errs []float64
+	// edges maps edges between GNG units to their ages
+	edges map[gngEdge]int
+	// signals counts input signals GNG has been trained on
+	signals int
+	// config is GNG configuration
+	config GNGConfig
+	// metric is a distance metric used to compare unit and data vectors
+	metric string
+	// rand is a random number generator used to pick training samples
+	rand *rand.Rand
+}
+
+// NewGNG creates new Growing Neural Gas based on the provided configuration.
+// GNG starts with two units initialized to randomly chosen complete data samples.
+// NewGNG returns error if the provided configuration is not valid or if the data matrix is nil
+// or contains less than two complete samples.
+func NewGNG(c *GNGConfig, data *mat64.Dense) (*GNG, error) {
+	// if input data is empty throw error
+	if data == nil {
+		return nil, fmt.Errorf("invalid input data: %v", data)
+	}
+	if err := validateGNGConfig(c); err != nil {
+		return nil, err
+	}
+	rows, _ := data.Dims()
+	// only complete data rows can be picked
+	complete := []int{}
+	for i := 0; i < rows; i++ {
+		if !floats.HasNaN(data.RawRowView(i)) {
+			complete = append(complete, i)
+		}
+	}
+	if len(complete) < 2 {
+		return nil, fmt.Errorf("insufficient number of samples: %d", len(complete))
+	}
+	// use euclidean metric if none was specified
+	metric := c.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	r := rand.New(randSource(c.Source))
+	perm := r.Perm(len(complete))
+	units := make([][]float64, 2)
+	for i := range units {
+		units[i] = make([]float64, len(data.RawRowView(complete[perm[i]])))
+		copy(units[i], data.RawRowView(complete[perm[i]]))
+	}
+
+	return &GNG{
+		units:  units,
+		errs:   make([]float64, 2),
+		edges:  map[gngEdge]int{newGNGEdge(0, 1): 0},
+		config: *c,
+		metric: metric,
+		rand:   r,
+	}, nil
+}
+
+// Codebook returns a matrix which contains GNG unit vectors stored row by row
+func (g GNG) Codebook() *mat64.Dense {
+	_, cols := g.dims()
+	codebook := mat64.NewDense(len(g.units), cols, nil)
+	for i, unit := range g.units {
+		codebook.SetRow(i, unit)
+	}
+	return codebook
+}
+
+// Edges returns an adjacency matrix of GNG units.
+// If GNG units are all connected, it can be used as a graph of graph grid.
+func (g GNG) Edges() *mat64.Dense {
+	adj := mat64.NewDense(len(g.units), len(g.units), nil)
+	for e := range g.edges {
+		adj.Set(e.a, e.b, 1.0)
+		adj.Set(e.b, e.a, 1.0)
+	}
+	return adj
+}
+
+// Metric returns GNG distance metric
+func (g GNG) Metric() string {
+	return g.metric
+}
+
+// BMUs returns a slice which contains indices of the closest GNG units for each vector stored in data rows.
+// It returns error if the data is nil or if the data dimensions do not match the GNG unit dimensions.
+func (g GNG) BMUs(data *mat64.Dense) ([]int, error) {
+	return bmus(g.metric, data, g.Codebook())
+}
+
+// QuantError computes GNG quantization error for the supplied data set using GNG distance metric
+// It returns the quantization error or fails with error if the passed in data is nil
+func (g GNG) QuantError(data *mat64.Dense) (float64, error) {
+	return QuantizationError(g.Codebook(), data, g.metric)
+}
+
+// MarshalTo serializes GNG unit vectors in a given format to writer w.
+// At the moment only the native gonum binary format is supported.
+// It returns the number of bytes written to w or fails with error.
+func (g *GNG) MarshalTo(format string, w io.Writer) (int, error) {
+	switch format {
+	case "gonum":
+		return g.Codebook().MarshalBinaryTo(w)
+	}
+	return 0, fmt.Errorf("unsupported format: %s", format)
+}
+
+// Train runs GNG training for a given data set. Each iteration presents one randomly chosen data
+// sample to GNG: the closest unit and its topological neighbours are moved towards the sample,
+// edges which exceed maximum age are removed along with units left without any edges, and every
+// Lambda samples a new unit is inserted next to the unit with the highest accumulated error.
+// It returns error if the number of iterations is not positive, the data is nil or if the
+// distances could not be computed.
+func (g *GNG) Train(data *mat64.Dense, iters int) error {
+	return g.TrainContext(context.Background(), data, iters)
+}
+
+// TrainContext runs GNG training in the same way as Train, but aborts the training when ctx is
+// cancelled or its deadline expires. GNG then holds the units and edges trained so far.
+// It returns ctx.Err() if the training has been aborted or fails in the same way as Train.
+func (g *GNG) TrainContext(ctx context.Context, data *mat64.Dense, iters int) error {
+	// number of iterations must be a positive integer
+	if iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", iters)
+	}
+	// nil data passed in
+	if data == nil {
+		return fmt.Errorf("invalid data supplied: %v", data)
+	}
+	rows, cols := data.Dims()
+	if _, unitCols := g.dims(); cols != unitCols {
+		return fmt.Errorf("invalid data dimensions: %d", cols)
+	}
+	for i := 0; i < iters; i++ {
+		// stop if the training has been cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := g.adapt(data.RawRowView(g.rand.Intn(rows))); err != nil {
+			return err
+		}
+		g.signals++
+		if g.signals%g.config.Lambda == 0 && len(g.units) < g.config.MaxUnits {
+			g.insert()
+		}
+		// decrease all errors
+		for j := range g.errs {
+			g.errs[j] *= 1.0 - g.config.Decay
+		}
+	}
+
+	return nil
+}
+
+// adapt adapts GNG units and edges to input signal vec
+func (g *GNG) adapt(vec []float64) error {
+	// find the two closest units
+	s1, s2, d1, err := g.closestTwo(vec)
+	if err != nil {
+		return err
+	}
+	g.errs[s1] += d1 * d1
+	move(g.units[s1], vec, g.config.WinnerRate)
+	for e := range g.edges {
+		if e.a != s1 && e.b != s1 {
+			continue
+		}
+		// age all the edges of the winner and move its neighbours
+		g.edges[e]++
+		neighb := e.a
+		if neighb == s1 {
+			neighb = e.b
+		}
+		move(g.units[neighb], vec, g.config.NeighbRate)
+	}
+	// connect the two closest units by a fresh edge
+	g.edges[newGNGEdge(s1, s2)] = 0
+	g.prune()
+
+	return nil
+}
+
+// closestTwo returns indices of the two units closest to vec and distance to the closest one
+func (g GNG) closestTwo(vec []float64) (int, int, float64, error) {
+	s1, s2 := -1, -1
+	var d1, d2 float64
+	for i, unit := range g.units {
+		d, err := Distance(g.metric, vec, unit)
+		if err != nil {
+			return -1, -1, 0.0, err
+		}
+		switch {
+		case s1 == -1 || d < d1:
+			s2, d2 = s1, d1
+			s1, d1 = i, d
+		case s2 == -1 || d < d2:
+			s2, d2 = i, d
+		}
+	}
+	return s1, s2, d1, nil
+}
+
+// prune removes edges older than maximum edge age and units without any edges
+func (g *GNG) prune() {
+	for e, age := range g.edges {
+		if age > g.config.MaxAge {
+			delete(g.edges, e)
+		}
+	}
+	connected := make([]bool, len(g.units))
+	for e := range g.edges {
+		connected[e.a], connected[e.b] = true, true
+	}
+	// new indices of the remaining units
+	index := make([]int, len(g.units))
+	units, errs := g.units[:0], g.errs[:0]
+	for i := range connected {
+		index[i] = len(units)
+		if connected[i] {
+			units = append(units, g.units[i])
+			errs = append(errs, g.errs[i])
+		}
+	}
+	if len(units) == len(connected) {
+		return
+	}
+	edges := make(map[gngEdge]int, len(g.edges))
+	for e, age := range g.edges {
+		edges[newGNGEdge(index[e.a], index[e.b])] = age
+	}
+	g.units, g.errs, g.edges = units, errs, edges
+}
+
+// insert inserts a new unit halfway between the unit with the highest accumulated error
+// and its neighbour with the highest accumulated error
+func (g *GNG) insert() {
+	q := 0
+	for i, e := range g.errs {
+		if e > g.errs[q] {
+			q = i
+		}
+	}
+	f := -1
+	for e := range g.edges {
+		neighb := -1
+		switch q {
+		case e.a:
+			neighb = e.b
+		case e.b:
+			neighb = e.a
+		}
+		// ties are resolved in favour of the smaller index so that the result does not depend on the edge order
+		if neighb != -1 && (f == -1 || g.errs[neighb] > g.errs[f] || (g.errs[neighb] == g.errs[f] && neighb < f)) {
+			f = neighb
+		}
+	}
+	// every unit has at least one edge after pruning
+	if f == -1 {
+		return
+	}
+	unit := make([]float64, len(g.units[q]))
+	for i := range unit {
+		unit[i] = (g.units[q][i] + g.units[f][i]) / 2.0
+	}
+	g.units = append(g.units, unit)
+	n := len(g.units) - 1
+	delete(g.edges, newGNGEdge(q, f))
+	g.edges[newGNGEdge(q, n)] = 0
+	g.edges[newGNGEdge(n, f)] = 0
+	g.errs[q] *= g.config.Alpha
+	g.errs[f] *= g.config.Alpha
+	g.errs = append(g.errs, g.errs[q])
+}
+
+// dims returns the number of GNG units and their dimension
+func (g GNG) dims() (int, int) {
+	return len(g.units), len(g.units[0])
+}
+
+// move moves vector v towards vector to by rate; missing components of to are skipped
+func move(v, to []float64, rate float64) {
+	for i := range v {
+		if !math.IsNaN(to[i]) {
+			v[i] += rate * (to[i] - v[i])
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package som
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeDefaultGNGConfig() *GNGConfig {
+	return &GNGConfig{
+		MaxUnits:   6,
+		Lambda:     10,
+		WinnerRate: 0.2,
+		NeighbRate: 0.01,
+		MaxAge:     20,
+		Alpha:      0.5,
+		Decay:      0.005,
+	}
+}
+
+func TestNewGNG(t *testing.T) {
+	assert := assert.New(t)
+
+	c := makeDefaultGNGConfig()
+	g, err := NewGNG(c, dataMx)
+	assert.NotNil(g)
+	assert.NoError(err)
+	assert.Equal("euclidean", g.Metric())
+	// GNG starts with two connected units
+	rows, cols := g.Codebook().Dims()
+	assert.Equal(2, rows)
+	assert.Equal(4, cols)
+	assert.True(mat64.Equal(mat64.NewDense(2, 2, []float64{0, 1, 1, 0}), g.Edges()))
+	// nil data
+	g, err = NewGNG(c, nil)
+	assert.Nil(g)
+	assert.Error(err)
+	// insufficient number of samples
+	g, err = NewGNG(c, mat64.NewDense(1, 4, nil))
+	assert.Nil(g)
+	assert.Error(err)
+	// invalid config
+	c.MaxUnits = 1
+	g, err = NewGNG(c, dataMx)
+	assert.Nil(g)
+	assert.Error(err)
+}
+
+func TestGNGTrain(t *testing.T) {
+	assert := assert.New(t)
+
+	c := makeDefaultGNGConfig()
+	g, err := NewGNG(c, dataMx)
+	assert.NoError(err)
+	err = g.Train(dataMx, 500)
+	assert.NoError(err)
+	// GNG grows but never exceeds maximum number of units
+	rows, _ := g.Codebook().Dims()
+	assert.True(rows > 2 && rows <= c.MaxUnits)
+	// every unit is connected to some other unit
+	edges := g.Edges()
+	for i := 0; i < rows; i++ {
+		assert.True(mat64.Sum(edges.RowView(i)) > 0)
+	}
+	// BMUs and quantization error use GNG units
+	bmus, err := g.BMUs(dataMx)
+	assert.NoError(err)
+	assert.Len(bmus, 5)
+	qErr, err := g.QuantError(dataMx)
+	assert.NoError(err)
+	assert.True(qErr < 1.0)
+	// GNG units can be serialized
+	var buf bytes.Buffer
+	n, err := g.MarshalTo("gonum", &buf)
+	assert.NoError(err)
+	assert.True(n > 0)
+	_, err = g.MarshalTo("foobar", &buf)
+	assert.Error(err)
+	// invalid training parameters
+	assert.Error(g.Train(dataMx, 0))
+	assert.Error(g.Train(nil, 10))
+	assert.Error(g.Train(mat64.NewDense(2, 3, nil), 10))
+}
+
+func TestGNGQuantErrorMetric(t *testing.T) {
+	assert := assert.New(t)
+
+	g := &GNG{
+		units:  [][]float64{{0.0, 0.0}, {3.0, 3.0}},
+		metric: "manhattan",
+	}
+	data := mat64.NewDense(2, 2, []float64{
+		1.0, 1.0,
+		3.0, 4.0,
+	})
+	// quantization error is computed using GNG metric
+	qErr, err := g.QuantError(data)
+	assert.NoError(err)
+	assert.InDelta(1.5, qErr, 1e-12)
+}
+
+func TestGNGTrainMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	data := mat64.NewDense(4, 2, []float64{
+		nan, 1.0,
+		0.0, 0.0,
+		1.0, 1.0,
+		2.0, 0.0,
+	})
+	c := makeDefaultGNGConfig()
+	c.Source = rand.NewSource(1)
+	g, err := NewGNG(c, data)
+	assert.NoError(err)
+	assert.NoError(g.Train(data, 200))
+	// missing components are never copied into GNG units
+	rows, cols := g.Codebook().Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			assert.False(math.IsNaN(g.Codebook().At(i, j)), "unit %d, component %d", i, j)
+		}
+	}
+	// incomplete samples can't initialize GNG units
+	_, err = NewGNG(c, mat64.NewDense(2, 2, []float64{nan, 1.0, 0.0, 0.0}))
+	assert.Error(err)
+}
+
+func TestPruneGNG(t *testing.T) {
+	assert := assert.New(t)
+
+	g := &GNG{
+		units: [][]float64{{0.0}, {1.0}, {2.0}},
+		errs:  []float64{0.0, 1.0, 2.0},
+		edges: map[gngEdge]int{
+			newGNGEdge(0, 1): 30,
+			newGNGEdge(1, 2): 0,
+		},
+		config: *makeDefaultGNGConfig(),
+	}
+	// old edge and disconnected unit are removed
+	g.prune()
+	assert.Len(g.units, 2)
+	assert.EqualValues([]float64{1.0, 2.0}, g.errs)
+	assert.Equal(map[gngEdge]int{newGNGEdge(0, 1): 0}, g.edges)
+}
+
+func TestGNGSource(t *testing.T) {
+	assert := assert.New(t)
+
+	train := func(seed int64) *mat64.Dense {
+		c := makeDefaultGNGConfig()
+		c.Source = rand.NewSource(seed)
+		g, err := NewGNG(c, dataMx)
+		assert.NoError(err)
+		assert.NoError(g.Train(dataMx, 200))
+		return g.Codebook()
+	}
+	assert.True(mat64.Equal(train(3), train(3)))
+}
@@ -0,0 +1,184 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// GraphUnitDist returns a matrix which contains distances between all units of custom grid graph.
+// graph is a square matrix whose non-zero elements specify the lengths of the edges between
+// the units: an adjacency matrix produces hop distances, while a matrix of distances between
+// the units is treated as a complete graph. The returned distances are the lengths of the
+// shortest paths between the units. GraphUnitDist fails with error if graph is invalid or if
+// it is not connected.
+func GraphUnitDist(graph *mat64.Dense) (*mat64.Dense, error) {
+	if err := validateGraph(graph); err != nil {
+		return nil, err
+	}
+	units, _ := graph.Dims()
+	dist := mat64.NewDense(units, units, nil)
+	for i := 0; i < units; i++ {
+		for j := 0; j < units; j++ {
+			switch {
+			case i == j:
+				dist.Set(i, j, 0.0)
+			case graph.At(i, j) > 0.0:
+				dist.Set(i, j, graph.At(i, j))
+			default:
+				dist.Set(i, j, math.Inf(1))
+			}
+		}
+	}
+	// Floyd-Warshall shortest paths
+	for k := 0; k < units; k++ {
+		for i := 0; i < units; i++ {
+			for j := 0; j < units; j++ {
+				if d := dist.At(i, k) + dist.At(k, j); d < dist.At(i, j) {
+					dist.Set(i, j, d)
+				}
+			}
+		}
+	}
+	for i := 0; i < units; i++ {
+		for j := 0; j < units; j++ {
+			if math.IsInf(dist.At(i, j), 1) {
+				return nil, fmt.Errorf("SOM grid graph is not connected: no path between units %d and %d", i, j)
+			}
+		}
+	}
+
+	return dist, nil
+}
+
+// GraphCoords returns a matrix which contains 2D coordinates of all units of custom grid graph
+// stored row by row. The coordinates are computed using classical multidimensional scaling of
+// the unit distances returned by GraphUnitDist, so that they are suitable for visualization.
+// GraphCoords fails with error if the unit distances could not be computed.
+func GraphCoords(graph *mat64.Dense) (*mat64.Dense, error) {
+	dist, err := GraphUnitDist(graph)
+	if err != nil {
+		return nil, err
+	}
+	units, _ := dist.Dims()
+	// double centered matrix of squared distances
+	sqDist := make([]float64, units*units)
+	rowMeans := make([]float64, units)
+	mean := 0.0
+	for i := 0; i < units; i++ {
+		for j := 0; j < units; j++ {
+			d := dist.At(i, j) * dist.At(i, j)
+			sqDist[i*units+j] = d
+			rowMeans[i] += d / float64(units)
+			mean += d / float64(units*units)
+		}
+	}
+	for i := 0; i < units; i++ {
+		for j := 0; j < units; j++ {
+			sqDist[i*units+j] = -0.5 * (sqDist[i*units+j] - rowMeans[i] - rowMeans[j] + mean)
+		}
+	}
+	var eigen mat64.EigenSym
+	if ok := eigen.Factorize(mat64.NewSymDense(units, sqDist), true); !ok {
+		return nil, fmt.Errorf("could not compute SOM grid graph coordinates")
+	}
+	values := eigen.Values(nil)
+	var vectors mat64.Dense
+	vectors.EigenvectorsSym(&eigen)
+	// eigenvalues are sorted in ascending order; use the two largest ones
+	coords := mat64.NewDense(units, 2, nil)
+	for k := 0; k < 2; k++ {
+		col := units - 1 - k
+		scale := math.Sqrt(math.Max(values[col], 0.0))
+		for i := 0; i < units; i++ {
+			coords.Set(i, k, vectors.At(i, col)*scale)
+		}
+	}
+	// shift the coordinates so they start at the origin
+	for k := 0; k < 2; k++ {
+		min := mat64.Min(coords.ColView(k))
+		for i := 0; i < units; i++ {
+			coords.Set(i, k, coords.At(i, k)-min)
+		}
+	}
+
+	return coords, nil
+}
+
+// validateGraph validates custom grid graph
+// It returns error if graph is not a square symmetric matrix with non-negative elements
+func validateGraph(graph *mat64.Dense) error {
+	if graph == nil {
+		return fmt.Errorf("invalid SOM grid graph: %v", graph)
+	}
+	rows, cols := graph.Dims()
+	if rows != cols {
+		return fmt.Errorf("SOM grid graph must be a square matrix: %dx%d", rows, cols)
+	}
+	if rows < 2 {
+		return fmt.Errorf("SOM grid graph must contain at least 2 units: %d", rows)
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if graph.At(i, j) < 0.0 {
+				return fmt.Errorf("SOM grid graph contains negative edge: %f", graph.At(i, j))
+			}
+			if graph.At(i, j) != graph.At(j, i) {
+				return fmt.Errorf("SOM grid graph must be symmetric")
+			}
+		}
+	}
+
+	return nil
+}
+
+// graphSVG creates an SVG element which contains the U-Matrix of custom grid graph units.
+// Each unit is drawn as a circle and the graph edges are drawn as lines between the units.
+func graphSVG(grid *Grid, umatrix []float64, minDistance, maxDistance float64, s unitStyle) svgElement {
+	// scale the coord grid to something visible
+	mul, off := s.cellSize(), s.margin()
+	scale := func(x float64) float64 { return mul*(x+0.5) + off }
+
+	rows, _ := grid.coords.Dims()
+	svgElem := svgElement{
+		Width:    scale(mat64.Max(grid.coords.ColView(0))) + 0.5*mul + off,
+		Height:   scale(mat64.Max(grid.coords.ColView(1))) + 0.5*mul + off,
+		Polygons: []interface{}{},
+	}
+	for i := 0; i < rows; i++ {
+		for j := i + 1; j < rows; j++ {
+			if grid.graph.At(i, j) > 0.0 {
+				svgElem.Polygons = append(svgElem.Polygons, line{
+					X1:    scale(grid.coords.At(i, 0)),
+					Y1:    scale(grid.coords.At(i, 1)),
+					X2:    scale(grid.coords.At(j, 0)),
+					Y2:    scale(grid.coords.At(j, 1)),
+					Class: "edge",
+					Style: s.strokeStyle(),
+				})
+			}
+		}
+	}
+	centers := make(map[int][2]float64)
+	for row := 0; row < rows; row++ {
+		x := scale(grid.coords.At(row, 0))
+		y := scale(grid.coords.At(row, 1))
+		r, g, b := s.unitRGB(row, umatrix, minDistance, maxDistance)
+		svgElem.Polygons = append(svgElem.Polygons, circle{
+			Cx:      x,
+			Cy:      y,
+			R:       0.45 * mul,
+			Class:   "unit",
+			Style:   s.fillStyle(r, g, b),
+			Title:   s.tooltip(row),
+			Animate: s.unitAnimation(row, minDistance, maxDistance),
+		})
+		svgElem.Polygons = append(svgElem.Polygons, s.unitSVG(row, x, y, mul)...)
+		centers[row] = [2]float64{x, y}
+	}
+
+	svgElem.Polygons = append(svgElem.Polygons, s.trajectorySVG(centers, mul)...)
+
+	return svgElem
+}
@@ -0,0 +1,131 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// ringGraph returns adjacency matrix of a ring of n units
+func ringGraph(n int) *mat64.Dense {
+	graph := mat64.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		graph.Set(i, (i+1)%n, 1.0)
+		graph.Set((i+1)%n, i, 1.0)
+	}
+	return graph
+}
+
+func TestGraphUnitDist(t *testing.T) {
+	assert := assert.New(t)
+
+	// ring graph unit distances are hop distances
+	uDist, err := GraphUnitDist(ringGraph(6))
+	assert.NoError(err)
+	rows, cols := uDist.Dims()
+	assert.Equal(6, rows)
+	assert.Equal(6, cols)
+	assert.Equal(0.0, uDist.At(0, 0))
+	assert.Equal(1.0, uDist.At(0, 1))
+	assert.Equal(1.0, uDist.At(0, 5))
+	assert.Equal(3.0, uDist.At(0, 3))
+	// weighted edges
+	graph := mat64.NewDense(3, 3, []float64{
+		0.0, 1.0, 5.0,
+		1.0, 0.0, 2.0,
+		5.0, 2.0, 0.0,
+	})
+	uDist, err = GraphUnitDist(graph)
+	assert.NoError(err)
+	assert.Equal(3.0, uDist.At(0, 2))
+	// disconnected graph
+	graph = mat64.NewDense(3, 3, []float64{
+		0.0, 1.0, 0.0,
+		1.0, 0.0, 0.0,
+		0.0, 0.0, 0.0,
+	})
+	uDist, err = GraphUnitDist(graph)
+	assert.Nil(uDist)
+	assert.Error(err)
+	// invalid graphs
+	invalid := []*mat64.Dense{
+		nil,
+		mat64.NewDense(2, 3, nil),
+		mat64.NewDense(1, 1, nil),
+		mat64.NewDense(2, 2, []float64{0.0, -1.0, -1.0, 0.0}),
+		mat64.NewDense(2, 2, []float64{0.0, 1.0, 2.0, 0.0}),
+	}
+	for _, graph := range invalid {
+		uDist, err = GraphUnitDist(graph)
+		assert.Nil(uDist)
+		assert.Error(err)
+	}
+}
+
+func TestGraphCoords(t *testing.T) {
+	assert := assert.New(t)
+
+	coords, err := GraphCoords(ringGraph(6))
+	assert.NoError(err)
+	rows, cols := coords.Dims()
+	assert.Equal(6, rows)
+	assert.Equal(2, cols)
+	// coordinates start at the origin
+	assert.InDelta(0.0, mat64.Min(coords.ColView(0)), 0.0001)
+	assert.InDelta(0.0, mat64.Min(coords.ColView(1)), 0.0001)
+	// opposite ring units are laid out further apart than neighbours
+	dist, err := DistanceMx("euclidean", coords)
+	assert.NoError(err)
+	assert.True(dist.At(0, 3) > dist.At(0, 1))
+	// invalid graph
+	coords, err = GraphCoords(mat64.NewDense(2, 3, nil))
+	assert.Nil(coords)
+	assert.Error(err)
+}
+
+func TestGraphMap(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := &MapConfig{
+		Grid: &GridConfig{
+			Type:  "graph",
+			Graph: ringGraph(8),
+		},
+		Cb: &CbConfig{
+			Dim:      4,
+			InitFunc: LinInit,
+		},
+	}
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	assert.Equal(8, m.Grid().Units())
+	assert.EqualValues([]int{8}, m.Grid().Size())
+	uDist, err := m.UnitDist()
+	assert.NoError(err)
+	assert.Equal(4.0, uDist.At(0, 4))
+	// both training algorithms work on graph grid
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	origAlgorithm := tSom.Algorithm
+	tSom.Algorithm = "batch"
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	tSom.Algorithm = origAlgorithm
+	// u-matrix is rendered using graph layout
+	var buf bytes.Buffer
+	err = m.UMatrix(&buf, dataMx, map[int]int{0: 1}, "svg", "Graph")
+	assert.NoError(err)
+	out := buf.String()
+	assert.Equal(1, strings.Count(out, "<svg "))
+	assert.Equal(8, strings.Count(out, "<circle "))
+	assert.Equal(8, strings.Count(out, "<line "))
+	// graph must be supplied
+	mapCfg.Grid.Graph = nil
+	m, err = NewMap(mapCfg, dataMx)
+	assert.Nil(m)
+	assert.Error(err)
+}
@@ -3,6 +3,8 @@ package som
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 	"strings"
 
 	"github.com/gonum/floats"
@@ -18,8 +20,12 @@ type Grid struct {
 	size []int
 	// ushape holds grid unit shape
 	ushape string
+	// gtype holds grid type
+	gtype string
 	// coords holds grid point coordinates
 	coords *mat64.Dense
+	// graph holds graph grid unit graph
+	graph *mat64.Dense
 }
 
 // NewGrid creates new grid and returns it
@@ -30,8 +36,24 @@ func NewGrid(c *GridConfig) (*Grid, error) {
 		return nil, err
 	}
 
+	// graph grid units are laid out using their graph
+	if c.Type == "graph" {
+		coords, err := GraphCoords(c.Graph)
+		if err != nil {
+			return nil, err
+		}
+		units, _ := coords.Dims()
+		return &Grid{
+			size:   []int{units},
+			ushape: c.UShape,
+			gtype:  c.Type,
+			coords: coords,
+			graph:  c.Graph,
+		}, nil
+	}
+
 	// grid coordinates matrix
-	coords, err := GridCoords(c.UShape, c.Size)
+	coords, err := coordsInitFns[c.Type](c.UShape, c.Size)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +61,7 @@ func NewGrid(c *GridConfig) (*Grid, error) {
 	return &Grid{
 		size:   c.Size,
 		ushape: c.UShape,
+		gtype:  c.Type,
 		coords: coords,
 	}, nil
 }
@@ -53,11 +76,192 @@ func (g *Grid) UShape() string {
 	return g.ushape
 }
 
+// Type returns grid type
+func (g *Grid) Type() string {
+	return g.gtype
+}
+
+// Units returns the number of grid units
+func (g *Grid) Units() int {
+	rows, _ := g.coords.Dims()
+	return rows
+}
+
+// cbDims returns the dimensions used to initialize codebook of the grid.
+// Spherical, graph and 1D grid codebooks are initialized as if the units formed a [n, 1] grid.
+func (g *Grid) cbDims() []int {
+	if g.gtype == "sphere" || g.gtype == "graph" || len(g.size) == 1 {
+		return []int{g.Units(), 1}
+	}
+	return g.size
+}
+
 // Coords returns a matrix that contains grid coordinates
 func (g *Grid) Coords() mat64.Matrix {
 	return g.coords
 }
 
+// UnitDist returns a matrix which contains distances between all grid units.
+// The returned distances respect the grid topology.
+func (g *Grid) UnitDist() (*mat64.Dense, error) {
+	if g.gtype == "graph" {
+		return GraphUnitDist(g.graph)
+	}
+	return unitDist(g.gtype, g.ushape, g.size, g.coords)
+}
+
+// GridUnitDist returns a matrix which contains distances between all units of the grid
+// of the given type, unit shape and dimensions. The distances respect the grid topology:
+// on planar grid the returned distances are euclidean distances between unit coordinates,
+// on toroid grid the units on the opposite edges of the grid are neighbours and on cylinder
+// grid only the units on the opposite edges along x axis (i.e. first and last column) are neighbours.
+// On spherical grid the returned distances are great-circle distances between the units.
+// Graph grid unit distances can be computed using GraphUnitDist.
+// It fails with error if the requested grid type or unit shape are unsupported
+// or if the grid coordinates could not be computed.
+func GridUnitDist(gridType, uShape string, dims []int) (*mat64.Dense, error) {
+	coordsInitFn, ok := coordsInitFns[gridType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SOM grid type: %s", gridType)
+	}
+	coords, err := coordsInitFn(uShape, dims)
+	if err != nil {
+		return nil, err
+	}
+	return unitDist(gridType, uShape, dims, coords)
+}
+
+// unitDist computes distances between all grid units stored in coords rows given grid type,
+// unit shape and grid dimensions
+func unitDist(gridType, uShape string, dims []int, coords *mat64.Dense) (*mat64.Dense, error) {
+	switch gridType {
+	case "toroid":
+		return wrappedDistMx(coords, gridPeriods(uShape, dims))
+	case "cylinder":
+		// cylinder only wraps around x axis
+		periods := gridPeriods(uShape, dims)
+		return wrappedDistMx(coords, periods[:1])
+	case "sphere":
+		return sphereDistMx(coords, sphereRadius(dims[0]))
+	default:
+		return DistanceMx("euclidean", coords)
+	}
+}
+
+// gridPeriods returns the lengths of the grid along each of its axis in coordinate space
+func gridPeriods(uShape string, dims []int) []float64 {
+	// 1D grids only wrap around their only axis
+	if len(dims) == 1 {
+		return []float64{float64(dims[0])}
+	}
+	xPeriod := float64(dims[1])
+	yPeriod := float64(dims[0])
+	if strings.EqualFold(uShape, "hexagon") {
+		yPeriod *= math.Sqrt(0.75)
+	}
+	if strings.EqualFold(uShape, "triangle") {
+		xPeriod *= math.Sqrt(0.75)
+		yPeriod *= 1.5
+	}
+	if len(dims) == 3 {
+		return []float64{xPeriod, yPeriod, float64(dims[2])}
+	}
+	return []float64{xPeriod, yPeriod}
+}
+
+// wrappedDistMx computes a matrix of euclidean distances between each row in coords where
+// the coordinates wrap around along each axis with non-zero period: the distance
+// along such axis is the shorter of the direct and wrap-around distance.
+func wrappedDistMx(coords *mat64.Dense, periods []float64) (*mat64.Dense, error) {
+	_, cols := coords.Dims()
+	if len(periods) > cols {
+		return nil, fmt.Errorf("Incorrect number of periods: %d", len(periods))
+	}
+	return distanceMx(coords, func(a, b []float64) (float64, error) {
+		return wrappedDist(a, b, periods), nil
+	})
+}
+
+// wrappedDist computes euclidean distance between coordinates a and b which wrap around
+// along each axis with non-zero period
+func wrappedDist(a, b, periods []float64) float64 {
+	d := 0.0
+	for i := 0; i < len(a); i++ {
+		diff := math.Abs(a[i] - b[i])
+		if i < len(periods) && periods[i] > 0.0 {
+			diff = math.Min(diff, periods[i]-diff)
+		}
+		d += diff * diff
+	}
+	return math.Sqrt(d)
+}
+
+// unitNeighbours returns sorted indices of neighbouring units of all grid units without computing the distances
+// between all of them. Graph grid units are neighbours if they are connected by an edge and sphere grid units
+// if they are adjacent vertices of the geodesic grid. Other grid units are neighbours if their grid distance
+// is lower than sqrt(2)*1.01 which only the units at most one lattice step apart along every grid axis can be.
+func unitNeighbours(grid *Grid) ([][]int, error) {
+	units := grid.Units()
+	neighbs := make([][]int, units)
+	switch grid.gtype {
+	case "graph":
+		for unit := range neighbs {
+			for n, edge := range grid.graph.RawRowView(unit) {
+				if edge > 0.0 && n != unit {
+					neighbs[unit] = append(neighbs[unit], n)
+				}
+			}
+		}
+		return neighbs, nil
+	case "sphere":
+		_, neighbs = sphereLattice(grid.size[0])
+		return neighbs, nil
+	}
+	var periods []float64
+	switch grid.gtype {
+	case "toroid":
+		periods = gridPeriods(grid.ushape, grid.size)
+	case "cylinder":
+		// cylinder only wraps around x axis
+		periods = gridPeriods(grid.ushape, grid.size)[:1]
+	}
+	// units are stored with the first grid axis changing fastest
+	dims := grid.size
+	strides := make([]int, len(dims))
+	stride := 1
+	for i, dim := range dims {
+		strides[i] = stride
+		stride *= dim
+	}
+	// number of combinations of the lattice offsets along the grid axes
+	combos := 1
+	for range dims {
+		combos *= 3
+	}
+	for unit := range neighbs {
+		seen := make(map[int]bool)
+		for k := 0; k < combos; k++ {
+			// lattice offsets -1, 0 or 1 along every axis wrap around the grid edges,
+			// the units which are not neighbours are filtered out by their distance
+			n, c := 0, k
+			for i, dim := range dims {
+				off := c%3 - 1
+				c /= 3
+				n += ((unit/strides[i]%dim + off + dim) % dim) * strides[i]
+			}
+			if n == unit || seen[n] {
+				continue
+			}
+			seen[n] = true
+			if wrappedDist(grid.coords.RawRowView(unit), grid.coords.RawRowView(n), periods) < math.Sqrt2*1.01 {
+				neighbs[unit] = append(neighbs[unit], n)
+			}
+		}
+		sort.Ints(neighbs[unit])
+	}
+	return neighbs, nil
+}
+
 // GridSize tries to estimate the best dimensions of map from data matrix and given unit shape.
 // It determines the grid size from eigenvectors of input data: the grid dimensions are
 // calculated from the ratio of two highest input eigenvalues.
@@ -113,10 +317,27 @@ func GridSize(data *mat64.Dense, uShape string) ([]int, error) {
 
 // RandInit returns a matrix initialized to uniformly distributed random values
 // in each column in range between [max, min] where max and min are maximum and minmum values
-// in particular matrix column. The returned matrix has product(dims) number of rows and
-// as many columns as the matrix passed in as a parameter.
+// in particular matrix column. Missing values encoded as NaN are ignored. The returned matrix has
+// product(dims) number of rows and as many columns as the matrix passed in as a parameter.
 // It fails with error if the new matrix could not be initialized or if data is nil.
 func RandInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+	return randInit(data, dims, nil)
+}
+
+// RandInitSource returns codebook initialization function which initializes the codebook
+// in the same way as RandInit, but uses random numbers generated by the provided source.
+// Maps initialized with sources seeded by the same seed have the same codebooks.
+func RandInitSource(src rand.Source) CbInitFunc {
+	return func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+		if src == nil {
+			return nil, fmt.Errorf("invalid random number source: %v", src)
+		}
+		return randInit(data, dims, src)
+	}
+}
+
+// randInit initializes random codebook using random number source src or the default one if src is nil
+func randInit(data *mat64.Dense, dims []int, src rand.Source) (*mat64.Dense, error) {
 	// if nil matrix is passed in, return error
 	if data == nil {
 		return nil, fmt.Errorf("invalid input matrix: %v", data)
@@ -133,19 +354,17 @@ func RandInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 	}
 	// input matrix dimensions
 	_, cols := data.Dims()
-	// get max of each column
-	max, err := matrix.ColsMax(cols, data)
-	if err != nil {
-		return nil, err
-	}
-	// get min of each column
-	min, err := matrix.ColsMin(cols, data)
-	if err != nil {
-		return nil, err
-	}
+	// get min and max of each column ignoring missing values
+	min, max := colsRange(data)
 	mUnits := utils.IntProduct(dims)
 	// initialize matrix to rand values between 0.0 and 1.0
-	codebook, err := matrix.MakeRandom(mUnits, cols, 0.0, 1.0)
+	var codebook *mat64.Dense
+	var err error
+	if src != nil {
+		codebook, err = matrix.MakeRandomSource(mUnits, cols, 0.0, 1.0, src)
+	} else {
+		codebook, err = matrix.MakeRandom(mUnits, cols, 0.0, 1.0)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -159,9 +378,108 @@ func RandInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 	return codebook, nil
 }
 
+// SampleInit returns a matrix whose rows are distinct data rows drawn at random, i.e. codebook
+// vectors are initialized to randomly picked data samples without replacement. Data rows
+// which contain missing NaN values are never picked. The returned matrix has product(dims) rows.
+// It fails with error if data is nil, if dims are invalid or if data has fewer complete rows than there are map units.
+func SampleInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+	return sampleInit(data, dims, nil)
+}
+
+// SampleInitSource returns codebook initialization function which initializes the codebook
+// in the same way as SampleInit, but picks the data samples using the provided random number source.
+func SampleInitSource(src rand.Source) CbInitFunc {
+	return func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+		if src == nil {
+			return nil, fmt.Errorf("invalid random number source: %v", src)
+		}
+		return sampleInit(data, dims, src)
+	}
+}
+
+// sampleInit initializes codebook to random data samples using random number source src or the default one if src is nil
+func sampleInit(data *mat64.Dense, dims []int, src rand.Source) (*mat64.Dense, error) {
+	// if nil matrix is passed in, return error
+	if data == nil {
+		return nil, fmt.Errorf("invalid input matrix: %v", data)
+	}
+	// dims can't be nil
+	if dims == nil {
+		return nil, fmt.Errorf("invalid dimensions: %v", dims)
+	}
+	// dims can't be nil or negative
+	for _, dim := range dims {
+		if dim <= 0 {
+			return nil, fmt.Errorf("Non-Positive dimensions supplied: %v", dims)
+		}
+	}
+	rows, cols := data.Dims()
+	// only complete data rows can be picked
+	complete := []int{}
+	for i := 0; i < rows; i++ {
+		if !floats.HasNaN(data.RawRowView(i)) {
+			complete = append(complete, i)
+		}
+	}
+	mUnits := utils.IntProduct(dims)
+	if len(complete) < mUnits {
+		return nil, fmt.Errorf("insufficient number of data samples: %d, required: %d", len(complete), mUnits)
+	}
+	r := rand.New(randSource(src))
+	perm := r.Perm(len(complete))
+	codebook := mat64.NewDense(mUnits, cols, nil)
+	for j := 0; j < mUnits; j++ {
+		codebook.SetRow(j, data.RawRowView(complete[perm[j]]))
+	}
+	return codebook, nil
+}
+
+// CodebookInit returns codebook initialization function which initializes the codebook to a copy
+// of the supplied codebook, e.g. a codebook of previously trained map. It allows to resume training
+// of a map on new data instead of training it from scratch. The returned function fails with error
+// if the codebook is nil, if its number of rows is different from the number of map units or if
+// its number of columns is different from the number of data columns.
+func CodebookInit(codebook mat64.Matrix) CbInitFunc {
+	return func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+		if codebook == nil {
+			return nil, fmt.Errorf("invalid codebook: %v", codebook)
+		}
+		if data == nil {
+			return nil, fmt.Errorf("invalid input matrix: %v", data)
+		}
+		rows, cols := codebook.Dims()
+		if units := utils.IntProduct(dims); rows != units {
+			return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", rows, units)
+		}
+		if _, dataCols := data.Dims(); cols != dataCols {
+			return nil, fmt.Errorf("invalid codebook dimension: %d, expected: %d", cols, dataCols)
+		}
+		return mat64.DenseCopyOf(codebook), nil
+	}
+}
+
+// colsRange returns min and max values of every data column ignoring missing NaN values.
+// Both values of a column which contains missing values only are set to 0.
+func colsRange(data *mat64.Dense) ([]float64, []float64) {
+	rows, cols := data.Dims()
+	min, max := make([]float64, cols), make([]float64, cols)
+	for j := 0; j < cols; j++ {
+		min[j], max[j] = math.Inf(1), math.Inf(-1)
+		for i := 0; i < rows; i++ {
+			if v := data.At(i, j); !math.IsNaN(v) {
+				min[j], max[j] = math.Min(min[j], v), math.Max(max[j], v)
+			}
+		}
+		if math.IsInf(min[j], 1) {
+			min[j], max[j] = 0.0, 0.0
+		}
+	}
+	return min, max
+}
+
 // LinInit returns a matrix initialized to values lying in a linear space
 // spanned by principal components of data stored in the data matrix passed in as parameter.
-// It fails with error if the new matrix could not be initialized or if data is nil.
+// It fails with error if the new matrix could not be initialized, if data is nil or if it contains missing NaN values.
 func LinInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 	if err := validateLinInit(data, dims); err != nil {
 		return nil, err
@@ -244,10 +562,28 @@ func validateLinInit(data *mat64.Dense, dims []int) error {
 		}
 	}
 	// Linear initialization requires at least 2 samples
-	samples, _ := data.Dims()
+	samples, dataDim := data.Dims()
 	if samples < 2 {
 		return fmt.Errorf("Insufficient number of samples: %d", samples)
 	}
+	// principal components can't be computed from incomplete data
+	for i := 0; i < samples; i++ {
+		for _, v := range data.RawRowView(i) {
+			if math.IsNaN(v) {
+				return fmt.Errorf("missing values are not supported by linear initialization")
+			}
+		}
+	}
+	// multidimensional data can't span more map dimensions than it has features
+	mapDim := 0
+	for _, dim := range dims {
+		if dim > 1 {
+			mapDim++
+		}
+	}
+	if dataDim > 1 && mapDim > dataDim {
+		return fmt.Errorf("Map dimensions exceed data dimensions: %d", mapDim)
+	}
 	return nil
 }
 
@@ -358,6 +694,10 @@ func GridCoords(uShape string, dims []int) (*mat64.Dense, error) {
 		seq := makeSeq(mUnits/counts[i+1], dims[i], counts[i])
 		coords.SetCol(i, seq)
 	}
+	// 1D grids are chains of units regardless of the unit shape
+	if mDims == 1 {
+		return coords, nil
+	}
 	// retrieve x and y coords
 	x := mat64.Col(make([]float64, mUnits), 0, coords)
 	y := mat64.Col(make([]float64, mUnits), 1, coords)
@@ -386,9 +726,27 @@ func GridCoords(uShape string, dims []int) (*mat64.Dense, error) {
 		coords.SetCol(0, x)
 		coords.SetCol(1, y)
 	}
+	// Triangles alternate their orientation, so the centroids of every other triangle are
+	// offset along y-axis. The spacing makes distances of a unit to all its three neighbors equal
+	if strings.EqualFold(uShape, "triangle") {
+		for i := 0; i < mUnits; i++ {
+			x, y := coords.At(i, 0), coords.At(i, 1)
+			offset := 0.0
+			if !triangleUp(int(x), int(y)) {
+				offset = 0.5
+			}
+			coords.Set(i, 0, x*math.Sqrt(0.75))
+			coords.Set(i, 1, y*1.5+offset)
+		}
+	}
 	return coords, nil
 }
 
+// triangleUp returns true if the triangle unit in column x and row y points up
+func triangleUp(x, y int) bool {
+	return (x+y)%2 == 0
+}
+
 // validate gridCoords validates whether you can initialize SOM unit coordinates
 // given the provided parameters. It returns error if the validation fails
 func validateGridCoords(uShape string, dims []int) error {
@@ -411,10 +769,10 @@ func validateGridCoords(uShape string, dims []int) error {
 	if mDims > 3 {
 		return fmt.Errorf("unsupported dimensions requested: %d", mDims)
 	}
-	// can't use hexagon with dims > 2
-	if strings.EqualFold(uShape, "hexagon") {
+	// can't use hexagon or triangle with dims > 2
+	if strings.EqualFold(uShape, "hexagon") || strings.EqualFold(uShape, "triangle") {
 		if mDims > 2 {
-			return fmt.Errorf("Exceeded allowed hexagon dims: %d", mDims)
+			return fmt.Errorf("Exceeded allowed %s dims: %d", uShape, mDims)
 		}
 	}
 	return nil
@@ -1,6 +1,8 @@
 package som
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/gonum/matrix/mat64"
@@ -49,6 +51,119 @@ func TestNewGrid(t *testing.T) {
 	gCfg.Size = origDims
 }
 
+func TestGridUnitDist(t *testing.T) {
+	assert := assert.New(t)
+
+	dims := []int{3, 4}
+	// planar grid unit distances are euclidean distances
+	uDist, err := GridUnitDist("planar", "rectangle", dims)
+	assert.NoError(err)
+	rows, cols := uDist.Dims()
+	assert.Equal(12, rows)
+	assert.Equal(12, cols)
+	// unit 9 has coordinates [3, 0]
+	assert.InDelta(3.0, uDist.At(0, 9), 0.01)
+	// unit 11 has coordinates [3, 2]
+	assert.InDelta(3.606, uDist.At(0, 11), 0.01)
+	// toroid grid wraps around both axes
+	uDist, err = GridUnitDist("toroid", "rectangle", dims)
+	assert.NoError(err)
+	assert.InDelta(1.0, uDist.At(0, 9), 0.01)
+	assert.InDelta(1.414, uDist.At(0, 11), 0.01)
+	assert.InDelta(1.0, uDist.At(0, 2), 0.01)
+	// cylinder grid wraps around x axis only
+	uDist, err = GridUnitDist("cylinder", "rectangle", dims)
+	assert.NoError(err)
+	assert.InDelta(1.0, uDist.At(0, 9), 0.01)
+	assert.InDelta(2.236, uDist.At(0, 11), 0.01)
+	assert.InDelta(2.0, uDist.At(0, 2), 0.01)
+	// hexagon toroid grid
+	uDist, err = GridUnitDist("toroid", "hexagon", []int{2, 4})
+	assert.NoError(err)
+	// unit 7 has coordinates [3.5, sqrt(0.75)]
+	assert.InDelta(1.0, uDist.At(0, 7), 0.01)
+	// unit 6 has coordinates [3, 0]
+	assert.InDelta(1.0, uDist.At(0, 6), 0.01)
+	// 3D toroid wraps around z axis too
+	uDist, err = GridUnitDist("toroid", "rectangle", []int{2, 2, 4})
+	assert.NoError(err)
+	// unit 12 has coordinates [0, 0, 3]
+	assert.InDelta(1.0, uDist.At(0, 12), 0.01)
+	// 3D cylinder only wraps around x axis
+	uDist, err = GridUnitDist("cylinder", "rectangle", []int{2, 2, 4})
+	assert.NoError(err)
+	assert.InDelta(3.0, uDist.At(0, 12), 0.01)
+	// every triangle on toroid grid has exactly three neighbours
+	uDist, err = GridUnitDist("toroid", "triangle", []int{4, 6})
+	assert.NoError(err)
+	rows, _ = uDist.Dims()
+	for i := 0; i < rows; i++ {
+		neighbs := 0
+		for _, d := range uDist.RawRowView(i) {
+			if d > 0.0 && d < math.Sqrt2*1.01 {
+				assert.InDelta(1.0, d, 0.01)
+				neighbs++
+			}
+		}
+		assert.Equal(3, neighbs)
+	}
+	// unsupported grid type
+	uDist, err = GridUnitDist("foobar", "rectangle", dims)
+	assert.Nil(uDist)
+	assert.Error(err)
+	// grid method returns the same distances
+	g, err := NewGrid(&GridConfig{Size: dims, Type: "toroid", UShape: "rectangle"})
+	assert.NoError(err)
+	assert.Equal("toroid", g.Type())
+	gDist, err := g.UnitDist()
+	assert.NoError(err)
+	tDist, err := GridUnitDist("toroid", "rectangle", dims)
+	assert.NoError(err)
+	assert.True(mat64.Equal(gDist, tDist))
+}
+
+func TestUnitNeighbours(t *testing.T) {
+	assert := assert.New(t)
+
+	// lattice neighbours are the same units which are close in grid unit distances
+	for _, gType := range []string{"planar", "toroid", "cylinder"} {
+		for _, uShape := range []string{"rectangle", "hexagon", "triangle"} {
+			for _, dims := range [][]int{{2}, {5}, {2, 2}, {3, 4}, {5, 6}, {4, 3, 2}} {
+				grid, err := NewGrid(&GridConfig{Size: dims, Type: gType, UShape: uShape})
+				// not every unit shape supports 3D grids
+				if err != nil {
+					continue
+				}
+				neighbs, err := unitNeighbours(grid)
+				assert.NoError(err)
+				uDist, err := grid.UnitDist()
+				assert.NoError(err)
+				for unit, ns := range neighbs {
+					expected := []int{}
+					for n, d := range uDist.RawRowView(unit) {
+						if n != unit && d < math.Sqrt2*1.01 {
+							expected = append(expected, n)
+						}
+					}
+					assert.Equal(expected, append([]int{}, ns...), "%s %s %v unit %d", gType, uShape, dims, unit)
+				}
+			}
+		}
+	}
+	// sphere units are neighbours if they are adjacent grid vertices
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "sphere", UShape: "hexagon"})
+	assert.NoError(err)
+	neighbs, err := unitNeighbours(grid)
+	assert.NoError(err)
+	uDist, err := grid.UnitDist()
+	assert.NoError(err)
+	for unit, ns := range neighbs {
+		for _, n := range ns {
+			assert.InDelta(1.0, uDist.At(unit, n), 0.25)
+		}
+	}
+}
+
 func TestGridSize(t *testing.T) {
 	assert := assert.New(t)
 
@@ -127,6 +242,14 @@ func TestRandInit(t *testing.T) {
 	randMx, err = RandInit(emptyMx, []int{2, 3})
 	assert.Nil(randMx)
 	assert.Error(err)
+	// missing values are ignored
+	nanMx := mat64.NewDense(3, 2, []float64{math.NaN(), 1.0, 2.0, math.NaN(), 4.0, 3.0})
+	randMx, err = RandInit(nanMx, []int{2, 3})
+	assert.NoError(err)
+	for i := 0; i < 6; i++ {
+		assert.True(randMx.At(i, 0) >= 2.0 && randMx.At(i, 0) <= 4.0)
+		assert.True(randMx.At(i, 1) >= 1.0 && randMx.At(i, 1) <= 3.0)
+	}
 }
 
 func TestLinInit(t *testing.T) {
@@ -162,11 +285,92 @@ func TestLinInit(t *testing.T) {
 	linMx, err = LinInit(inMx, []int{-1, 2})
 	assert.Nil(linMx)
 	assert.Error(err)
+	// 3D map
+	linMx, err = LinInit(inMx, []int{2, 3, 2})
+	assert.NotNil(linMx)
+	assert.NoError(err)
+	linR, _ = linMx.Dims()
+	assert.Equal(12, linR)
+	// map dimensions exceed data dimensions
+	linMx, err = LinInit(inMx.View(0, 0, 6, 2).(*mat64.Dense), []int{2, 3, 2})
+	assert.Nil(linMx)
+	assert.Error(err)
 	// insufficient number of samples
 	inMx = mat64.NewDense(1, 2, []float64{1, 1})
 	linMx, err = LinInit(inMx, []int{5, 2})
 	assert.Nil(linMx)
 	assert.Error(err)
+	// missing values
+	inMx = mat64.NewDense(2, 2, []float64{1, math.NaN(), 2, 3})
+	linMx, err = LinInit(inMx, []int{5, 2})
+	assert.Nil(linMx)
+	assert.EqualError(err, "missing values are not supported by linear initialization")
+}
+
+func TestCodebookInit(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(4, 2, []float64{1, 2, 3, 4, 5, 6, 7, 8})
+	data := mat64.NewDense(3, 2, nil)
+	initFn := CodebookInit(codebook)
+	cb, err := initFn(data, []int{2, 2})
+	assert.NoError(err)
+	assert.True(mat64.Equal(codebook, cb))
+	// the codebook is copied
+	cb.Set(0, 0, 10.0)
+	assert.Equal(1.0, codebook.At(0, 0))
+	// mismatched dimensions
+	cb, err = initFn(data, []int{2, 3})
+	assert.Nil(cb)
+	assert.EqualError(err, "invalid number of codebook vectors: 4, expected: 6")
+	cb, err = initFn(mat64.NewDense(3, 3, nil), []int{2, 2})
+	assert.Nil(cb)
+	assert.EqualError(err, "invalid codebook dimension: 2, expected: 3")
+	cb, err = initFn(nil, []int{2, 2})
+	assert.Nil(cb)
+	assert.Error(err)
+	cb, err = CodebookInit(nil)(data, []int{2, 2})
+	assert.Nil(cb)
+	assert.Error(err)
+}
+
+func TestSampleInit(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	data := mat64.NewDense(6, 2, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, nan, 11, 12})
+	cb, err := SampleInit(data, []int{5})
+	assert.NoError(err)
+	rows, cols := cb.Dims()
+	assert.Equal(5, rows)
+	assert.Equal(2, cols)
+	// every complete sample is picked exactly once
+	picked := make(map[float64]bool)
+	for j := 0; j < rows; j++ {
+		assert.Equal(cb.At(j, 0)+1, cb.At(j, 1))
+		picked[cb.At(j, 0)] = true
+	}
+	assert.Len(picked, 5)
+	// same seed picks the same samples
+	cb1, err := SampleInitSource(rand.NewSource(5))(data, []int{2, 2})
+	assert.NoError(err)
+	cb2, err := SampleInitSource(rand.NewSource(5))(data, []int{2, 2})
+	assert.NoError(err)
+	assert.True(mat64.Equal(cb1, cb2))
+	// not enough complete samples
+	cb, err = SampleInit(data, []int{3, 2})
+	assert.Nil(cb)
+	assert.EqualError(err, "insufficient number of data samples: 5, required: 6")
+	// invalid input
+	cb, err = SampleInit(nil, []int{2})
+	assert.Nil(cb)
+	assert.Error(err)
+	cb, err = SampleInit(data, []int{0})
+	assert.Nil(cb)
+	assert.Error(err)
+	cb, err = SampleInitSource(nil)(data, []int{2})
+	assert.Nil(cb)
+	assert.Error(err)
 }
 
 func TestGridCoords(t *testing.T) {
@@ -204,6 +408,23 @@ func TestGridCoords(t *testing.T) {
 	assert.NotNil(coords)
 	assert.NoError(err)
 	assert.True(mat64.EqualApprox(coords, expMx, 0.01))
+	// triangle shape
+	dims = []int{2, 3}
+	expMx = mat64.NewDense(6, 2, []float64{
+		0.0, 0.0,
+		0.0, 2.0,
+		0.866, 0.5,
+		0.866, 1.5,
+		1.732, 0.0,
+		1.732, 2.0})
+	coords, err = GridCoords("triangle", dims)
+	assert.NotNil(coords)
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(coords, expMx, 0.01))
+	// triangle grid can't be 3D
+	coords, err = GridCoords("triangle", []int{2, 2, 2})
+	assert.Nil(coords)
+	assert.Error(err)
 	// incorrect units shape
 	coords, err = GridCoords("fooshape", []int{2, 2})
 	assert.Nil(coords)
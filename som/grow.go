@@ -0,0 +1,148 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Grow runs Growing Grid training for a given data set and configuration parameters.
+// The map is first trained using tc for gc.Iters iterations. If the map quantization error is
+// above the target error, a new row or column of units is inserted between the unit with the
+// highest accumulated quantization error and its most distant grid neighbour. New codebook vectors
+// are interpolated from the codebook vectors of the units they are inserted between. The training
+// and growth are repeated until the target quantization error is reached or the grid would exceed
+// the maximum number of units. Growing Grid training is only supported on 2D planar grids.
+// It returns error if the supplied configuration is invalid or if the training fails.
+func (m *Map) Grow(tc *TrainConfig, gc *GrowConfig, data *mat64.Dense) error {
+	// nil data passed in
+	if data == nil {
+		return fmt.Errorf("invalid data supplied: %v", data)
+	}
+	// validate the growth configuration
+	if err := validateGrowConfig(gc); err != nil {
+		return err
+	}
+	if m.grid.gtype != "planar" || len(m.grid.size) != 2 {
+		return fmt.Errorf("growing grid requires 2D planar grid: %s %v", m.grid.gtype, m.grid.size)
+	}
+
+	for {
+		if err := m.Train(tc, data, gc.Iters); err != nil {
+			return err
+		}
+		qErr, err := m.QuantError(data)
+		if err != nil {
+			return err
+		}
+		if qErr <= gc.QError {
+			return nil
+		}
+		axis, at, err := m.growPosition(data)
+		if err != nil {
+			return err
+		}
+		dims := m.grid.size
+		// the new line contains as many units as the other grid dimension
+		if m.grid.Units()+dims[1-axis] > gc.MaxUnits {
+			return nil
+		}
+		codebook, newDims := insertGridLine(m.codebook, dims, axis, at)
+		grid, err := NewGrid(&GridConfig{
+			Size:   newDims,
+			Type:   m.grid.gtype,
+			UShape: m.grid.ushape,
+		})
+		if err != nil {
+			return err
+		}
+		m.codebook, m.grid = codebook, grid
+	}
+}
+
+// growPosition finds the position where new grid line should be inserted.
+// It returns the grid axis (0 for rows, 1 for columns) and the index of the new line along it.
+func (m Map) growPosition(data *mat64.Dense) (int, int, error) {
+	dims := m.grid.size
+	// accumulate the quantization error of every unit
+	bmus, err := m.BMUs(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	unitErr := make([]float64, m.grid.Units())
+	for i, bmu := range bmus {
+		d, err := Distance(m.metric, data.RawRowView(i), m.codebook.RawRowView(bmu))
+		if err != nil {
+			return 0, 0, err
+		}
+		unitErr[bmu] += d
+	}
+	errUnit := 0
+	for unit, e := range unitErr {
+		if e > unitErr[errUnit] {
+			errUnit = unit
+		}
+	}
+	// find the most distant codebook vector among the grid neighbours
+	pos := []int{errUnit % dims[0], errUnit / dims[0]}
+	axis, at, maxDist := 0, 0, -1.0
+	for _, ax := range []int{0, 1} {
+		for _, step := range []int{-1, 1} {
+			neighb := []int{pos[0], pos[1]}
+			neighb[ax] += step
+			if neighb[ax] < 0 || neighb[ax] >= dims[ax] {
+				continue
+			}
+			d, err := Distance(m.metric, m.codebook.RawRowView(errUnit),
+				m.codebook.RawRowView(neighb[1]*dims[0]+neighb[0]))
+			if err != nil {
+				return 0, 0, err
+			}
+			if d > maxDist {
+				maxDist = d
+				axis = ax
+				// the new line is inserted in front of the further of the two units
+				at = pos[ax]
+				if neighb[ax] > at {
+					at = neighb[ax]
+				}
+			}
+		}
+	}
+
+	return axis, at, nil
+}
+
+// insertGridLine inserts a new row (axis 0) or column (axis 1) of units in front of the line
+// at the given index of the grid with dims dimensions. It returns the new codebook whose new
+// vectors are averages of their neighbours along the axis along with the new grid dimensions.
+func insertGridLine(codebook *mat64.Dense, dims []int, axis, at int) (*mat64.Dense, []int) {
+	newDims := []int{dims[0], dims[1]}
+	newDims[axis]++
+	_, cols := codebook.Dims()
+	newCb := mat64.NewDense(newDims[0]*newDims[1], cols, nil)
+	// index returns codebook row of the unit at the given position of the original grid
+	index := func(pos []int) int { return pos[1]*dims[0] + pos[0] }
+	for x := 0; x < newDims[1]; x++ {
+		for y := 0; y < newDims[0]; y++ {
+			row := newCb.RawRowView(x*newDims[0] + y)
+			pos := []int{y, x}
+			switch {
+			case pos[axis] < at:
+				copy(row, codebook.RawRowView(index(pos)))
+			case pos[axis] > at:
+				pos[axis]--
+				copy(row, codebook.RawRowView(index(pos)))
+			default:
+				prev, next := []int{y, x}, []int{y, x}
+				prev[axis] = at - 1
+				prevVec, nextVec := codebook.RawRowView(index(prev)), codebook.RawRowView(index(next))
+				for i := range row {
+					row[i] = (prevVec[i] + nextVec[i]) / 2.0
+				}
+			}
+		}
+	}
+
+	return newCb, newDims
+}
@@ -0,0 +1,80 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertGridLine(t *testing.T) {
+	assert := assert.New(t)
+
+	// 2x2 grid: unit index = x*rows + y
+	codebook := mat64.NewDense(4, 1, []float64{0.0, 2.0, 4.0, 6.0})
+	dims := []int{2, 2}
+	// insert a new row in front of row 1
+	newCb, newDims := insertGridLine(codebook, dims, 0, 1)
+	assert.EqualValues([]int{3, 2}, newDims)
+	expCb := mat64.NewDense(6, 1, []float64{0.0, 1.0, 2.0, 4.0, 5.0, 6.0})
+	assert.True(mat64.Equal(expCb, newCb))
+	// insert a new column in front of column 1
+	newCb, newDims = insertGridLine(codebook, dims, 1, 1)
+	assert.EqualValues([]int{2, 3}, newDims)
+	expCb = mat64.NewDense(6, 1, []float64{0.0, 2.0, 2.0, 4.0, 4.0, 6.0})
+	assert.True(mat64.Equal(expCb, newCb))
+}
+
+func TestGrow(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := &MapConfig{
+		Grid: &GridConfig{
+			Size:   []int{2, 2},
+			Type:   "planar",
+			UShape: "rectangle",
+		},
+		Cb: &CbConfig{
+			Dim:      4,
+			InitFunc: RandInit,
+		},
+	}
+	gc := &GrowConfig{
+		QError:   0.0,
+		MaxUnits: 9,
+		Iters:    50,
+	}
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	// zero target error makes the grid grow until it reaches the maximum number of units
+	err = m.Grow(tSom, gc, dataMx)
+	assert.NoError(err)
+	units := m.Grid().Units()
+	assert.True(units > 4 && units <= gc.MaxUnits)
+	cbRows, _ := m.Codebook().Dims()
+	assert.Equal(units, cbRows)
+	dims := m.Grid().Size()
+	assert.Equal(units, dims[0]*dims[1])
+	// target error which is already met prevents the grid from growing
+	m, err = NewMap(mapCfg, dataMx)
+	assert.NoError(err)
+	gc.QError = 1000.0
+	err = m.Grow(tSom, gc, dataMx)
+	assert.NoError(err)
+	assert.Equal(4, m.Grid().Units())
+	// nil data
+	err = m.Grow(tSom, gc, nil)
+	assert.Error(err)
+	// invalid grow config
+	gc.MaxUnits = 0
+	err = m.Grow(tSom, gc, dataMx)
+	assert.Error(err)
+	gc.MaxUnits = 9
+	// unsupported grid type
+	mapCfg.Grid.Type = "toroid"
+	m, err = NewMap(mapCfg, dataMx)
+	assert.NoError(err)
+	err = m.Grow(tSom, gc, dataMx)
+	assert.Error(err)
+}
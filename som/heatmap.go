@@ -0,0 +1,48 @@
+package som
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gonum/floats"
+)
+
+// HeatmapSVG creates an SVG representation of arbitrary unit values laid out on a planar grid of the given
+// dimensions and unit shape in the same way as UMatrixSVG lays out the U-Matrix, and writes it to writer.
+// i-th item of values holds the value of i-th grid unit, e.g. its hit count, quantization error or cluster size.
+// Units are shaded by their values normalized to the range of values using the color map and other rendering
+// options of c. If c is nil, the default rendering options are used. It fails with error if the number of values
+// is different from the number of grid units, if c is invalid or if the grid coordinates could not be computed.
+func HeatmapSVG(values []float64, dims []int, uShape, title string, writer io.Writer, c *RenderConfig) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	if rows, _ := coords.Dims(); len(values) != rows {
+		return fmt.Errorf("invalid number of unit values: %d, expected: %d", len(values), rows)
+	}
+	if c != nil {
+		if err := validateRenderConfig(c); err != nil {
+			return err
+		}
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return valuesSVG(grid, values, floats.Min(values), floats.Max(values), title, writer, c.style())
+}
+
+// Heatmap renders map units shaded by arbitrary unit values in a given format and writes the output to w.
+// i-th item of values holds the value of i-th map unit. Supported formats are svg and png.
+// It fails with error if the number of values is different from the number of map units.
+func (m Map) Heatmap(w io.Writer, values []float64, format, title string) error {
+	if len(values) != m.grid.Units() {
+		return fmt.Errorf("invalid number of unit values: %d, expected: %d", len(values), m.grid.Units())
+	}
+
+	return m.renderValues(w, values, format, title)
+}
@@ -0,0 +1,52 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeatmapSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	values := []float64{0.0, 5.0, 10.0}
+	var buf bytes.Buffer
+	assert.NoError(HeatmapSVG(values, []int{1, 3}, "rectangle", "Heat", &buf, nil))
+	svg := buf.String()
+	assert.Equal(3, strings.Count(svg, "<polygon "))
+	assert.True(strings.Contains(svg, "<title>Heat</title>"))
+	// values are normalized to their range
+	assert.True(strings.Contains(svg, "fill:rgb(0,0,0);"))
+	assert.True(strings.Contains(svg, "fill:rgb(127,127,127);"))
+	assert.True(strings.Contains(svg, "fill:rgb(255,255,255);"))
+	// rendering options are applied
+	buf.Reset()
+	assert.NoError(HeatmapSVG(values, []int{1, 3}, "rectangle", "Heat", &buf, &RenderConfig{ColorMap: "inverted", Legend: true}))
+	assert.True(strings.Contains(buf.String(), "fill:rgb(255,255,255);stroke:black"))
+	assert.Equal(3, strings.Count(buf.String(), "<svg "))
+	// invalid parameters
+	assert.EqualError(HeatmapSVG(values, []int{2, 2}, "rectangle", "Heat", &buf, nil), "invalid number of unit values: 3, expected: 4")
+	assert.Error(HeatmapSVG(values, []int{1, 3}, "foobar", "Heat", &buf, nil))
+	assert.Error(HeatmapSVG(values, []int{1, 3}, "rectangle", "Heat", &buf, &RenderConfig{ColorMap: "foobar"}))
+}
+
+func TestMapHeatmap(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: mat64.NewDense(3, 1, nil), grid: grid, metric: "euclidean"}
+	values := []float64{0.0, 5.0, 10.0}
+	var buf, expected bytes.Buffer
+	assert.NoError(m.Heatmap(&buf, values, "svg", "Heat"))
+	assert.NoError(HeatmapSVG(values, []int{1, 3}, "rectangle", "Heat", &expected, nil))
+	assert.Equal(expected.String(), buf.String())
+	buf.Reset()
+	assert.NoError(m.Heatmap(&buf, values, "png", "Heat"))
+	assert.True(buf.Len() > 0)
+	assert.Error(m.Heatmap(&buf, values, "bmp", "Heat"))
+	assert.Error(m.Heatmap(&buf, values[:2], "svg", "Heat"))
+}
@@ -0,0 +1,201 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Merge is a single merge of two clusters in a dendrogram.
+// Clusters 0 to n-1 are the clustered vectors, cluster n+i is created by i-th merge.
+type Merge struct {
+	// A and B are IDs of the merged clusters
+	A, B int
+	// Dist is the linkage distance of the merged clusters
+	Dist float64
+	// Size is the number of vectors in the merged cluster
+	Size int
+}
+
+// Dendrogram holds the result of agglomerative hierarchical clustering
+type Dendrogram struct {
+	// Leaves is the number of clustered vectors
+	Leaves int
+	// Merges holds the cluster merges in the order they were made
+	Merges []Merge
+}
+
+// Hierarchy clusters vectors stored in codebook rows using agglomerative hierarchical clustering: starting
+// with single-vector clusters it repeatedly merges the two closest clusters according to c.Linkage.
+// If c.UnitDist is set, only clusters which contain grid neighbours are merged so the clusters are contiguous
+// map regions. Grid units are neighbours in the same way as they are in TopographicError. Merging stops when
+// no more clusters can be merged: with grid constraints on disconnected grids this can leave more than one cluster.
+// It returns error if the configuration is invalid, if codebook is nil or if the unit distance matrix
+// does not match the number of codebook vectors.
+func Hierarchy(codebook *mat64.Dense, c *HierarchyConfig) (*Dendrogram, error) {
+	if err := validateHierarchyConfig(c); err != nil {
+		return nil, err
+	}
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	n, _ := codebook.Dims()
+	if c.UnitDist != nil {
+		if rows, cols := c.UnitDist.Dims(); rows != n || cols != n {
+			return nil, fmt.Errorf("invalid unit distance matrix dimensions: [%d, %d], expected: [%d, %d]", rows, cols, n, n)
+		}
+	}
+	metric := c.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	dist, err := DistanceMx(metric, codebook)
+	if err != nil {
+		return nil, err
+	}
+	// active clusters: their IDs, sizes and which of them are neighbours
+	ids := make([]int, n)
+	sizes := make([]int, n)
+	adj := make([][]bool, n)
+	for i := range ids {
+		ids[i], sizes[i] = i, 1
+		adj[i] = make([]bool, n)
+		for j := range adj[i] {
+			adj[i][j] = c.UnitDist == nil || c.UnitDist.At(i, j) < 1.01*math.Sqrt(2)
+		}
+	}
+	active := make([]bool, n)
+	for i := range active {
+		active[i] = true
+	}
+	d := &Dendrogram{Leaves: n, Merges: []Merge{}}
+	for len(d.Merges) < n-1 {
+		// find the closest pair of mergeable clusters
+		a, b := -1, -1
+		for i := 0; i < n; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && adj[i][j] && (a == -1 || dist.At(i, j) < dist.At(a, b)) {
+					a, b = i, j
+				}
+			}
+		}
+		if a == -1 {
+			break
+		}
+		d.Merges = append(d.Merges, Merge{A: ids[a], B: ids[b], Dist: dist.At(a, b), Size: sizes[a] + sizes[b]})
+		// cluster a becomes the merged cluster, cluster b is removed
+		for k := 0; k < n; k++ {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			lw := linkageDist(c.Linkage, dist.At(a, k), dist.At(b, k), dist.At(a, b), sizes[a], sizes[b], sizes[k])
+			dist.Set(a, k, lw)
+			dist.Set(k, a, lw)
+			adj[a][k] = adj[a][k] || adj[b][k]
+			adj[k][a] = adj[a][k]
+		}
+		ids[a], sizes[a] = n+len(d.Merges)-1, sizes[a]+sizes[b]
+		active[b] = false
+	}
+
+	return d, nil
+}
+
+// Hierarchy clusters the map codebook vectors using agglomerative hierarchical clustering with the given linkage
+// and the map distance metric. If contiguous is true, only clusters which contain neighbouring map units are merged.
+// It fails in the same way as Hierarchy function.
+func (m Map) Hierarchy(linkage string, contiguous bool) (*Dendrogram, error) {
+	c := &HierarchyConfig{Linkage: linkage, Metric: m.metric}
+	if contiguous {
+		uDist, err := m.UnitDist()
+		if err != nil {
+			return nil, err
+		}
+		c.UnitDist = uDist
+	}
+	return Hierarchy(m.codebook, c)
+}
+
+// Cut returns flat clustering of the dendrogram leaves into k clusters: the last merges are undone until
+// there are k clusters left. Cluster IDs are numbered from 0 in the order of their first leaf.
+// It returns error if k is not a positive integer, if it is higher than the number of leaves
+// or if it is lower than the number of clusters left when the merging stopped.
+func (d *Dendrogram) Cut(k int) ([]int, error) {
+	if k <= 0 || k > d.Leaves || k < d.Leaves-len(d.Merges) {
+		return nil, fmt.Errorf("invalid number of clusters: %d", k)
+	}
+	// the leaves and the clusters of the first Leaves-k merges are formed
+	formed := make([]bool, d.Leaves+len(d.Merges))
+	for i := range formed[:2*d.Leaves-k] {
+		formed[i] = true
+	}
+	return d.flatten(formed), nil
+}
+
+// CutDist returns flat clustering of the dendrogram leaves in which the clusters are merged as long as their
+// linkage distance does not exceed dist. Cluster IDs are numbered as in Cut. Merge distances need not grow
+// with the merge order, e.g. under grid constraints, so every merge within dist is applied in merge order
+// unless it merges a cluster which has not been formed because some of its merges exceed dist.
+func (d *Dendrogram) CutDist(dist float64) []int {
+	formed := make([]bool, d.Leaves+len(d.Merges))
+	for i := range formed[:d.Leaves] {
+		formed[i] = true
+	}
+	for i, merge := range d.Merges {
+		formed[d.Leaves+i] = merge.Dist <= dist && formed[merge.A] && formed[merge.B]
+	}
+	return d.flatten(formed)
+}
+
+// flatten applies the merges of the formed clusters and returns cluster IDs of all leaves.
+// formed holds for every dendrogram cluster whether it has been formed, leaves are always formed.
+func (d *Dendrogram) flatten(formed []bool) []int {
+	// parent links from clusters to the clusters they were merged into
+	parent := make([]int, len(formed))
+	for i := range parent {
+		parent[i] = i
+	}
+	for i, merge := range d.Merges {
+		if formed[d.Leaves+i] {
+			parent[merge.A] = d.Leaves + i
+			parent[merge.B] = d.Leaves + i
+		}
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+		return i
+	}
+	clusters := make([]int, d.Leaves)
+	ids := make(map[int]int)
+	for i := range clusters {
+		r := root(i)
+		if _, ok := ids[r]; !ok {
+			ids[r] = len(ids)
+		}
+		clusters[i] = ids[r]
+	}
+	return clusters
+}
+
+// linkageDist computes distance of cluster k to the cluster merged from clusters a and b using
+// Lance-Williams formula for the given linkage. dak, dbk and dab are distances between the clusters
+// and na, nb and nk are the cluster sizes.
+func linkageDist(linkage string, dak, dbk, dab float64, na, nb, nk int) float64 {
+	switch linkage {
+	case "single":
+		return math.Min(dak, dbk)
+	case "complete":
+		return math.Max(dak, dbk)
+	case "average":
+		return (float64(na)*dak + float64(nb)*dbk) / float64(na+nb)
+	}
+	// ward
+	a, b, k := float64(na), float64(nb), float64(nk)
+	return math.Sqrt(((a+k)*dak*dak + (b+k)*dbk*dbk - k*dab*dab) / (a + b + k))
+}
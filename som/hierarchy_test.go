@@ -0,0 +1,90 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHierarchy(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(5, 1, []float64{0.0, 1.0, 5.0, 6.0, 20.0})
+	d, err := Hierarchy(cbook, &HierarchyConfig{Linkage: "single"})
+	assert.NoError(err)
+	assert.Equal(5, d.Leaves)
+	assert.Equal([]Merge{
+		{A: 0, B: 1, Dist: 1.0, Size: 2},
+		{A: 2, B: 3, Dist: 1.0, Size: 2},
+		{A: 5, B: 6, Dist: 4.0, Size: 4},
+		{A: 7, B: 4, Dist: 14.0, Size: 5},
+	}, d.Merges)
+	clusters, err := d.Cut(2)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 0, 1}, clusters)
+	clusters, err = d.Cut(3)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 1, 1, 2}, clusters)
+	assert.Equal([]int{0, 0, 1, 1, 2}, d.CutDist(2.0))
+	assert.Equal([]int{0, 1, 2, 3, 4}, d.CutDist(0.5))
+	// merge distances which do not grow with the merge order
+	inv := &Dendrogram{
+		Leaves: 5,
+		Merges: []Merge{{A: 0, B: 1, Dist: 1.0}, {A: 2, B: 3, Dist: 3.0}, {A: 4, B: 5, Dist: 2.0}, {A: 6, B: 7, Dist: 4.0}},
+	}
+	assert.Equal([]int{0, 0, 1, 2, 0}, inv.CutDist(2.5))
+	assert.Equal([]int{0, 0, 1, 1, 0}, inv.CutDist(3.0))
+	assert.Equal([]int{0, 0, 0, 0, 0}, inv.CutDist(4.0))
+	// clusters merged from clusters which have not been formed are not formed either
+	inv = &Dendrogram{Leaves: 3, Merges: []Merge{{A: 0, B: 1, Dist: 2.0}, {A: 2, B: 3, Dist: 1.0}}}
+	assert.Equal([]int{0, 1, 2}, inv.CutDist(1.5))
+	// linkages
+	for linkage, dist := range map[string]float64{"complete": 6.0, "average": 5.0, "ward": 5.0 * math.Sqrt2} {
+		d, err = Hierarchy(cbook, &HierarchyConfig{Linkage: linkage})
+		assert.NoError(err)
+		assert.InDelta(dist, d.Merges[2].Dist, 1e-9, linkage)
+	}
+	// invalid cuts
+	for _, k := range []int{0, 6} {
+		clusters, err = d.Cut(k)
+		assert.Nil(clusters)
+		assert.Error(err)
+	}
+	// invalid configuration
+	d, err = Hierarchy(cbook, &HierarchyConfig{Linkage: "foo"})
+	assert.Nil(d)
+	assert.EqualError(err, "unsupported linkage: foo")
+	d, err = Hierarchy(cbook, &HierarchyConfig{Linkage: "ward", Metric: "manhattan"})
+	assert.Nil(d)
+	assert.EqualError(err, "ward linkage requires euclidean metric: manhattan")
+	d, err = Hierarchy(cbook, &HierarchyConfig{Linkage: "single", UnitDist: mat64.NewDense(2, 2, nil)})
+	assert.Nil(d)
+	assert.Error(err)
+	d, err = Hierarchy(nil, &HierarchyConfig{Linkage: "single"})
+	assert.Nil(d)
+	assert.Error(err)
+}
+
+func TestMapHierarchy(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 5}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(5, 1, []float64{0.0, 10.0, 1.0, 11.0, 30.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	d, err := m.Hierarchy("single", false)
+	assert.NoError(err)
+	assert.Equal(Merge{A: 0, B: 2, Dist: 1.0, Size: 2}, d.Merges[0])
+	// only grid neighbours are merged
+	d, err = m.Hierarchy("single", true)
+	assert.NoError(err)
+	assert.Equal(Merge{A: 1, B: 2, Dist: 9.0, Size: 2}, d.Merges[0])
+	clusters, err := d.Cut(2)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 0, 1}, clusters)
+}
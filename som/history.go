@@ -0,0 +1,63 @@
+package som
+
+import (
+	"context"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// TrainHistory holds SOM training metrics recorded at the end of every training epoch
+type TrainHistory struct {
+	// QError holds map quantization errors
+	QError []float64
+	// TopoError holds map topographic errors
+	TopoError []float64
+	// Radius holds neighbourhood radii used in the last iteration of every epoch
+	Radius []float64
+	// LRate holds learning rates used in the last iteration of every epoch
+	LRate []float64
+}
+
+// Epochs returns the number of recorded training epochs
+func (h TrainHistory) Epochs() int {
+	return len(h.QError)
+}
+
+// TrainWithHistory runs SOM training in the same way as Train and records quantization error,
+// topographic error, radius and learning rate at the end of every training epoch: every iteration
+// of batch training and every as many iterations of sequential training as there are data rows.
+// It returns the recorded training history or fails with error if the training or any of the
+// map quality measures fails. The history recorded until the failure is returned along with the error.
+func (m *Map) TrainWithHistory(c *TrainConfig, data *mat64.Dense, iters int) (*TrainHistory, error) {
+	h := &TrainHistory{
+		QError:    []float64{},
+		TopoError: []float64{},
+		Radius:    []float64{},
+		LRate:     []float64{},
+	}
+	// unit distances don't change during the training
+	uDistMx, err := m.UnitDist()
+	if err != nil {
+		return h, err
+	}
+	epochEnd := func(epoch, iteration int) (bool, error) {
+		qErr, err := m.QuantError(data)
+		if err != nil {
+			return true, err
+		}
+		tErr, err := topoError(m.metric, data, m.codebook, uDistMx)
+		if err != nil {
+			return true, err
+		}
+		h.QError = append(h.QError, qErr)
+		h.TopoError = append(h.TopoError, tErr)
+		h.Radius = append(h.Radius, c.radius(iteration-1, iters))
+		h.LRate = append(h.LRate, c.lRate(iteration-1, iters))
+		return false, nil
+	}
+	if err := m.train(context.Background(), c, data, iters, epochEnd); err != nil {
+		return h, err
+	}
+
+	return h, nil
+}
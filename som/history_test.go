@@ -0,0 +1,41 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrainWithHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	tc := *tSom
+	tc.Algorithm = "batch"
+	h, err := m.TrainWithHistory(&tc, dataMx, 10)
+	assert.NoError(err)
+	assert.Equal(10, h.Epochs())
+	assert.Len(h.TopoError, 10)
+	assert.Len(h.Radius, 10)
+	assert.Len(h.LRate, 10)
+	// the last epoch metrics match the trained map
+	qErr, err := m.QuantError(dataMx)
+	assert.NoError(err)
+	assert.Equal(qErr, h.QError[9])
+	tErr, err := m.TopoError(dataMx)
+	assert.NoError(err)
+	assert.Equal(tErr, h.TopoError[9])
+	assert.Equal(tc.radius(0, 10), h.Radius[0])
+	assert.Equal(tc.lRate(9, 10), h.LRate[9])
+	// sequential epoch is as long as the data set
+	rows, _ := dataMx.Dims()
+	tc.Algorithm = "seq"
+	h, err = m.TrainWithHistory(&tc, dataMx, 2*rows)
+	assert.NoError(err)
+	assert.Equal(2, h.Epochs())
+	// invalid training parameters
+	h, err = m.TrainWithHistory(&tc, dataMx, 0)
+	assert.Equal(0, h.Epochs())
+	assert.Error(err)
+}
@@ -0,0 +1,34 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// HitMap returns a slice which contains the number of vectors stored in data rows every codebook
+// vector is the Best Match Unit of using the supplied distance metric. i-th slice item holds
+// the hit count of the codebook vector stored in i-th codebook row.
+// It returns error if either data or codebook are nil or if their dimensions are mismatched.
+func HitMap(codebook, data *mat64.Dense, metric string) ([]int, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	units, _, err := MapRows(codebook, data, metric)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := codebook.Dims()
+	hits := make([]int, rows)
+	for _, unit := range units {
+		hits[unit]++
+	}
+
+	return hits, nil
+}
+
+// HitMap returns the number of vectors stored in data rows every map unit is the Best Match Unit of.
+// It returns error if the data dimension and map codebook dimensions are not the same.
+func (m Map) HitMap(data *mat64.Dense) ([]int, error) {
+	return HitMap(m.codebook, data, m.metric)
+}
@@ -0,0 +1,67 @@
+package som
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHitMap(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 100.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 4.0, 6.0, 10.0})
+	hits, err := HitMap(cbook, data, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]int{2, 3, 0}, hits)
+	// nil parameters
+	hits, err = HitMap(nil, data, "euclidean")
+	assert.Nil(hits)
+	assert.Error(err)
+	hits, err = HitMap(cbook, nil, "euclidean")
+	assert.Nil(hits)
+	assert.Error(err)
+	// map hits
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	hits, err = m.HitMap(data)
+	assert.NoError(err)
+	assert.Equal([]int{2, 3, 0}, hits)
+	hits, err = m.HitMap(mat64.NewDense(1, 2, nil))
+	assert.Nil(hits)
+	assert.Error(err)
+}
+
+func TestHitMapSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 100.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 4.0, 6.0, 10.0})
+	var buf bytes.Buffer
+	err := HitMapSVG(cbook, data, []int{1, 3}, "rectangle", "euclidean", "Hits", &buf)
+	assert.NoError(err)
+	svg := buf.String()
+	assert.Equal(3, strings.Count(svg, "<polygon "))
+	// units without hits have no markers
+	assert.Equal(2, strings.Count(svg, "<circle "))
+	// the unit with the most hits has the biggest marker
+	assert.True(strings.Contains(svg, `<circle cx="85" cy="35" r="20" `))
+	assert.Equal([]float64{math.Sqrt(2.0 / 3.0), 1.0, 0.0}, hitMarkers([]int{2, 3, 0}))
+	assert.Equal([]float64{0.0, 0.0}, hitMarkers([]int{0, 0}))
+	// map hits
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var mBuf bytes.Buffer
+	assert.NoError(m.HitsUMatrix(&mBuf, data, "svg", "Hits"))
+	assert.Equal(svg, mBuf.String())
+	// invalid parameters
+	assert.Error(HitMapSVG(cbook, nil, []int{1, 3}, "rectangle", "euclidean", "Hits", &buf))
+	assert.Error(m.HitsUMatrix(&mBuf, mat64.NewDense(1, 2, nil), "svg", "Hits"))
+	assert.Error(m.HitsUMatrix(&mBuf, data, "bmp", "Hits"))
+}
@@ -0,0 +1,88 @@
+package som
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// htmlHeader is the header of the HTML document which embeds the map SVG
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; }
+polygon:hover, circle:hover { stroke: red !important; stroke-width: 3 !important; }
+</style>
+</head>
+<body>
+`
+
+// htmlFooter is the footer of the HTML document which embeds the map SVG
+const htmlFooter = `
+</body>
+</html>
+`
+
+// UMatrixHTML creates a self-contained HTML document with the U-Matrix of the given codebook and writes it to writer.
+// Hovering over a unit shows its index, average distance to its neighbours, hit count and codebook vector.
+// It accepts the same parameters as UMatrixSVG and data - the data set whose hits are shown. If data is nil, hits are not shown.
+// It fails with error if the U-Matrix or the hits could not be computed or if the grid coordinates could not be computed.
+func UMatrixHTML(codebook, data *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return umatrixHTML(codebook, data, grid, metric, title, writer, unitStyle{classes: classes})
+}
+
+// umatrixHTML creates a self-contained HTML document with the U-Matrix of the given codebook and grid.
+func umatrixHTML(codebook, data *mat64.Dense, grid *Grid, metric, title string, writer io.Writer, s unitStyle) error {
+	umatrix, _, _, err := umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return err
+	}
+	var hits []int
+	if data != nil {
+		if hits, err = HitMap(codebook, data, metric); err != nil {
+			return err
+		}
+	}
+	s.tooltips = make([]string, len(umatrix))
+	for unit := range umatrix {
+		tooltip := fmt.Sprintf("unit: %d\ndistance: %.4g", unit, umatrix[unit])
+		if hits != nil {
+			tooltip += fmt.Sprintf("\nhits: %d", hits[unit])
+		}
+		vec := make([]string, 0, len(codebook.RawRowView(unit)))
+		for _, v := range codebook.RawRowView(unit) {
+			vec = append(vec, fmt.Sprintf("%.4g", v))
+		}
+		s.tooltips[unit] = tooltip + fmt.Sprintf("\ncodebook: [%s]", strings.Join(vec, ", "))
+	}
+
+	var escTitle bytes.Buffer
+	xml.EscapeText(&escTitle, []byte(title))
+	if _, err := fmt.Fprintf(writer, htmlHeader, escTitle.String()); err != nil {
+		return err
+	}
+	if err := umatrixSVG(codebook, grid, metric, title, writer, s); err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, htmlFooter)
+
+	return err
+}
@@ -0,0 +1,44 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUMatrixHTML(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2, []float64{0.0, 0.0, 1.0, 0.0, 3.0, 0.5})
+	data := mat64.NewDense(3, 2, []float64{0.1, 0.0, 0.2, 0.1, 2.9, 0.5})
+	var buf bytes.Buffer
+	err := UMatrixHTML(cbook, data, []int{1, 3}, "rectangle", "euclidean", "Iris & co", &buf, make(map[int]int))
+	assert.NoError(err)
+	html := buf.String()
+	assert.True(strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.True(strings.Contains(html, "<title>Iris &amp; co</title>"))
+	assert.True(strings.HasSuffix(html, "</html>\n"))
+	// every unit has its tooltip
+	assert.Equal(3, strings.Count(html, "<polygon "))
+	assert.Equal(5, strings.Count(html, "<title>"))
+	assert.True(strings.Contains(html, "<title>unit: 0&#xA;distance: 1&#xA;hits: 2&#xA;codebook: [0, 0]</title>"))
+	assert.True(strings.Contains(html, "<title>unit: 2&#xA;distance: 2.062&#xA;hits: 1&#xA;codebook: [3, 0.5]</title>"))
+	// hits are not shown without data
+	buf.Reset()
+	assert.NoError(UMatrixHTML(cbook, nil, []int{1, 3}, "rectangle", "euclidean", "Iris", &buf, make(map[int]int)))
+	assert.False(strings.Contains(buf.String(), "hits:"))
+	// map html format
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var mBuf bytes.Buffer
+	buf.Reset()
+	assert.NoError(UMatrixHTML(cbook, data, []int{1, 3}, "rectangle", "euclidean", "Iris", &buf, make(map[int]int)))
+	assert.NoError(m.UMatrix(&mBuf, data, nil, "html", "Iris"))
+	assert.Equal(buf.String(), mBuf.String())
+	// mismatched data
+	assert.Error(UMatrixHTML(cbook, mat64.NewDense(1, 3, nil), []int{1, 3}, "rectangle", "euclidean", "Iris", &buf, make(map[int]int)))
+}
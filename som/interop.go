@@ -0,0 +1,324 @@
+package som
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// npyMagic marks the beginning of NumPy .npy files
+const npyMagic = "\x93NUMPY"
+
+// npyHeader matches the array description of .npy header
+var npyHeader = regexp.MustCompile(`'descr':\s*'([<>|]f[48])'.*'fortran_order':\s*(True|False).*'shape':\s*\(([0-9,\s]*)\)`)
+
+// foreignTopologies maps topologies of MiniSom and R kohonen maps to unit shapes
+var foreignTopologies = map[string]string{
+	"rectangular": "rectangle",
+	"hexagonal":   "hexagon",
+}
+
+// MiniSomMeta holds metadata of MiniSom map stored along with its .npy weights.
+// Its keys are the names of MiniSom constructor parameters, so the map can be
+// recreated in Python by MiniSom(**meta) and its weights loaded by numpy.load.
+type MiniSomMeta struct {
+	// X and Y are the numbers of map grid columns and rows
+	X int `json:"x"`
+	Y int `json:"y"`
+	// InputLen is the codebook vector dimension
+	InputLen int `json:"input_len"`
+	// Topology is the map topology: rectangular or hexagonal
+	Topology string `json:"topology"`
+	// ActivationDistance is the distance metric: euclidean, cosine, manhattan or chebyshev
+	ActivationDistance string `json:"activation_distance"`
+}
+
+// SaveMiniSom writes the codebook of the map to weights as NumPy .npy array of shape (x, y, input_len) used by
+// MiniSom, where weights[i, j] is the codebook vector of the unit in grid column i and row j, and writes its
+// metadata as JSON to meta unless meta is nil. It fails with error if the map grid is not 2D planar grid of
+// hexagons or rectangles, the map metric is not supported by MiniSom or if the writes fail.
+func (m Map) SaveMiniSom(weights, meta io.Writer) error {
+	topology, err := m.foreignTopology()
+	if err != nil {
+		return err
+	}
+	switch m.metric {
+	case "euclidean", "cosine", "manhattan", "chebyshev":
+	default:
+		return fmt.Errorf("unsupported MiniSom distance metric: %s", m.metric)
+	}
+	rows, cols := m.grid.size[0], m.grid.size[1]
+	_, dim := m.codebook.Dims()
+	// map units are stored by grid columns which is C order of (x, y, input_len) array
+	if err := writeNpy(weights, []int{cols, rows, dim}, m.codebook); err != nil {
+		return err
+	}
+	if meta == nil {
+		return nil
+	}
+
+	return json.NewEncoder(meta).Encode(MiniSomMeta{
+		X:                  cols,
+		Y:                  rows,
+		InputLen:           dim,
+		Topology:           topology,
+		ActivationDistance: m.metric,
+	})
+}
+
+// LoadMiniSom reads the map saved by MiniSom as NumPy .npy weights array of shape (x, y, input_len)
+// and its JSON metadata from meta. If meta is nil, the map has rectangular topology and euclidean metric.
+// It fails with error if the weights or metadata could not be read or if they don't match.
+func LoadMiniSom(weights, meta io.Reader) (*Map, error) {
+	shape, data, err := readNpy(weights)
+	if err != nil {
+		return nil, err
+	}
+	if len(shape) != 3 {
+		return nil, fmt.Errorf("invalid MiniSom weights shape: %v", shape)
+	}
+	info := MiniSomMeta{X: shape[0], Y: shape[1], InputLen: shape[2], Topology: "rectangular", ActivationDistance: "euclidean"}
+	if meta != nil {
+		if err := json.NewDecoder(meta).Decode(&info); err != nil {
+			return nil, err
+		}
+		if info.X != shape[0] || info.Y != shape[1] || info.InputLen != shape[2] {
+			return nil, fmt.Errorf("invalid MiniSom weights shape: %v, expected: [%d %d %d]", shape, info.X, info.Y, info.InputLen)
+		}
+	}
+	if err := validateMetric(info.ActivationDistance); err != nil {
+		return nil, err
+	}
+
+	return foreignMap(info.Y, info.X, info.Topology, info.ActivationDistance, func(x, y int) []float64 {
+		i := (x*info.Y + y) * info.InputLen
+		return data[i : i+info.InputLen]
+	})
+}
+
+// SaveKohonen writes the codebook of the map to w as CSV codes matrix of R kohonen map whose units
+// are ordered by grid rows and whose columns are named V1, V2, ... The codes can be read into kohonen
+// map of somgrid(xdim, ydim, topo) by read.csv. It fails with error if the map grid is not 2D planar
+// grid of hexagons or rectangles or if the write to w fails.
+func (m Map) SaveKohonen(w io.Writer) error {
+	if _, err := m.foreignTopology(); err != nil {
+		return err
+	}
+	rows, cols := m.grid.size[0], m.grid.size[1]
+	_, dim := m.codebook.Dims()
+	cw := csv.NewWriter(w)
+	record := make([]string, dim)
+	for i := range record {
+		record[i] = fmt.Sprintf("V%d", i+1)
+	}
+	cw.Write(record)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			for i, v := range m.codebook.RawRowView(x*rows + y) {
+				record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+			}
+			cw.Write(record)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// LoadKohonen reads CSV codes matrix of R kohonen map of somgrid(xdim, ydim, topo) from r, e.g. written by
+// write.csv(som$codes[[1]], row.names = FALSE). The first record is skipped if it is not numeric.
+// The map uses euclidean metric. It fails with error if the codes could not be read or if they don't match the grid.
+func LoadKohonen(r io.Reader, xdim, ydim int, topo string) (*Map, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	// header record holds column names
+	if len(records) > 0 && len(records[0]) > 0 {
+		if _, err := strconv.ParseFloat(records[0][0], 64); err != nil {
+			records = records[1:]
+		}
+	}
+	if xdim <= 0 || ydim <= 0 || len(records) != xdim*ydim {
+		return nil, fmt.Errorf("invalid number of kohonen codes: %d, expected: %d", len(records), xdim*ydim)
+	}
+	codes := make([][]float64, len(records))
+	for i, record := range records {
+		codes[i] = make([]float64, len(record))
+		for j, field := range record {
+			if codes[i][j], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := denseRows(codes); err != nil {
+		return nil, err
+	}
+
+	return foreignMap(ydim, xdim, topo, "euclidean", func(x, y int) []float64 {
+		return codes[y*xdim+x]
+	})
+}
+
+// foreignTopology returns MiniSom and R kohonen topology of the map grid.
+// It fails with error if the map grid is not 2D planar grid of hexagons or rectangles.
+func (m Map) foreignTopology() (string, error) {
+	if m.grid.gtype == "planar" && len(m.grid.size) == 2 {
+		for topology, shape := range foreignTopologies {
+			if shape == m.grid.ushape {
+				return topology, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported grid: %s %s %v", m.grid.gtype, m.grid.ushape, m.grid.size)
+}
+
+// foreignMap returns the map of 2D planar grid of the given topology whose codebook
+// vector of the unit in column x and row y is returned by vec
+func foreignMap(rows, cols int, topology, metric string, vec func(x, y int) []float64) (*Map, error) {
+	uShape, ok := foreignTopologies[topology]
+	if !ok {
+		return nil, fmt.Errorf("unsupported topology: %s", topology)
+	}
+	grid, err := NewGrid(&GridConfig{Size: []int{rows, cols}, Type: "planar", UShape: uShape})
+	if err != nil {
+		return nil, err
+	}
+	dim := len(vec(0, 0))
+	if dim == 0 {
+		return nil, fmt.Errorf("invalid codebook vector dimension: %d", dim)
+	}
+	codebook := mat64.NewDense(rows*cols, dim, nil)
+	for x := 0; x < cols; x++ {
+		for y := 0; y < rows; y++ {
+			codebook.SetRow(x*rows+y, vec(x, y))
+		}
+	}
+
+	return &Map{
+		codebook: codebook,
+		grid:     grid,
+		metric:   metric,
+	}, nil
+}
+
+// writeNpy writes the values of mx as NumPy .npy version 1.0 array of little endian float64 values
+// of the given shape in C order to w
+func writeNpy(w io.Writer, shape []int, mx *mat64.Dense) error {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s), }", strings.Join(dims, ", "))
+	// the header is padded by spaces and terminated by new line so that the data are 64 bytes aligned
+	pad := 64 - (len(npyMagic)+4+len(header)+1)%64
+	header += strings.Repeat(" ", pad%64) + "\n"
+
+	bw := bufio.NewWriter(w)
+	var buf [8]byte
+	bw.WriteString(npyMagic)
+	bw.Write([]byte{1, 0})
+	binary.LittleEndian.PutUint16(buf[:2], uint16(len(header)))
+	bw.Write(buf[:2])
+	bw.WriteString(header)
+	rows, _ := mx.Dims()
+	for i := 0; i < rows; i++ {
+		for _, v := range mx.RawRowView(i) {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			bw.Write(buf[:])
+		}
+	}
+	return bw.Flush()
+}
+
+// readNpy reads NumPy .npy array of float32 or float64 values stored in C order from r.
+// It returns the array shape and its values or fails with error if the array could not be read.
+func readNpy(r io.Reader) ([]int, []float64, error) {
+	br := bufio.NewReader(r)
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, nil, err
+	}
+	if string(prefix[:len(npyMagic)]) != npyMagic {
+		return nil, nil, fmt.Errorf("invalid npy array")
+	}
+	// version 1.0 header length is 16 bit integer, later versions use 32 bit integer
+	var size int
+	switch prefix[len(npyMagic)] {
+	case 1:
+		var buf [2]byte
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			return nil, nil, err
+		}
+		size = int(binary.LittleEndian.Uint16(buf[:]))
+	case 2, 3:
+		var buf [4]byte
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			return nil, nil, err
+		}
+		size = int(binary.LittleEndian.Uint32(buf[:]))
+		if size > maxBinaryHeader {
+			return nil, nil, fmt.Errorf("invalid npy header size: %d", size)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported npy version: %d", prefix[len(npyMagic)])
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, nil, err
+	}
+	match := npyHeader.FindStringSubmatch(string(header))
+	if match == nil {
+		return nil, nil, fmt.Errorf("invalid npy header: %s", header)
+	}
+	if match[2] == "True" {
+		return nil, nil, fmt.Errorf("unsupported npy fortran order")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if match[1][0] == '>' {
+		order = binary.BigEndian
+	}
+	width := int(match[1][2] - '0')
+	shape := []int{}
+	n := 1
+	for _, d := range strings.Split(match[3], ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		v, err := strconv.Atoi(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		shape = append(shape, v)
+		n *= v
+	}
+	// the values are read in chunks so that corrupted shapes don't allocate memory which is never filled
+	data := make([]float64, 0, binaryChunk)
+	chunk := make([]byte, width*binaryChunk)
+	for len(data) < n {
+		k := n - len(data)
+		if k > binaryChunk {
+			k = binaryChunk
+		}
+		if _, err := io.ReadFull(br, chunk[:width*k]); err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < k; i++ {
+			if width == 4 {
+				data = append(data, float64(math.Float32frombits(order.Uint32(chunk[4*i:]))))
+			} else {
+				data = append(data, math.Float64frombits(order.Uint64(chunk[8*i:])))
+			}
+		}
+	}
+
+	return shape, data, nil
+}
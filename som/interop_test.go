@@ -0,0 +1,135 @@
+package som
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiniSom(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 3}, Type: "planar", UShape: "hexagon"})
+	assert.NoError(err)
+	m := Map{codebook: mat64.NewDense(6, 2, []float64{0, 0.5, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 5.5}), grid: grid, metric: "cosine"}
+	var weights, meta bytes.Buffer
+	assert.NoError(m.SaveMiniSom(&weights, &meta))
+	// npy header is 64 bytes aligned and describes (x, y, input_len) array
+	data := weights.Bytes()
+	assert.Equal(npyMagic+"\x01\x00", string(data[:8]))
+	size := int(binary.LittleEndian.Uint16(data[8:10]))
+	assert.Equal(0, (10+size)%64)
+	assert.Contains(string(data[10:10+size]), "'shape': (3, 2, 2), }")
+	assert.Equal(10+size+6*2*8, len(data))
+	var info MiniSomMeta
+	assert.NoError(json.Unmarshal(meta.Bytes(), &info))
+	assert.Equal(MiniSomMeta{X: 3, Y: 2, InputLen: 2, Topology: "hexagonal", ActivationDistance: "cosine"}, info)
+	// the map can be loaded back
+	loaded, err := LoadMiniSom(bytes.NewReader(data), bytes.NewReader(meta.Bytes()))
+	assert.NoError(err)
+	assert.True(mat64.Equal(m.codebook, loaded.codebook))
+	assert.Equal(m.grid.size, loaded.grid.size)
+	assert.Equal("hexagon", loaded.grid.ushape)
+	assert.Equal("cosine", loaded.metric)
+	// metadata are optional
+	assert.NoError(m.SaveMiniSom(&weights, nil))
+	loaded, err = LoadMiniSom(bytes.NewReader(data), nil)
+	assert.NoError(err)
+	assert.Equal("rectangle", loaded.grid.ushape)
+	assert.Equal("euclidean", loaded.metric)
+	// unsupported maps
+	m.metric = "canberra"
+	assert.Error(m.SaveMiniSom(&weights, &meta))
+	m.metric = "euclidean"
+	m.grid, _ = NewGrid(&GridConfig{Size: []int{2, 3}, Type: "planar", UShape: "triangle"})
+	assert.Error(m.SaveMiniSom(&weights, &meta))
+	assert.Error(m.SaveMiniSom(failingWriter{}, nil))
+	// invalid weights and metadata
+	testCases := []struct {
+		weights []byte
+		meta    string
+	}{
+		{[]byte{}, ""},
+		{[]byte("\x93NUMPX\x01\x00"), ""},
+		{append([]byte(npyMagic+"\x04\x00"), data[8:]...), ""},
+		{data[:len(data)-1], ""},
+		{data[:20], ""},
+		{data, `{"x":2,"y":3,"input_len":2}`},
+		{data, `{"x":3,"y":2,"input_len":2,"topology":"foo"}`},
+		{data, `{"x":3,"y":2,"input_len":2,"activation_distance":"foo"}`},
+		{data, `{`},
+		{npyArray("{'descr': '<i8', 'fortran_order': False, 'shape': (1, 1, 1), }", 8), ""},
+		{npyArray("{'descr': '<f8', 'fortran_order': True, 'shape': (1, 1, 1), }", 8), ""},
+		{npyArray("{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1), }", 8), ""},
+		{npyArray("{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1, 0), }", 0), ""},
+	}
+	for i, tc := range testCases {
+		var metaReader *strings.Reader
+		if tc.meta != "" {
+			metaReader = strings.NewReader(tc.meta)
+			_, err = LoadMiniSom(bytes.NewReader(tc.weights), metaReader)
+		} else {
+			_, err = LoadMiniSom(bytes.NewReader(tc.weights), nil)
+		}
+		assert.Error(err, "case %d", i)
+	}
+	// float32 and big endian arrays
+	f32 := npyArray("{'descr': '>f4', 'fortran_order': False, 'shape': (2, 2, 1), }", 0)
+	f32 = append(f32, 0x3f, 0xc0, 0, 0, 0x40, 0, 0, 0, 0x3f, 0x80, 0, 0, 0, 0, 0, 0)
+	loaded, err = LoadMiniSom(bytes.NewReader(f32), nil)
+	assert.NoError(err)
+	assert.Equal([]float64{1.5, 2, 1, 0}, loaded.codebook.RawMatrix().Data)
+}
+
+// npyArray returns npy version 1.0 array with the given header followed by size zero bytes
+func npyArray(header string, size int) []byte {
+	data := []byte(npyMagic + "\x01\x00")
+	data = append(data, byte(len(header)), 0)
+	data = append(data, header...)
+	return append(data, make([]byte, size)...)
+}
+
+func TestKohonen(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := Map{codebook: mat64.NewDense(6, 2, []float64{0, 0.5, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 5.5}), grid: grid, metric: "euclidean"}
+	var buf bytes.Buffer
+	assert.NoError(m.SaveKohonen(&buf))
+	// kohonen units are ordered by grid rows
+	assert.Equal("V1,V2\n0,0.5\n2,2.5\n4,4.5\n1,1.5\n3,3.5\n5,5.5\n", buf.String())
+	loaded, err := LoadKohonen(&buf, 3, 2, "rectangular")
+	assert.NoError(err)
+	assert.True(mat64.Equal(m.codebook, loaded.codebook))
+	assert.Equal(m.grid.size, loaded.grid.size)
+	// header is optional
+	loaded, err = LoadKohonen(strings.NewReader("1,2\n3,4\n"), 1, 2, "hexagonal")
+	assert.NoError(err)
+	assert.Equal("hexagon", loaded.grid.ushape)
+	assert.Equal([]float64{1, 2, 3, 4}, loaded.codebook.RawMatrix().Data)
+	// invalid parameters
+	m.grid, _ = NewGrid(&GridConfig{Size: []int{6}, Type: "planar", UShape: "rectangle"})
+	assert.Error(m.SaveKohonen(&buf))
+	testCases := []struct {
+		codes      string
+		xdim, ydim int
+		topo       string
+	}{
+		{"1,2\n3,4\n", 2, 2, "rectangular"},
+		{"1,2\n3,4\n", 0, 2, "rectangular"},
+		{"1,2\n3,4\n", 1, 2, "toroid"},
+		{"1,2\n3,x\n", 1, 2, "rectangular"},
+		{"1,2\n3\n", 1, 2, "rectangular"},
+		{"V1\n\"\n", 1, 1, "rectangular"},
+	}
+	for _, tc := range testCases {
+		_, err := LoadKohonen(strings.NewReader(tc.codes), tc.xdim, tc.ydim, tc.topo)
+		assert.Error(err, tc.codes)
+	}
+}
@@ -0,0 +1,107 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// KaskiLagusError computes Kaski-Lagus error of codebook for the supplied data set. For every data sample
+// it adds the distance of the sample to its BMU and the length of the shortest path from the BMU to
+// the second BMU which passes through neighbouring grid units only. The path length is the sum of distances
+// between codebook vectors of the consecutive units on the path. The distances are computed using the supplied metric.
+// Grid units are neighbours in the same way as they are in TopographicError. It returns the average error of all samples.
+// It returns error if either data, codebook or gridCoords are nil or if their dimensions are mismatched.
+// When the error is returned, Kaski-Lagus error is set to -1.0
+func KaskiLagusError(codebook, data, gridCoords *mat64.Dense, metric string) (float64, error) {
+	if data == nil {
+		return -1.0, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if codebook == nil {
+		return -1.0, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if gridCoords == nil {
+		return -1.0, fmt.Errorf("invalid grid supplied: %v", gridCoords)
+	}
+	if cRows, _ := codebook.Dims(); cRows != gridCoords.RawMatrix().Rows {
+		return -1.0, fmt.Errorf("Grid and codebook dimension mismatch")
+	}
+	// unit distance matrix -- no need to check for error
+	uDistMx, _ := DistanceMx("euclidean", gridCoords)
+
+	return kaskiLagus(metric, data, codebook, uDistMx)
+}
+
+// KaskiLagusError computes Kaski-Lagus error of the map for the supplied data set using the map distance metric.
+// It returns a single number or fails with error if the error could not be computed
+func (m Map) KaskiLagusError(data *mat64.Dense) (float64, error) {
+	if data == nil {
+		return -1.0, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	uDistMx, err := m.UnitDist()
+	if err != nil {
+		return -1.0, err
+	}
+	return kaskiLagus(m.metric, data, m.codebook, uDistMx)
+}
+
+// kaskiLagus computes Kaski-Lagus error for given data set, codebook and grid unit distance matrix
+func kaskiLagus(metric string, data, codebook, uDistMx *mat64.Dense) (float64, error) {
+	cDistMx, err := DistanceMx(metric, codebook)
+	if err != nil {
+		return -1.0, err
+	}
+	// shortest path lengths from the BMUs found so far
+	paths := make(map[int][]float64)
+	var kl float64
+	rows, _ := data.Dims()
+	for i := 0; i < rows; i++ {
+		units, dists, err := KBMU(codebook, data.RowView(i), 2, metric)
+		if err != nil {
+			return -1.0, err
+		}
+		path, ok := paths[units[0]]
+		if !ok {
+			path = mapPaths(units[0], cDistMx, uDistMx)
+			paths[units[0]] = path
+		}
+		kl += dists[0] + path[units[1]]
+	}
+
+	return kl / float64(rows), nil
+}
+
+// mapPaths returns lengths of the shortest paths from unit src to all map units which pass through
+// neighbouring units only. Path length is the sum of codebook distances cDistMx of the consecutive units.
+func mapPaths(src int, cDistMx, uDistMx *mat64.Dense) []float64 {
+	units, _ := cDistMx.Dims()
+	paths := make([]float64, units)
+	done := make([]bool, units)
+	for j := range paths {
+		paths[j] = math.Inf(1)
+	}
+	paths[src] = 0.0
+	for {
+		// pick the closest unvisited unit
+		u := -1
+		for j := range paths {
+			if !done[j] && !math.IsInf(paths[j], 1) && (u == -1 || paths[j] < paths[u]) {
+				u = j
+			}
+		}
+		if u == -1 {
+			return paths
+		}
+		done[u] = true
+		for j := range paths {
+			// 1.01*math.Sqrt(2) is the same neighbourhood used by topographic error
+			if done[j] || uDistMx.At(u, j) >= 1.01*math.Sqrt(2) {
+				continue
+			}
+			if d := paths[u] + cDistMx.At(u, j); d < paths[j] {
+				paths[j] = d
+			}
+		}
+	}
+}
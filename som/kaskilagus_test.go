@@ -0,0 +1,46 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKaskiLagusError(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := GridCoords("rectangle", []int{1, 3})
+	assert.NoError(err)
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 10.0, 1.0})
+	data := mat64.NewDense(2, 1, []float64{0.4, 9.0})
+	// the first sample path goes through the middle unit: 0.4 + 10 + 9
+	// the second sample BMUs are neighbours: 1 + 9
+	kl, err := KaskiLagusError(cbook, data, grid, "euclidean")
+	assert.NoError(err)
+	assert.InDelta((19.4+10.0)/2.0, kl, 1e-9)
+	// mismatched codebook and grid
+	kl, err = KaskiLagusError(mat64.NewDense(2, 1, nil), data, grid, "euclidean")
+	assert.EqualError(err, "Grid and codebook dimension mismatch")
+	assert.Equal(-1.0, kl)
+	// nil parameters
+	kl, err = KaskiLagusError(nil, data, grid, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, kl)
+	kl, err = KaskiLagusError(cbook, nil, grid, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, kl)
+	kl, err = KaskiLagusError(cbook, data, nil, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, kl)
+	// map error
+	g, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: g, metric: "euclidean"}
+	kl, err = m.KaskiLagusError(data)
+	assert.NoError(err)
+	assert.InDelta((19.4+10.0)/2.0, kl, 1e-9)
+	kl, err = m.KaskiLagusError(mat64.NewDense(1, 2, nil))
+	assert.Error(err)
+	assert.Equal(-1.0, kl)
+}
@@ -0,0 +1,108 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// KMeans clusters vectors stored in codebook rows using k-means algorithm seeded by k-means++.
+// It returns a slice which contains cluster IDs of all codebook vectors and a matrix which contains
+// the cluster centroids stored row by row. The clustering stops when the cluster assignments don't change
+// or after c.Iters iterations. Clusters which lose all their vectors keep their previous centroids.
+// It returns error if the configuration is invalid, if codebook is nil or if c.K is higher than the number of codebook vectors.
+func KMeans(codebook *mat64.Dense, c *KMeansConfig) ([]int, *mat64.Dense, error) {
+	if err := validateKMeansConfig(c); err != nil {
+		return nil, nil, err
+	}
+	if codebook == nil {
+		return nil, nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	rows, cols := codebook.Dims()
+	if c.K > rows {
+		return nil, nil, fmt.Errorf("invalid number of clusters: %d", c.K)
+	}
+	r := rand.New(randSource(c.Source))
+	centroids := kMeansSeeds(codebook, c.K, r)
+	clusters := make([]int, rows)
+	for i := range clusters {
+		clusters[i] = -1
+	}
+	for iter := 0; iter < c.Iters; iter++ {
+		changed := false
+		for i := range clusters {
+			// no need to check for error: codebook and centroids have the same dimension
+			cluster, _ := ClosestVec("euclidean", codebook.RawRowView(i), centroids)
+			if cluster != clusters[i] {
+				clusters[i] = cluster
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		// move centroids to the mean of their vectors
+		sums := mat64.NewDense(c.K, cols, nil)
+		counts := make([]int, c.K)
+		for i, cluster := range clusters {
+			counts[cluster]++
+			sum := sums.RawRowView(cluster)
+			for j, val := range codebook.RawRowView(i) {
+				sum[j] += val
+			}
+		}
+		for k, count := range counts {
+			if count == 0 {
+				continue
+			}
+			centroid := centroids.RawRowView(k)
+			for j, sum := range sums.RawRowView(k) {
+				centroid[j] = sum / float64(count)
+			}
+		}
+	}
+
+	return clusters, centroids, nil
+}
+
+// KMeans clusters the map codebook vectors using k-means algorithm and returns cluster IDs of all map units.
+// It fails in the same way as KMeans function.
+func (m Map) KMeans(c *KMeansConfig) ([]int, error) {
+	clusters, _, err := KMeans(m.codebook, c)
+	return clusters, err
+}
+
+// kMeansSeeds picks k initial centroids from vectors stored in data rows using k-means++ seeding:
+// every next centroid is picked with probability proportional to the squared distance to the closest centroid picked so far
+func kMeansSeeds(data *mat64.Dense, k int, r *rand.Rand) *mat64.Dense {
+	rows, cols := data.Dims()
+	seeds := mat64.NewDense(k, cols, nil)
+	seeds.SetRow(0, data.RawRowView(r.Intn(rows)))
+	dists := make([]float64, rows)
+	for i := range dists {
+		dists[i] = math.Inf(1)
+	}
+	for s := 1; s < k; s++ {
+		var total float64
+		for i := range dists {
+			d := sqDist(data.RawRowView(i), seeds.RawRowView(s-1))
+			dists[i] = math.Min(dists[i], d)
+			total += dists[i]
+		}
+		// all remaining vectors are identical to the seeds
+		next := r.Intn(rows)
+		if total > 0 {
+			target := r.Float64() * total
+			for i, d := range dists {
+				if target -= d; target < 0 {
+					next = i
+					break
+				}
+			}
+		}
+		seeds.SetRow(s, data.RawRowView(next))
+	}
+	return seeds
+}
@@ -0,0 +1,80 @@
+package som
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKMeans(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(6, 2, []float64{
+		0.0, 0.0,
+		0.1, 0.0,
+		0.0, 0.1,
+		10.0, 10.0,
+		10.1, 10.0,
+		10.0, 10.1,
+	})
+	c := &KMeansConfig{K: 2, Iters: 10, Source: rand.NewSource(1)}
+	clusters, centroids, err := KMeans(cbook, c)
+	assert.NoError(err)
+	assert.Len(clusters, 6)
+	assert.Equal(clusters[0], clusters[1])
+	assert.Equal(clusters[0], clusters[2])
+	assert.Equal(clusters[3], clusters[4])
+	assert.Equal(clusters[3], clusters[5])
+	assert.NotEqual(clusters[0], clusters[3])
+	assert.InDeltaSlice([]float64{0.1 / 3.0, 0.1 / 3.0}, centroids.RawRowView(clusters[0]), 1e-9)
+	assert.InDeltaSlice([]float64{10.0 + 0.1/3.0, 10.0 + 0.1/3.0}, centroids.RawRowView(clusters[3]), 1e-9)
+	// same seed gives the same clusters
+	c.Source = rand.NewSource(1)
+	clusters2, _, err := KMeans(cbook, c)
+	assert.NoError(err)
+	assert.Equal(clusters, clusters2)
+	// invalid parameters
+	for _, c := range []*KMeansConfig{{K: 0, Iters: 1}, {K: 7, Iters: 1}, {K: 2, Iters: 0}} {
+		clusters, centroids, err = KMeans(cbook, c)
+		assert.Nil(clusters)
+		assert.Nil(centroids)
+		assert.Error(err)
+	}
+	clusters, centroids, err = KMeans(nil, &KMeansConfig{K: 2, Iters: 1})
+	assert.Nil(clusters)
+	assert.Nil(centroids)
+	assert.Error(err)
+}
+
+func TestMapKMeansClusterUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 1}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(2, 2, []float64{0.0, 0.0, 1.0, 1.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	clusters, err := m.KMeans(&KMeansConfig{K: 2, Iters: 5, Source: rand.NewSource(1)})
+	assert.NoError(err)
+	assert.NotEqual(clusters[0], clusters[1])
+	var buf bytes.Buffer
+	assert.NoError(m.ClusterUMatrix(&buf, []int{0, 1}, "svg", "Done"))
+	// units are colored by their clusters and separated by the cluster boundary
+	var expected bytes.Buffer
+	assert.NoError(ClusterUMatrixSVG(m.codebook, []int{2, 1}, "rectangle", "euclidean", "Done", &expected, []int{0, 1}))
+	assert.Equal(expected.String(), buf.String())
+	assert.True(strings.Contains(buf.String(), "<polygon "))
+	assert.Equal(1, strings.Count(buf.String(), "<line "))
+	var pngBuf bytes.Buffer
+	assert.NoError(m.ClusterUMatrix(&pngBuf, []int{0, 1}, "png", "Done"))
+	assert.True(pngBuf.Len() > 0)
+	// invalid parameters
+	assert.Error(m.ClusterUMatrix(&buf, []int{0}, "svg", "Done"))
+	assert.Error(m.ClusterUMatrix(&buf, []int{0, 1}, "bmp", "Done"))
+}
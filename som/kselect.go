@@ -0,0 +1,120 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/gonum/stat"
+)
+
+// SelectK evaluates k-means clustering of vectors stored in codebook rows for every cluster count from c.MinK
+// to c.MaxK and returns the recommended cluster count along with the score of every evaluated count.
+// The elbow method scores the counts by within-cluster sum of squares and recommends the count whose score
+// lies farthest from the line connecting the scores of the first and the last count.
+// The gap method scores the counts by gap statistic, i.e. the difference between the expected log within-cluster
+// sum of squares of c.Refs data sets drawn uniformly from the codebook bounding box and the log within-cluster
+// sum of squares of the codebook. It recommends the smallest count k whose gap is not lower than the gap of k+1
+// reduced by its standard error. It returns error if the configuration is invalid, if codebook is nil or if
+// c.MaxK is higher than the number of codebook vectors.
+func SelectK(codebook *mat64.Dense, c *KSelectConfig) (int, []float64, error) {
+	if err := validateKSelectConfig(c); err != nil {
+		return -1, nil, err
+	}
+	if codebook == nil {
+		return -1, nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	rows, cols := codebook.Dims()
+	if c.MaxK > rows {
+		return -1, nil, fmt.Errorf("invalid cluster count range: [%d, %d]", c.MinK, c.MaxK)
+	}
+	src := rand.New(randSource(c.Source))
+	wcss := func(data *mat64.Dense, k int) (float64, error) {
+		clusters, centroids, err := KMeans(data, &KMeansConfig{K: k, Iters: c.Iters, Source: rand.NewSource(src.Int63())})
+		if err != nil {
+			return 0.0, err
+		}
+		var w float64
+		for i, cluster := range clusters {
+			w += sqDist(data.RawRowView(i), centroids.RawRowView(cluster))
+		}
+		return w, nil
+	}
+	counts := c.MaxK - c.MinK + 1
+	scores := make([]float64, counts)
+	if c.Method == "elbow" {
+		for i := range scores {
+			w, err := wcss(codebook, c.MinK+i)
+			if err != nil {
+				return -1, nil, err
+			}
+			scores[i] = w
+		}
+		return c.MinK + elbow(scores), scores, nil
+	}
+	// uniformly distributed reference data sets
+	min, max := colsRange(codebook)
+	refs := make([]*mat64.Dense, c.Refs)
+	for r := range refs {
+		refs[r] = mat64.NewDense(rows, cols, nil)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				refs[r].Set(i, j, min[j]+src.Float64()*(max[j]-min[j]))
+			}
+		}
+	}
+	errs := make([]float64, counts)
+	for i := range scores {
+		w, err := wcss(codebook, c.MinK+i)
+		if err != nil {
+			return -1, nil, err
+		}
+		refLogs := make([]float64, c.Refs)
+		for r, ref := range refs {
+			refW, err := wcss(ref, c.MinK+i)
+			if err != nil {
+				return -1, nil, err
+			}
+			refLogs[r] = math.Log(refW)
+		}
+		mean, std := stat.MeanStdDev(refLogs, nil)
+		if c.Refs == 1 {
+			std = 0.0
+		}
+		scores[i] = mean - math.Log(w)
+		errs[i] = std * math.Sqrt(1.0+1.0/float64(c.Refs))
+	}
+	for i := 0; i < counts-1; i++ {
+		if scores[i] >= scores[i+1]-errs[i+1] {
+			return c.MinK + i, scores, nil
+		}
+	}
+	return c.MaxK, scores, nil
+}
+
+// SelectK recommends the number of clusters of the map codebook vectors. It fails in the same way as SelectK function.
+func (m Map) SelectK(c *KSelectConfig) (int, []float64, error) {
+	return SelectK(m.codebook, c)
+}
+
+// elbow returns index of the score which lies farthest from the line connecting the first and the last score.
+// The indices and the scores are normalized to [0, 1] range so that the distances don't depend on their scales.
+func elbow(scores []float64) int {
+	n := len(scores)
+	first, last := scores[0], scores[n-1]
+	span := first - last
+	best, bestDist := 0, -1.0
+	for i, s := range scores {
+		x := float64(i) / float64(n-1)
+		y := 0.0
+		if span != 0 {
+			y = (first - s) / span
+		}
+		// the normalized line goes from (0, 0) to (1, 1)
+		if d := math.Abs(y-x) / math.Sqrt2; d > bestDist && y > x {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
@@ -0,0 +1,59 @@
+package som
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeClusteredCodebook() *mat64.Dense {
+	cbook := mat64.NewDense(12, 2, nil)
+	centers := [][]float64{{0.0, 0.0}, {10.0, 0.0}, {0.0, 10.0}}
+	offsets := [][]float64{{0.0, 0.0}, {0.5, 0.0}, {0.0, 0.5}, {0.5, 0.5}}
+	for i, center := range centers {
+		for j, offset := range offsets {
+			cbook.SetRow(i*len(offsets)+j, []float64{center[0] + offset[0], center[1] + offset[1]})
+		}
+	}
+	return cbook
+}
+
+func TestSelectK(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := makeClusteredCodebook()
+	for _, method := range []string{"elbow", "gap"} {
+		c := &KSelectConfig{Method: method, MinK: 1, MaxK: 6, Refs: 10, Iters: 20, Source: rand.NewSource(7)}
+		k, scores, err := SelectK(cbook, c)
+		assert.NoError(err, method)
+		assert.Equal(3, k, method)
+		assert.Len(scores, 6, method)
+	}
+	// within-cluster sum of squares decreases with the number of clusters
+	_, scores, err := SelectK(cbook, &KSelectConfig{Method: "elbow", MinK: 1, MaxK: 4, Iters: 20, Source: rand.NewSource(7)})
+	assert.NoError(err)
+	for i := 1; i < len(scores); i++ {
+		assert.True(scores[i] <= scores[i-1])
+	}
+	// invalid configuration
+	for _, c := range []*KSelectConfig{
+		{Method: "foo", MinK: 1, MaxK: 3, Refs: 1, Iters: 1},
+		{Method: "gap", MinK: 0, MaxK: 3, Refs: 1, Iters: 1},
+		{Method: "gap", MinK: 2, MaxK: 2, Refs: 1, Iters: 1},
+		{Method: "elbow", MinK: 1, MaxK: 2, Iters: 1},
+		{Method: "gap", MinK: 1, MaxK: 3, Refs: 0, Iters: 1},
+		{Method: "gap", MinK: 1, MaxK: 3, Refs: 1, Iters: 0},
+		{Method: "gap", MinK: 1, MaxK: 13, Refs: 1, Iters: 1},
+	} {
+		k, scores, err := SelectK(cbook, c)
+		assert.Equal(-1, k)
+		assert.Nil(scores)
+		assert.Error(err)
+	}
+	k, scores, err := SelectK(nil, &KSelectConfig{Method: "gap", MinK: 1, MaxK: 3, Refs: 1, Iters: 1})
+	assert.Equal(-1, k)
+	assert.Nil(scores)
+	assert.Error(err)
+}
@@ -0,0 +1,92 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// LabelUnits returns a slice which contains labels of all codebook vectors stored in codebook rows.
+// Every unit is labeled by the most frequent label of the data samples it is BMU for. labels maps
+// data row indices to their labels: data rows without a label are ignored. Ties between the most
+// frequent labels are resolved according to c.TieBreak. Units which are not BMUs of any labeled
+// data sample, units with fewer than c.MinSupport samples of the winning label and units whose tie
+// is left unresolved are labeled -1, so the labels must not be negative. If c is nil, the default labeling configuration is used.
+// It returns error if the configuration is invalid, if any label is negative, if either data or codebook are nil
+// or if their dimensions don't match.
+func LabelUnits(codebook, data *mat64.Dense, labels map[int]int, c *LabelConfig) ([]int, error) {
+	if c == nil {
+		c = &LabelConfig{}
+	}
+	if err := validateLabelConfig(c); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	// -1 is reserved for unlabeled units
+	for row, label := range labels {
+		if label < 0 {
+			return nil, fmt.Errorf("invalid label of data row %d: %d", row, label)
+		}
+	}
+	units, dists, err := MapRows(codebook, data, c.Metric)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := codebook.Dims()
+	// label counts and distance of the closest sample of every label for each unit
+	counts := make([]map[int]int, rows)
+	nearest := make([]map[int]float64, rows)
+	for row, unit := range units {
+		label, ok := labels[row]
+		if !ok {
+			continue
+		}
+		if counts[unit] == nil {
+			counts[unit] = make(map[int]int)
+			nearest[unit] = make(map[int]float64)
+		}
+		if d, ok := nearest[unit][label]; !ok || dists[row] < d {
+			nearest[unit][label] = dists[row]
+		}
+		counts[unit][label]++
+	}
+	unitLabels := make([]int, rows)
+	for unit := range unitLabels {
+		unitLabels[unit] = voteLabel(counts[unit], nearest[unit], c)
+	}
+
+	return unitLabels, nil
+}
+
+// voteLabel returns the most frequent label in counts resolving the ties according to c.TieBreak.
+// It returns -1 if counts is empty, if the tie is unresolved or if the label has less than c.MinSupport counts.
+func voteLabel(counts map[int]int, nearest map[int]float64, c *LabelConfig) int {
+	best, tied := -1, false
+	for label, count := range counts {
+		switch {
+		case best == -1 || count > counts[best]:
+			best, tied = label, false
+		case count == counts[best]:
+			tied = true
+			switch c.TieBreak {
+			case "nearest":
+				if d := nearest[label]; d < nearest[best] || (d == nearest[best] && label < best) {
+					best = label
+				}
+			default:
+				if label < best {
+					best = label
+				}
+			}
+		}
+	}
+	if best == -1 || counts[best] < c.MinSupport || (tied && c.TieBreak == "none") {
+		return -1
+	}
+	return best
+}
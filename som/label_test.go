@@ -0,0 +1,53 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelUnits(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 10.0, 20.0, 30.0})
+	data := mat64.NewDense(8, 1, []float64{0.0, 1.0, 2.0, 9.0, 12.0, 19.0, 22.0, 31.0})
+	labels := map[int]int{0: 1, 1: 1, 2: 2, 3: 3, 4: 2, 5: 5, 6: 4}
+	// default configuration
+	unitLabels, err := LabelUnits(cbook, data, labels, nil)
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 4, -1}, unitLabels)
+	// the label of the closest tied sample wins
+	unitLabels, err = LabelUnits(cbook, data, labels, &LabelConfig{TieBreak: "nearest"})
+	assert.NoError(err)
+	assert.Equal([]int{1, 3, 5, -1}, unitLabels)
+	// ties are left unresolved
+	unitLabels, err = LabelUnits(cbook, data, labels, &LabelConfig{TieBreak: "none"})
+	assert.NoError(err)
+	assert.Equal([]int{1, -1, -1, -1}, unitLabels)
+	// minimum support
+	unitLabels, err = LabelUnits(cbook, data, labels, &LabelConfig{MinSupport: 2})
+	assert.NoError(err)
+	assert.Equal([]int{1, -1, -1, -1}, unitLabels)
+	// invalid configuration
+	unitLabels, err = LabelUnits(cbook, data, labels, &LabelConfig{TieBreak: "foo"})
+	assert.Nil(unitLabels)
+	assert.EqualError(err, "unsupported tie-breaking strategy: foo")
+	unitLabels, err = LabelUnits(cbook, data, labels, &LabelConfig{MinSupport: -1})
+	assert.Nil(unitLabels)
+	assert.EqualError(err, "invalid minimum support: -1")
+	// negative labels clash with unlabeled units
+	unitLabels, err = LabelUnits(cbook, data, map[int]int{0: 1, 7: -1}, nil)
+	assert.Nil(unitLabels)
+	assert.EqualError(err, "invalid label of data row 7: -1")
+	// invalid parameters
+	unitLabels, err = LabelUnits(nil, data, labels, nil)
+	assert.Nil(unitLabels)
+	assert.Error(err)
+	unitLabels, err = LabelUnits(cbook, nil, labels, nil)
+	assert.Nil(unitLabels)
+	assert.Error(err)
+	unitLabels, err = LabelUnits(cbook, mat64.NewDense(1, 2, nil), labels, nil)
+	assert.Nil(unitLabels)
+	assert.Error(err)
+}
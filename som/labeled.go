@@ -0,0 +1,83 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// LabeledMap is a SOM classifier whose units are labeled by majority vote of labeled data samples
+type LabeledMap struct {
+	// m is the trained SOM
+	m *Map
+	// labels holds unit labels: unlabeled units are labeled -1
+	labels []int
+	// labeled holds indices of labeled units
+	labeled []int
+}
+
+// NewLabeledMap labels units of the trained map m by majority vote of the data samples labeled in classes
+// and returns a classifier which predicts labels of new samples. The units are labeled by LabelUnits
+// using the map distance metric: c.Metric is ignored. If c is nil, the default labeling configuration is used.
+// It returns error if the map or data are nil, if the labeling fails or if no unit could be labeled.
+func NewLabeledMap(m *Map, data *mat64.Dense, classes map[int]int, c *LabelConfig) (*LabeledMap, error) {
+	if m == nil {
+		return nil, fmt.Errorf("invalid map supplied: %v", m)
+	}
+	cfg := LabelConfig{}
+	if c != nil {
+		cfg = *c
+	}
+	cfg.Metric = m.metric
+	labels, err := LabelUnits(m.codebook, data, classes, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	labeled := []int{}
+	for unit, label := range labels {
+		if label != -1 {
+			labeled = append(labeled, unit)
+		}
+	}
+	if len(labeled) == 0 {
+		return nil, fmt.Errorf("no map unit could be labeled")
+	}
+
+	return &LabeledMap{
+		m:       m,
+		labels:  labels,
+		labeled: labeled,
+	}, nil
+}
+
+// Map returns the labeled map
+func (l LabeledMap) Map() *Map {
+	return l.m
+}
+
+// Labels returns a slice which contains labels of all map units. Unlabeled units are labeled -1.
+func (l LabeledMap) Labels() []int {
+	labels := make([]int, len(l.labels))
+	copy(labels, l.labels)
+	return labels
+}
+
+// Predict returns the label of the sample BMU. If the BMU is not labeled,
+// it returns the label of the labeled unit closest to the sample.
+// It returns error if the sample is nil or if its dimension is different from the map codebook dimension.
+func (l LabeledMap) Predict(sample *mat64.Vector) (int, error) {
+	unit, _, err := BMU(l.m.codebook, sample, l.m.metric)
+	if err != nil {
+		return -1, err
+	}
+	if l.labels[unit] != -1 {
+		return l.labels[unit], nil
+	}
+	// fall back to the closest labeled unit
+	unit, _, _, _, err = l.m.closestLabeled(mat64.Col(nil, 0, sample), l.labeled)
+	if err != nil {
+		return -1, err
+	}
+
+	return l.labels[unit], nil
+}
@@ -0,0 +1,51 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabeledMap(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{4}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(4, 1, []float64{0.0, 10.0, 20.0, 30.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	data := mat64.NewDense(4, 1, []float64{0.0, 1.0, 9.0, 31.0})
+	classes := map[int]int{0: 1, 1: 1, 2: 2, 3: 3}
+	l, err := NewLabeledMap(m, data, classes, nil)
+	assert.NoError(err)
+	assert.Equal(m, l.Map())
+	assert.Equal([]int{1, 2, -1, 3}, l.Labels())
+	// labeled BMU
+	label, err := l.Predict(mat64.NewVector(1, []float64{2.0}))
+	assert.NoError(err)
+	assert.Equal(1, label)
+	// unlabeled BMU falls back to the closest labeled unit
+	label, err = l.Predict(mat64.NewVector(1, []float64{24.0}))
+	assert.NoError(err)
+	assert.Equal(3, label)
+	label, err = l.Predict(mat64.NewVector(1, []float64{19.0}))
+	assert.NoError(err)
+	assert.Equal(2, label)
+	// invalid sample
+	label, err = l.Predict(nil)
+	assert.Equal(-1, label)
+	assert.Error(err)
+	label, err = l.Predict(mat64.NewVector(2, nil))
+	assert.Equal(-1, label)
+	assert.Error(err)
+	// no unit can be labeled
+	l, err = NewLabeledMap(m, data, classes, &LabelConfig{MinSupport: 3})
+	assert.Nil(l)
+	assert.EqualError(err, "no map unit could be labeled")
+	l, err = NewLabeledMap(nil, data, classes, nil)
+	assert.Nil(l)
+	assert.Error(err)
+}
@@ -9,8 +9,8 @@ import (
 const MinLRate = 0.01
 
 // LRate is a decay function for the SOM learning rate parameter.
-// It supports exponential and linear decay strategies denoted as "exp" and "lin".
-// Any other strategy defaults to "exp". At the first iteration the function returns
+// It supports exponential, linear, inverse-time and power-law decay strategies denoted as
+// "exp", "lin", "inv" and "pow". Any other strategy defaults to "exp". At the first iteration the function returns
 // the initLRate, at totalIterations-1 it returns MinLRate
 // It returns error if initLRate  is not a positive integer
 func LRate(iteration, totalIterations int, strategy string, initLRate float64) (float64, error) {
@@ -23,6 +23,10 @@ func LRate(iteration, totalIterations int, strategy string, initLRate float64) (
 		return expLRate(iteration, totalIterations, initLRate), nil
 	case "lin":
 		return linLRate(iteration, totalIterations, initLRate), nil
+	case "inv":
+		return invLRate(iteration, totalIterations, initLRate), nil
+	case "pow":
+		return powLRate(iteration, totalIterations, initLRate), nil
 	default:
 		return expLRate(iteration, totalIterations, initLRate), nil
 	}
@@ -36,3 +40,32 @@ func expLRate(iteration, totalIterations int, initLRate float64) float64 {
 func linLRate(iteration, totalIterations int, initLRate float64) float64 {
 	return initLRate - float64(iteration)/float64(totalIterations-1)*(initLRate-MinLRate)
 }
+
+func invLRate(iteration, totalIterations int, initLRate float64) float64 {
+	lambda := float64(totalIterations-1) / (initLRate/MinLRate - 1.0)
+	return initLRate / (1.0 + float64(iteration)/lambda)
+}
+
+func powLRate(iteration, totalIterations int, initLRate float64) float64 {
+	k := math.Log(initLRate/MinLRate) / math.Log(float64(totalIterations))
+	return initLRate * math.Pow(1.0+float64(iteration), -k)
+}
+
+// lRate returns the learning rate at the given training iteration out of iters iterations
+// using the decay schedule or strategy and parameters of the training configuration
+func (c *TrainConfig) lRate(iteration, iters int) float64 {
+	if c.LSchedule != nil {
+		return c.LSchedule.Value(iteration, iters)
+	}
+	if c.LDecayTime > 0 {
+		switch c.LDecay {
+		case "exp":
+			return c.LRate * math.Exp(-float64(iteration)/c.LDecayTime)
+		case "inv":
+			return c.LRate / (1.0 + float64(iteration)/c.LDecayTime)
+		}
+	}
+	// no need to check for errors: initial learning rate is checked by config validation
+	l, _ := LRate(iteration, iters, c.LDecay, c.LRate)
+	return l
+}
@@ -15,6 +15,14 @@ func TestLinLR(t *testing.T) {
 	testLR(t, "lin")
 }
 
+func TestInvLR(t *testing.T) {
+	testLR(t, "inv")
+}
+
+func TestPowLR(t *testing.T) {
+	testLR(t, "pow")
+}
+
 func TestDefaultLR(t *testing.T) {
 	testLR(t, "some other")
 }
@@ -41,3 +49,33 @@ func testLR(t *testing.T, strategy string) {
 	assert.InDelta(MinLRate, lr, 0.01)
 	assert.NoError(err)
 }
+
+func TestTrainConfigLRate(t *testing.T) {
+	assert := assert.New(t)
+
+	tc := &TrainConfig{LRate: 0.5, LDecay: "exp"}
+	// default exponential decay ends at MinLRate
+	assert.InDelta(MinLRate, tc.lRate(99, 100), 0.0001)
+	// decay time constant
+	tc.LDecayTime = 50.0
+	assert.Equal(0.5, tc.lRate(0, 100))
+	assert.InDelta(0.5*math.Exp(-1.0), tc.lRate(50, 100), 0.0001)
+	tc.LDecay = "inv"
+	assert.InDelta(0.25, tc.lRate(50, 100), 0.0001)
+	// decay time constant is ignored by linear decay
+	tc.LDecay = "lin"
+	assert.InDelta(MinLRate, tc.lRate(99, 100), 0.0001)
+}
+
+func TestTrainConfigLSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	// cyclic schedule restarts every 10 iterations
+	cyclic := DecayFunc(func(iteration, totalIterations int) float64 {
+		return 0.5 - float64(iteration%10)*0.05
+	})
+	tc := &TrainConfig{LRate: 0.1, LDecay: "exp", LSchedule: cyclic}
+	assert.Equal(0.5, tc.lRate(0, 100))
+	assert.InDelta(0.25, tc.lRate(15, 100), 0.0001)
+	assert.Equal(0.5, tc.lRate(20, 100))
+}
@@ -0,0 +1,126 @@
+package som
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// UnitClasses returns a slice which contains class labels of all map units.
+// Every unit is labeled by the most frequent class of the data samples it is BMU for;
+// ties are resolved in favour of the smaller class label. Units which are not BMUs of any
+// classified data sample are labeled -1.
+// It returns error if the data is nil, if its dimensions don't match the map codebook or if any class label is negative.
+func (m Map) UnitClasses(data *mat64.Dense, classes map[int]int) ([]int, error) {
+	return LabelUnits(m.codebook, data, classes, &LabelConfig{Metric: m.metric})
+}
+
+// LVQ runs Learning Vector Quantization fine-tuning of the map codebook for a given number of iterations.
+// Each iteration presents one randomly chosen classified data sample. lvq1 moves the closest unit
+// towards the sample if they are of the same class and away from it otherwise. lvq2.1 updates
+// the two closest units if exactly one of them is of the sample class and the sample falls into
+// the window around their decision border: the unit of the sample class is moved towards the
+// sample and the other one away from it. lvq3 additionally moves both units towards the sample
+// by Epsilon scaled learning rate if they are both of the sample class.
+// unitClasses holds class labels of map units as returned by UnitClasses: units labeled -1 are not updated.
+// It returns error if the configuration is invalid, the data is nil, none of the data samples is
+// classified or if the number of unit classes does not match the number of map units.
+func (m *Map) LVQ(c *LVQConfig, unitClasses []int, data *mat64.Dense, classes map[int]int, iters int) error {
+	// number of iterations must be a positive integer
+	if iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", iters)
+	}
+	// nil data passed in
+	if data == nil {
+		return fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if err := validateLVQConfig(c); err != nil {
+		return err
+	}
+	if len(unitClasses) != m.grid.Units() {
+		return fmt.Errorf("invalid number of unit classes: %d", len(unitClasses))
+	}
+	// only classified samples are used for fine-tuning
+	rows, _ := data.Dims()
+	samples := []int{}
+	for row := 0; row < rows; row++ {
+		if _, ok := classes[row]; ok {
+			samples = append(samples, row)
+		}
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("invalid data classes: %v", classes)
+	}
+	// labeled units are the only candidates for the closest units
+	labeled := []int{}
+	for unit, class := range unitClasses {
+		if class != -1 {
+			labeled = append(labeled, unit)
+		}
+	}
+	if len(labeled) == 0 || (c.Algorithm != "lvq1" && len(labeled) < 2) {
+		return fmt.Errorf("insufficient number of labeled units: %d", len(labeled))
+	}
+	// window border ratio
+	s := (1.0 - c.Window) / (1.0 + c.Window)
+	r := rand.New(randSource(c.Source))
+	for i := 0; i < iters; i++ {
+		row := samples[r.Intn(len(samples))]
+		sample, class := data.RawRowView(row), classes[row]
+		// learning rate decays linearly to zero
+		lRate := c.LRate * (1.0 - float64(i)/float64(iters))
+		u1, u2, d1, d2, err := m.closestLabeled(sample, labeled)
+		if err != nil {
+			return err
+		}
+		ok1, ok2 := unitClasses[u1] == class, unitClasses[u2] == class
+		switch {
+		case c.Algorithm == "lvq1":
+			if ok1 {
+				m.seqUpdateCbVec(u1, sample, lRate)
+			} else {
+				m.seqUpdateCbVec(u1, sample, -lRate)
+			}
+		case ok1 != ok2:
+			// the sample must fall into the window around the decision border
+			if d2 == 0 || d1/d2 <= s {
+				continue
+			}
+			if ok2 {
+				u1, u2 = u2, u1
+			}
+			m.seqUpdateCbVec(u1, sample, lRate)
+			m.seqUpdateCbVec(u2, sample, -lRate)
+		case ok1 && ok2 && c.Algorithm == "lvq3":
+			m.seqUpdateCbVec(u1, sample, c.Epsilon*lRate)
+			m.seqUpdateCbVec(u2, sample, c.Epsilon*lRate)
+		}
+	}
+
+	return nil
+}
+
+// closestLabeled returns the two units from the labeled units closest to vec along with their distances.
+// If there is only one labeled unit, both returned units are the same.
+func (m Map) closestLabeled(vec []float64, labeled []int) (int, int, float64, float64, error) {
+	u1, u2 := -1, -1
+	var d1, d2 float64
+	for _, unit := range labeled {
+		d, err := Distance(m.metric, vec, m.codebook.RawRowView(unit))
+		if err != nil {
+			return -1, -1, 0.0, 0.0, err
+		}
+		switch {
+		case u1 == -1 || d < d1:
+			u2, d2 = u1, d1
+			u1, d1 = unit, d
+		case u2 == -1 || d < d2:
+			u2, d2 = unit, d
+		}
+	}
+	if u2 == -1 {
+		u2, d2 = u1, d1
+	}
+	return u1, u2, d1, d2, nil
+}
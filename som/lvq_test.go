@@ -0,0 +1,88 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeLVQMap(t *testing.T, codebook []float64) *Map {
+	grid, err := NewGrid(&GridConfig{Size: []int{len(codebook)}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(t, err)
+	return &Map{
+		codebook: mat64.NewDense(len(codebook), 1, codebook),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+}
+
+func TestUnitClasses(t *testing.T) {
+	assert := assert.New(t)
+
+	m := makeLVQMap(t, []float64{0.0, 5.0, 10.0})
+	data := mat64.NewDense(5, 1, []float64{0.1, 0.2, -0.1, 9.0, 10.0})
+	classes := map[int]int{0: 2, 1: 1, 2: 2, 3: 4}
+	unitClasses, err := m.UnitClasses(data, classes)
+	assert.NoError(err)
+	assert.Equal([]int{2, -1, 4}, unitClasses)
+	// ties are resolved in favour of smaller class
+	delete(classes, 2)
+	unitClasses, err = m.UnitClasses(data, classes)
+	assert.NoError(err)
+	assert.Equal([]int{1, -1, 4}, unitClasses)
+	// nil data
+	unitClasses, err = m.UnitClasses(nil, classes)
+	assert.Nil(unitClasses)
+	assert.Error(err)
+}
+
+func TestLVQ(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(1, 1, []float64{4.5})
+	classes := map[int]int{0: 1}
+	c := &LVQConfig{
+		Algorithm: "lvq1",
+		LRate:     0.1,
+		Window:    0.3,
+		Epsilon:   0.5,
+	}
+	// lvq1 pushes the closest unit of the wrong class away
+	m := makeLVQMap(t, []float64{0.0, 10.0})
+	assert.NoError(m.LVQ(c, []int{0, 1}, data, classes, 1))
+	assert.True(m.codebook.At(0, 0) < 0.0)
+	assert.Equal(10.0, m.codebook.At(1, 0))
+	// lvq1 pulls the closest unit of the right class closer
+	m = makeLVQMap(t, []float64{0.0, 10.0})
+	assert.NoError(m.LVQ(c, []int{1, 0}, data, classes, 1))
+	assert.InDelta(0.45, m.codebook.At(0, 0), 0.0001)
+	// lvq2.1 updates both units within the window
+	c.Algorithm = "lvq2.1"
+	m = makeLVQMap(t, []float64{0.0, 10.0})
+	assert.NoError(m.LVQ(c, []int{0, 1}, data, classes, 1))
+	assert.True(m.codebook.At(0, 0) < 0.0)
+	assert.InDelta(9.45, m.codebook.At(1, 0), 0.0001)
+	// samples outside of the window are ignored
+	m = makeLVQMap(t, []float64{4.0, 20.0})
+	assert.NoError(m.LVQ(c, []int{0, 1}, data, classes, 1))
+	assert.Equal([]float64{4.0, 20.0}, m.codebook.RawMatrix().Data)
+	// lvq3 moves both units of the right class closer
+	c.Algorithm = "lvq3"
+	m = makeLVQMap(t, []float64{0.0, 10.0})
+	assert.NoError(m.LVQ(c, []int{1, 1}, data, classes, 1))
+	assert.InDelta(0.225, m.codebook.At(0, 0), 0.0001)
+	assert.InDelta(9.725, m.codebook.At(1, 0), 0.0001)
+	// unlabeled units are not updated
+	m = makeLVQMap(t, []float64{0.0, 10.0, 4.5})
+	assert.NoError(m.LVQ(c, []int{0, 1, -1}, data, classes, 1))
+	assert.Equal(4.5, m.codebook.At(2, 0))
+	// invalid parameters
+	assert.Error(m.LVQ(c, []int{0, 1, -1}, data, classes, 0))
+	assert.Error(m.LVQ(c, []int{0, 1, -1}, nil, classes, 1))
+	assert.EqualError(m.LVQ(c, []int{0, 1}, data, classes, 1), "invalid number of unit classes: 2")
+	assert.Error(m.LVQ(c, []int{0, 1, -1}, data, nil, 1))
+	assert.EqualError(m.LVQ(c, []int{0, -1, -1}, data, classes, 1), "insufficient number of labeled units: 1")
+	c.Algorithm = "foobar"
+	assert.EqualError(m.LVQ(c, []int{0, 1, -1}, data, classes, 1), "invalid LVQ algorithm: foobar")
+}
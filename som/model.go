@@ -0,0 +1,277 @@
+package som
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// modelVersion is the version of saved SOM models
+const modelVersion = 1
+
+// ModelInfo holds optional metadata saved along with SOM model
+type ModelInfo struct {
+	// Mean and Stdev hold column means and standard deviations the training data were scaled with.
+	// New data must be scaled in the same way before they are mapped to the loaded map
+	Mean  []float64 `json:"mean,omitempty"`
+	Stdev []float64 `json:"stdev,omitempty"`
+	// Train holds the parameters the map was trained with
+	Train *TrainInfo `json:"train,omitempty"`
+}
+
+// Scale returns a copy of data whose columns are centered and scaled by Mean and Stdev.
+// Columns with zero standard deviation are only centered.
+// It fails with error if data is nil or if the number of data columns does not match Mean and Stdev.
+func (i *ModelInfo) Scale(data *mat64.Dense) (*mat64.Dense, error) {
+	if data == nil {
+		return nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	rows, cols := data.Dims()
+	if len(i.Mean) != cols || len(i.Stdev) != cols {
+		return nil, fmt.Errorf("invalid number of scaling parameters: %d, %d, expected: %d", len(i.Mean), len(i.Stdev), cols)
+	}
+	scaled := mat64.NewDense(rows, cols, nil)
+	scaled.Apply(func(r, c int, x float64) float64 {
+		if i.Stdev[c] == 0 {
+			return x - i.Mean[c]
+		}
+		return (x - i.Mean[c]) / i.Stdev[c]
+	}, data)
+
+	return scaled, nil
+}
+
+// TrainInfo holds the serializable parameters of SOM training configuration.
+// Custom decay schedules, neighbourhoods, data row weights, hooks and random sources are not saved.
+type TrainInfo struct {
+	// Algorithm is the training method: seq or batch
+	Algorithm string `json:"algorithm"`
+	// Iters is the number of training iterations
+	Iters int `json:"iters"`
+	// Radius, RDecay and RDecayTime are the initial radius and its decay strategy
+	Radius     float64 `json:"radius"`
+	RDecay     string  `json:"rdecay"`
+	RDecayTime float64 `json:"rdecay_time,omitempty"`
+	// NeighbFn is the name of the neighbourhood function, it is empty for custom neighbourhoods
+	NeighbFn string `json:"neighb,omitempty"`
+	// LRate, LDecay and LDecayTime are the initial learning rate and its decay strategy
+	LRate      float64 `json:"lrate"`
+	LDecay     string  `json:"ldecay"`
+	LDecayTime float64 `json:"ldecay_time,omitempty"`
+	// Shuffle is the order of sequential training samples
+	Shuffle string `json:"shuffle,omitempty"`
+}
+
+// NewTrainInfo returns the serializable parameters of training configuration c used to train the map for iters iterations
+func NewTrainInfo(c *TrainConfig, iters int) *TrainInfo {
+	info := &TrainInfo{
+		Algorithm:  c.Algorithm,
+		Iters:      iters,
+		Radius:     c.Radius,
+		RDecay:     c.RDecay,
+		RDecayTime: c.RDecayTime,
+		LRate:      c.LRate,
+		LDecay:     c.LDecay,
+		LDecayTime: c.LDecayTime,
+		Shuffle:    c.Shuffle,
+	}
+	// functions can only be compared by their code pointers
+	if c.Neighb == nil && c.NeighbFn != nil {
+		fn := reflect.ValueOf(c.NeighbFn).Pointer()
+		for name, nFn := range neighbFns {
+			if reflect.ValueOf(nFn).Pointer() == fn {
+				info.NeighbFn = name
+			}
+		}
+	}
+	return info
+}
+
+// Config returns training configuration with the saved parameters.
+// It fails with error if the neighbourhood function is not supported.
+func (t *TrainInfo) Config() (*TrainConfig, error) {
+	c := &TrainConfig{
+		Algorithm:  t.Algorithm,
+		Radius:     t.Radius,
+		RDecay:     t.RDecay,
+		RDecayTime: t.RDecayTime,
+		LRate:      t.LRate,
+		LDecay:     t.LDecay,
+		LDecayTime: t.LDecayTime,
+		Shuffle:    t.Shuffle,
+	}
+	if t.NeighbFn != "" {
+		nFn, err := NeighbFuncByName(t.NeighbFn)
+		if err != nil {
+			return nil, err
+		}
+		c.NeighbFn = nFn
+	}
+	return c, nil
+}
+
+// mapModel is the serialized SOM model
+type mapModel struct {
+	Version  int         `json:"version"`
+	Grid     gridModel   `json:"grid"`
+	Metric   string      `json:"metric"`
+	Codebook [][]float64 `json:"codebook,omitempty"`
+	Info     *ModelInfo  `json:"info,omitempty"`
+}
+
+// gridModel is the serialized SOM grid
+type gridModel struct {
+	Size   []int       `json:"size"`
+	Type   string      `json:"type"`
+	UShape string      `json:"ushape"`
+	Graph  [][]float64 `json:"graph,omitempty"`
+}
+
+// Save serializes SOM model in a given format to writer w: the codebook, grid, distance metric
+// and optional info such as the data scaling and training parameters.
+// Supported formats are readable "json", "gob", compact "binary" format suitable for large codebooks
+// and "sompak" codebook format of SOM_PAK which only stores 2D planar grids of hexagons or rectangles
+// and the neighbourhood function. Rendering configuration is not saved. It fails with error if the format is not supported or if the write to w fails.
+func (m Map) Save(format string, w io.Writer, info *ModelInfo) error {
+	switch format {
+	case "json":
+		return json.NewEncoder(w).Encode(m.model(info))
+	case "gob":
+		return gob.NewEncoder(w).Encode(m.model(info))
+	case "binary":
+		return m.saveBinary(w, info)
+	case "sompak":
+		return m.saveSOMPAK(w, info)
+	}
+
+	return fmt.Errorf("unsupported format: %s", format)
+}
+
+// Load deserializes SOM model saved by Save in a given format from reader r.
+// It returns the loaded map and its saved info which is nil if no info has been saved.
+// It fails with error if the format is not supported, the model could not be read or if it is invalid.
+func Load(format string, r io.Reader) (*Map, *ModelInfo, error) {
+	model := new(mapModel)
+	switch format {
+	case "json":
+		if err := json.NewDecoder(r).Decode(model); err != nil {
+			return nil, nil, err
+		}
+	case "gob":
+		if err := gob.NewDecoder(r).Decode(model); err != nil {
+			return nil, nil, err
+		}
+	case "binary":
+		return loadBinary(r)
+	case "sompak":
+		return loadSOMPAK(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	m, err := model.restore()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return m, model.Info, nil
+}
+
+// model returns the serialized model of the map with the given info
+func (m Map) model(info *ModelInfo) *mapModel {
+	model := m.modelHeader(info)
+	rows, _ := m.codebook.Dims()
+	model.Codebook = make([][]float64, rows)
+	for i := range model.Codebook {
+		model.Codebook[i] = mat64.Row(nil, i, m.codebook)
+	}
+	return model
+}
+
+// modelHeader returns the serialized model of the map with the given info without the codebook
+func (m Map) modelHeader(info *ModelInfo) *mapModel {
+	model := &mapModel{
+		Version: modelVersion,
+		Grid: gridModel{
+			Size:   m.grid.size,
+			Type:   m.grid.gtype,
+			UShape: m.grid.ushape,
+		},
+		Metric: m.metric,
+		Info:   info,
+	}
+	if m.grid.graph != nil {
+		units, _ := m.grid.graph.Dims()
+		model.Grid.Graph = make([][]float64, units)
+		for i := range model.Grid.Graph {
+			model.Grid.Graph[i] = mat64.Row(nil, i, m.grid.graph)
+		}
+	}
+	return model
+}
+
+// restore returns the map of the serialized model.
+// It fails with error if the model version is not supported or if the model is invalid.
+func (model *mapModel) restore() (*Map, error) {
+	grid, err := model.grid()
+	if err != nil {
+		return nil, err
+	}
+	if len(model.Codebook) != grid.Units() {
+		return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", len(model.Codebook), grid.Units())
+	}
+	codebook, err := denseRows(model.Codebook)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Map{
+		codebook: codebook,
+		grid:     grid,
+		metric:   model.Metric,
+	}, nil
+}
+
+// grid returns the grid of the serialized model.
+// It fails with error if the model version or metric are not supported or if the grid is invalid.
+func (model *mapModel) grid() (*Grid, error) {
+	if model.Version != modelVersion {
+		return nil, fmt.Errorf("unsupported model version: %d", model.Version)
+	}
+	if err := validateMetric(model.Metric); err != nil {
+		return nil, err
+	}
+	c := &GridConfig{
+		Size:   model.Grid.Size,
+		Type:   model.Grid.Type,
+		UShape: model.Grid.UShape,
+	}
+	if len(model.Grid.Graph) > 0 {
+		graph, err := denseRows(model.Grid.Graph)
+		if err != nil {
+			return nil, err
+		}
+		c.Graph = graph
+	}
+
+	return NewGrid(c)
+}
+
+// denseRows returns a matrix of the given rows. It fails with error if the rows are empty or of different lengths.
+func denseRows(rows [][]float64) (*mat64.Dense, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("invalid matrix rows: %v", rows)
+	}
+	cols := len(rows[0])
+	data := make([]float64, 0, len(rows)*cols)
+	for i, row := range rows {
+		if len(row) != cols {
+			return nil, fmt.Errorf("invalid length of row %d: %d, expected: %d", i, len(row), cols)
+		}
+		data = append(data, row...)
+	}
+	return mat64.NewDense(len(rows), cols, data), nil
+}
@@ -0,0 +1,114 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	_, cols := dataMx.Dims()
+	info := &ModelInfo{
+		Mean:  make([]float64, cols),
+		Stdev: make([]float64, cols),
+		Train: NewTrainInfo(tSom, 100),
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.Save("json", &buf, info))
+	assert.Contains(buf.String(), `"version":1`)
+	loaded, loadedInfo, err := Load("json", &buf)
+	assert.NoError(err)
+	assert.True(mat64.Equal(m.codebook, loaded.codebook))
+	assert.Equal(m.grid.size, loaded.grid.size)
+	assert.Equal(m.grid.ushape, loaded.grid.ushape)
+	assert.True(mat64.Equal(m.grid.coords, loaded.grid.coords))
+	assert.Equal(m.metric, loaded.metric)
+	assert.Equal(info, loadedInfo)
+	// loaded map maps the data in the same way
+	bmus, err := m.BMUs(dataMx)
+	assert.NoError(err)
+	loadedBMUs, err := loaded.BMUs(dataMx)
+	assert.NoError(err)
+	assert.Equal(bmus, loadedBMUs)
+	// info is optional
+	buf.Reset()
+	assert.NoError(m.Save("json", &buf, nil))
+	assert.NotContains(buf.String(), "info")
+	_, loadedInfo, err = Load("json", &buf)
+	assert.NoError(err)
+	assert.Nil(loadedInfo)
+	// graph grids keep their graphs
+	graph := mat64.NewDense(3, 3, []float64{0, 1, 0, 1, 0, 1, 0, 1, 0})
+	grid, err := NewGrid(&GridConfig{Type: "graph", UShape: "hexagon", Graph: graph})
+	assert.NoError(err)
+	gm := Map{codebook: mat64.NewDense(3, 1, []float64{0, 1, 2}), grid: grid, metric: "manhattan"}
+	buf.Reset()
+	assert.NoError(gm.Save("json", &buf, nil))
+	loaded, _, err = Load("json", &buf)
+	assert.NoError(err)
+	assert.True(mat64.Equal(graph, loaded.grid.graph))
+	// invalid formats
+	assert.Error(m.Save("xml", &buf, nil))
+	_, _, err = Load("xml", &buf)
+	assert.Error(err)
+	assert.Error(m.Save("json", failingWriter{}, nil))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []string{
+		`{`,
+		`{"version":2,"grid":{"size":[1,2],"type":"planar","ushape":"rectangle"},"metric":"euclidean","codebook":[[1],[2]]}`,
+		`{"version":1,"grid":{"size":[1,2],"type":"planar","ushape":"rectangle"},"metric":"foo","codebook":[[1],[2]]}`,
+		`{"version":1,"grid":{"size":[1,2],"type":"planar","ushape":"foo"},"metric":"euclidean","codebook":[[1],[2]]}`,
+		`{"version":1,"grid":{"size":[1,2],"type":"planar","ushape":"rectangle"},"metric":"euclidean","codebook":[[1]]}`,
+		`{"version":1,"grid":{"size":[1,2],"type":"planar","ushape":"rectangle"},"metric":"euclidean","codebook":[[1],[2,3]]}`,
+		`{"version":1,"grid":{"size":[1,2],"type":"planar","ushape":"rectangle"},"metric":"euclidean","codebook":[[],[]]}`,
+	}
+	for _, tc := range testCases {
+		_, _, err := Load("json", strings.NewReader(tc))
+		assert.Error(err, tc)
+	}
+}
+
+func TestTrainInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info := NewTrainInfo(tSom, 10)
+	assert.Equal("gaussian", info.NeighbFn)
+	assert.Equal(10, info.Iters)
+	c, err := info.Config()
+	assert.NoError(err)
+	assert.NoError(validateTrainConfig(c))
+	assert.Equal(tSom.Radius, c.Radius)
+	assert.Equal(tSom.LDecay, c.LDecay)
+	assert.Equal(Gaussian(1.0, 2.0), c.NeighbFn(1.0, 2.0))
+	// custom neighbourhoods have no name
+	tc := *tSom
+	tc.NeighbFn = func(d, r float64) float64 { return 1.0 }
+	assert.Equal("", NewTrainInfo(&tc, 10).NeighbFn)
+	info.NeighbFn = "foo"
+	_, err = info.Config()
+	assert.Error(err)
+}
+
+func TestModelInfoScale(t *testing.T) {
+	assert := assert.New(t)
+
+	info := &ModelInfo{Mean: []float64{1, 2}, Stdev: []float64{2, 0}}
+	scaled, err := info.Scale(mat64.NewDense(2, 2, []float64{3, 4, 1, 2}))
+	assert.NoError(err)
+	assert.Equal([]float64{1, 2, 0, 0}, scaled.RawMatrix().Data)
+	_, err = info.Scale(mat64.NewDense(1, 3, nil))
+	assert.Error(err)
+	_, err = info.Scale(nil)
+	assert.Error(err)
+}
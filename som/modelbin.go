@@ -0,0 +1,131 @@
+package som
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// binaryMagic marks the beginning of SOM models saved in binary format
+const binaryMagic = "GSOM"
+
+// maxBinaryHeader is the maximum size of the binary model header in bytes
+const maxBinaryHeader = 1 << 26
+
+// binaryChunk is the number of codebook values read at once
+const binaryChunk = 4096
+
+// saveBinary writes the model of the map with the given info in binary format to w.
+// The model consists of binaryMagic, model version, the length of JSON model header which
+// describes the grid, metric and info, the header itself, the codebook dimensions and
+// little endian codebook values stored by rows. All integers are 32 bit little endian unsigned integers.
+func (m Map) saveBinary(w io.Writer, info *ModelInfo) error {
+	header, err := json.Marshal(m.modelHeader(info))
+	if err != nil {
+		return err
+	}
+	rows, cols := m.codebook.Dims()
+	bw := bufio.NewWriter(w)
+	var buf [8]byte
+	putUint32 := func(v int) {
+		binary.LittleEndian.PutUint32(buf[:4], uint32(v))
+		bw.Write(buf[:4])
+	}
+	bw.WriteString(binaryMagic)
+	putUint32(modelVersion)
+	putUint32(len(header))
+	bw.Write(header)
+	putUint32(rows)
+	putUint32(cols)
+	for i := 0; i < rows; i++ {
+		for _, v := range m.codebook.RawRowView(i) {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			bw.Write(buf[:])
+		}
+	}
+	// bufio.Writer keeps the first write error
+	return bw.Flush()
+}
+
+// loadBinary reads the map and its info saved in binary format by saveBinary from r.
+// It fails with error if the model could not be read or if it is invalid.
+func loadBinary(r io.Reader) (*Map, *ModelInfo, error) {
+	br := bufio.NewReader(r)
+	var buf [4]byte
+	readUint32 := func() (int, error) {
+		if _, err := io.ReadFull(br, buf[:]); err != nil {
+			return 0, err
+		}
+		return int(binary.LittleEndian.Uint32(buf[:])), nil
+	}
+	if _, err := io.ReadFull(br, buf[:]); err != nil {
+		return nil, nil, err
+	}
+	if string(buf[:]) != binaryMagic {
+		return nil, nil, fmt.Errorf("invalid binary model")
+	}
+	version, err := readUint32()
+	if err != nil {
+		return nil, nil, err
+	}
+	if version != modelVersion {
+		return nil, nil, fmt.Errorf("unsupported model version: %d", version)
+	}
+	size, err := readUint32()
+	if err != nil {
+		return nil, nil, err
+	}
+	if size > maxBinaryHeader {
+		return nil, nil, fmt.Errorf("invalid model header size: %d", size)
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, nil, err
+	}
+	model := new(mapModel)
+	if err := json.Unmarshal(header, model); err != nil {
+		return nil, nil, err
+	}
+	grid, err := model.grid()
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := readUint32()
+	if err != nil {
+		return nil, nil, err
+	}
+	cols, err := readUint32()
+	if err != nil {
+		return nil, nil, err
+	}
+	if rows != grid.Units() || cols == 0 {
+		return nil, nil, fmt.Errorf("invalid codebook dimensions: [%d, %d]", rows, cols)
+	}
+	// the values are read in chunks so that corrupted dimensions don't allocate memory which is never filled
+	n := rows * cols
+	data := make([]float64, 0, binaryChunk)
+	chunk := make([]byte, 8*binaryChunk)
+	for len(data) < n {
+		k := n - len(data)
+		if k > binaryChunk {
+			k = binaryChunk
+		}
+		if _, err := io.ReadFull(br, chunk[:8*k]); err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < k; i++ {
+			data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(chunk[8*i:])))
+		}
+	}
+
+	return &Map{
+		codebook: mat64.NewDense(rows, cols, data),
+		grid:     grid,
+		metric:   model.Metric,
+	}, model.Info, nil
+}
@@ -0,0 +1,62 @@
+package som
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLoadBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	info := &ModelInfo{Mean: []float64{1, 2}, Stdev: []float64{3, 4}, Train: NewTrainInfo(tSom, 10)}
+	for _, format := range []string{"gob", "binary"} {
+		var buf bytes.Buffer
+		assert.NoError(m.Save(format, &buf, info))
+		loaded, loadedInfo, err := Load(format, &buf)
+		assert.NoError(err, format)
+		assert.True(mat64.Equal(m.codebook, loaded.codebook), format)
+		assert.Equal(m.grid.size, loaded.grid.size)
+		assert.Equal(m.metric, loaded.metric)
+		assert.Equal(info, loadedInfo)
+		assert.Error(m.Save(format, failingWriter{}, info))
+	}
+	// binary codebook is stored as raw values
+	var buf bytes.Buffer
+	assert.NoError(m.Save("binary", &buf, nil))
+	rows, cols := m.codebook.Dims()
+	assert.Equal("GSOM", buf.String()[:4])
+	assert.Equal(uint32(1), binary.LittleEndian.Uint32(buf.Bytes()[4:8]))
+	header := int(binary.LittleEndian.Uint32(buf.Bytes()[8:12]))
+	assert.Equal(12+header+8+8*rows*cols, buf.Len())
+	_, loadedInfo, err := Load("binary", bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Nil(loadedInfo)
+	// invalid binary models
+	data := buf.Bytes()
+	corrupt := func(offset int, value uint32) []byte {
+		c := append([]byte{}, data...)
+		binary.LittleEndian.PutUint32(c[offset:], value)
+		return c
+	}
+	testCases := [][]byte{
+		{},
+		[]byte("GSOX"),
+		append([]byte("FOOB"), data[4:]...),
+		corrupt(4, 2),
+		corrupt(8, maxBinaryHeader+1),
+		corrupt(12+header, uint32(rows+1)),
+		corrupt(12+header+4, 0),
+		data[:len(data)-1],
+		data[:12+header-1],
+	}
+	for i, tc := range testCases {
+		_, _, err := Load("binary", bytes.NewReader(tc))
+		assert.Error(err, "case %d", i)
+	}
+}
@@ -0,0 +1,143 @@
+package som
+
+import (
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// MSOM is a Merge Self Organizing Map for sequence data.
+// Every unit holds an input weight vector and a context vector of the same dimension. The context
+// is compared with a merged descriptor of the previous BMU which makes MSOM much lighter than
+// RecSOM whose contexts are as long as the number of map units.
+type MSOM struct {
+	// m holds SOM grid and input weight vectors
+	m *Map
+	// context holds unit context vectors: units x data features
+	context *mat64.Dense
+	// alpha is the weight of context distance
+	alpha float64
+	// beta is the merging parameter
+	beta float64
+	// bmu is BMU of the last processed time step or -1 if there is no history
+	bmu int
+}
+
+// NewMSOM creates new Merge SOM based on the provided configuration.
+// Input weights are initialized by the configured codebook initialization function
+// and unit contexts are initialized to zero vectors.
+// NewMSOM returns error if the provided configuration is not valid or if the map could not be created.
+func NewMSOM(c *MSOMConfig, data *mat64.Dense) (*MSOM, error) {
+	if err := validateMSOMConfig(c); err != nil {
+		return nil, err
+	}
+	m, err := NewMap(c.Map, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MSOM{
+		m:       m,
+		context: mat64.NewDense(m.codebook.RawMatrix().Rows, m.codebook.RawMatrix().Cols, nil),
+		alpha:   c.Alpha,
+		beta:    c.Beta,
+		bmu:     -1,
+	}, nil
+}
+
+// Map returns SOM which holds MSOM grid and input weight vectors
+func (s MSOM) Map() *Map {
+	return s.m
+}
+
+// Context returns a matrix which contains unit context vectors stored row by row
+func (s MSOM) Context() mat64.Matrix {
+	return s.context
+}
+
+// Reset resets map history so that the next processed sequence starts without any context
+func (s *MSOM) Reset() {
+	s.bmu = -1
+}
+
+// Train runs sequential MSOM training for a given number of time steps. Data rows are processed
+// in their order as a single sequence which is repeated if iters exceeds the number of rows.
+// The context descriptor of every time step is (1-beta)*w + beta*c of the previous BMU or a zero
+// vector if there is no history. BMU is the unit with the smallest distance (1-alpha)*|x-w|^2 +
+// alpha*|ctx-c|^2. Input weights and contexts of units in the BMU neighbourhood are moved towards
+// x and context descriptor, respectively. Only sequential training algorithm is supported.
+// It returns error if the training configuration is invalid or if the data dimensions don't match the map.
+func (s *MSOM) Train(tc *TrainConfig, data *mat64.Dense, iters int) error {
+	if err := validateTemporalTraining(tc, data, s.m.codebook, iters); err != nil {
+		return err
+	}
+	unitDist, err := s.m.UnitDist()
+	if err != nil {
+		return err
+	}
+	nb := tc.neighbourhood()
+	rows, _ := data.Dims()
+	for i := 0; i < iters; i++ {
+		sample := data.RawRowView(i % rows)
+		ctx := s.descriptor()
+		bmu := s.closest(sample, ctx)
+		lRate := tc.lRate(i, iters)
+		radius := tc.radius(i, iters)
+		bmuDists := unitDist.RawRowView(bmu)
+		for j := 0; j < len(bmuDists); j++ {
+			nghb := unitWeight(nb, bmuDists[j], radius)
+			if nghb == 0.0 {
+				continue
+			}
+			l := math.Min(lRate*nghb, 1.0)
+			move(s.m.codebook.RawRowView(j), sample, l)
+			move(s.context.RawRowView(j), ctx, l)
+		}
+		s.bmu = bmu
+	}
+
+	return nil
+}
+
+// BMUs returns a slice which contains BMU indices of every time step of the sequence stored in data rows.
+// The sequence is processed from the current map history which is updated accordingly.
+// It returns error if the data is nil or if its dimensions don't match the map input weights.
+func (s *MSOM) BMUs(data *mat64.Dense) ([]int, error) {
+	if err := validateTemporalData(data, s.m.codebook); err != nil {
+		return nil, err
+	}
+	rows, _ := data.Dims()
+	bmus := make([]int, rows)
+	for i := 0; i < rows; i++ {
+		s.bmu = s.closest(data.RawRowView(i), s.descriptor())
+		bmus[i] = s.bmu
+	}
+	return bmus, nil
+}
+
+// descriptor returns the context descriptor merged from the input weights and context of the previous BMU
+func (s MSOM) descriptor() []float64 {
+	_, cols := s.context.Dims()
+	ctx := make([]float64, cols)
+	if s.bmu == -1 {
+		return ctx
+	}
+	w, c := s.m.codebook.RawRowView(s.bmu), s.context.RawRowView(s.bmu)
+	for i := range ctx {
+		ctx[i] = (1.0-s.beta)*w[i] + s.beta*c[i]
+	}
+	return ctx
+}
+
+// closest returns the index of the unit closest to input x and context descriptor ctx
+func (s MSOM) closest(x, ctx []float64) int {
+	units, _ := s.m.codebook.Dims()
+	bmu, minDist := 0, math.Inf(1)
+	for i := 0; i < units; i++ {
+		d := (1.0-s.alpha)*sqDist(x, s.m.codebook.RawRowView(i)) + s.alpha*sqDist(ctx, s.context.RawRowView(i))
+		if d < minDist {
+			bmu, minDist = i, d
+		}
+	}
+	return bmu
+}
@@ -0,0 +1,50 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMSOM(t *testing.T) {
+	assert := assert.New(t)
+
+	data := makeSequence(100)
+	c := &MSOMConfig{
+		Map: &MapConfig{
+			Grid: &GridConfig{Size: []int{6}, Type: "planar", UShape: "rectangle"},
+			Cb:   &CbConfig{Dim: 1, InitFunc: RandInit},
+		},
+		Alpha: 0.5,
+		Beta:  0.5,
+	}
+	s, err := NewMSOM(c, data)
+	assert.NoError(err)
+	assert.NotNil(s)
+	rows, cols := s.Context().Dims()
+	assert.Equal(6, rows)
+	assert.Equal(1, cols)
+	assert.NoError(s.Train(makeTemporalTrainConfig(), data, 3000))
+	// the two zero inputs of the pattern are told apart by their context
+	s.Reset()
+	bmus, err := s.BMUs(makeSequence(3))
+	assert.NoError(err)
+	assert.Len(bmus, 9)
+	assert.NotEqual(bmus[6], bmus[7])
+	// invalid parameters
+	tc := makeTemporalTrainConfig()
+	assert.Error(s.Train(tc, data, 0))
+	assert.EqualError(s.Train(tc, mat64.NewDense(2, 2, nil), 10), "invalid data dimensions: 2")
+	bmus, err = s.BMUs(nil)
+	assert.Nil(bmus)
+	assert.Error(err)
+	c.Alpha = 1.0
+	s, err = NewMSOM(c, data)
+	assert.Nil(s)
+	assert.EqualError(err, "invalid MSOM alpha: 1.000000")
+	c.Alpha, c.Beta = 0.5, 1.5
+	s, err = NewMSOM(c, data)
+	assert.Nil(s)
+	assert.EqualError(err, "invalid MSOM beta: 1.500000")
+}
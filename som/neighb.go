@@ -1,13 +1,29 @@
 package som
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
-// Gaussian calculates gaussian neghbourhood
+// NeighbFuncByName returns the neighbourhood function of the given name: gaussian, bubble, cutgauss,
+// epanechnikov, mexican, dog.
+// It fails with error if the requested neighbourhood function is not supported.
+func NeighbFuncByName(name string) (NeighbFunc, error) {
+	nFn, ok := neighbFns[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported neighbourhood function: %s", name)
+	}
+	return nFn, nil
+}
+
+// Gaussian calculates gaussian neghbourhood exp(-d^2/(2*r^2)).
+// Its weights smoothly decrease with distance and are not cut off at the radius.
 func Gaussian(distance float64, radius float64) float64 {
 	return math.Exp(-(distance * distance) / (2 * radius * radius))
 }
 
-// Bubble calculates bubble neghbourhood
+// Bubble calculates bubble neghbourhood.
+// All units within the radius have the same weight 1 and units outside of it are not updated.
 func Bubble(distance float64, radius float64) float64 {
 	if distance <= radius {
 		return 1.0
@@ -15,9 +31,40 @@ func Bubble(distance float64, radius float64) float64 {
 	return 0.0
 }
 
-// MexicanHat calculates mexican hat neghbourhood
+// CutGaussian calculates gaussian neighbourhood cut off at the radius.
+// Units outside of the radius are not updated.
+func CutGaussian(distance float64, radius float64) float64 {
+	if distance <= radius {
+		return Gaussian(distance, radius)
+	}
+	return 0.0
+}
+
+// Epanechnikov calculates epanechnikov neighbourhood 1 - d^2/r^2.
+// Its weights smoothly decrease to zero at the radius and units outside of it are not updated.
+func Epanechnikov(distance float64, radius float64) float64 {
+	if distance < radius {
+		return 1.0 - (distance*distance)/(radius*radius)
+	}
+	return 0.0
+}
+
+// MexicanHat calculates mexican hat neghbourhood.
+// Its weights are negative outside of the radius so sequential training slightly repels units
+// just outside of it. Batch training ignores negative weights.
 func MexicanHat(distance float64, radius float64) float64 {
 	return 2 / (math.Sqrt(3*radius) * math.Pow(math.Pi, 0.25)) *
 		(1 - (distance*distance)/(radius*radius)) *
 		math.Exp(-(distance*distance)/(2*radius*radius))
 }
+
+// dogExp is the exponent scale which makes DoG neighbourhood cross zero at the radius
+var dogExp = 4.0 * math.Ln2 / 3.0
+
+// DoG calculates difference of gaussians neighbourhood 2*exp(-a*d^2/r^2) - exp(-a*d^2/(4*r^2)),
+// where a is chosen so that the weights are 1 at zero distance and cross zero at the radius.
+// Like MexicanHat its weights are negative outside of the radius.
+func DoG(distance float64, radius float64) float64 {
+	x := dogExp * (distance * distance) / (radius * radius)
+	return 2*math.Exp(-x) - math.Exp(-x/4)
+}
@@ -55,3 +55,57 @@ func TestBubble(t *testing.T) {
 	assert.Equal(t, 0.0, Bubble(radius+diff, radius))
 	assert.Equal(t, 1.0, Bubble(radius, radius))
 }
+
+func TestNeighbFuncByName(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"gaussian", "bubble", "cutgauss", "epanechnikov", "mexican", "dog"} {
+		nFn, err := NeighbFuncByName(name)
+		assert.NotNil(nFn)
+		assert.NoError(err)
+	}
+	nFn, err := NeighbFuncByName("gaussian")
+	assert.NoError(err)
+	assert.Equal(Gaussian(1.5, 2.0), nFn(1.5, 2.0))
+	// unsupported function
+	nFn, err = NeighbFuncByName("foobar")
+	assert.Nil(nFn)
+	assert.Error(err)
+}
+
+func TestCutGaussian(t *testing.T) {
+	radius := 2.0
+	diff := radius / 10.0
+
+	assert.Equal(t, 1.0, CutGaussian(0.0, radius))
+	assert.Equal(t, Gaussian(radius-diff, radius), CutGaussian(radius-diff, radius))
+	assert.Equal(t, Gaussian(radius, radius), CutGaussian(radius, radius))
+	assert.Equal(t, 0.0, CutGaussian(radius+diff, radius))
+}
+
+func TestDoG(t *testing.T) {
+	radius := 3.0
+
+	assert.InDelta(t, 1.0, DoG(0.0, radius), 0.0001)
+	assert.InDelta(t, 0.0, DoG(radius, radius), 0.0001)
+	// positive inside the radius and negative outside of it
+	assert.True(t, DoG(0.5*radius, radius) > 0)
+	assert.True(t, DoG(1.5*radius, radius) < 0)
+	// the repulsion vanishes far away from the radius
+	assert.InDelta(t, 0.0, DoG(10*radius, radius), 0.0001)
+}
+
+func TestEpanechnikov(t *testing.T) {
+	radius := 2.0
+	diff := radius / 10.0
+
+	assert.Equal(t, 1.0, Epanechnikov(0.0, radius))
+	assert.InDelta(t, 0.75, Epanechnikov(0.5*radius, radius), 0.0001)
+	assert.Equal(t, 0.0, Epanechnikov(radius, radius))
+	assert.Equal(t, 0.0, Epanechnikov(radius+diff, radius))
+}
+
+func TestNeighbFuncWeight(t *testing.T) {
+	var nb Neighbourhood = NeighbFunc(Gaussian)
+	assert.Equal(t, Gaussian(1.5, 2.0), nb.Weight(1.5, 2.0))
+}
@@ -0,0 +1,132 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ClassCounts returns a slice which contains the class distribution of the data samples every codebook vector
+// is the Best Match Unit of using the supplied distance metric. i-th slice item maps classes to the number of samples
+// of the class mapped to the codebook vector stored in i-th codebook row; it is nil if no labeled sample is mapped there.
+// classes maps data row indices to their classes: data rows without a class are ignored.
+// It returns error if either data or codebook are nil or if their dimensions are mismatched.
+func ClassCounts(codebook, data *mat64.Dense, metric string, classes map[int]int) ([]map[int]int, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	units, _, err := MapRows(codebook, data, metric)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := codebook.Dims()
+	counts := make([]map[int]int, rows)
+	for row, unit := range units {
+		class, ok := classes[row]
+		if !ok {
+			continue
+		}
+		if counts[unit] == nil {
+			counts[unit] = make(map[int]int)
+		}
+		counts[unit][class]++
+	}
+
+	return counts, nil
+}
+
+// PieSVG creates an SVG representation of the U-Matrix of the given codebook with a pie chart drawn inside
+// every unit which shows the class distribution of the data samples stored in data rows mapped to the unit.
+// It accepts the same parameters as UMatrixSVG, data - the labeled data set and classes which maps data rows to their classes.
+// Pie slices are colored by their classes; units without labeled samples have no pie chart.
+// It fails with error if the class distribution could not be computed or if the grid coordinates could not be computed.
+func PieSVG(codebook, data *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	counts, err := ClassCounts(codebook, data, metric, classes)
+	if err != nil {
+		return err
+	}
+
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{pies: counts})
+}
+
+// ClassCounts returns the class distribution of the data samples mapped to every map unit.
+// It fails in the same way as ClassCounts.
+func (m Map) ClassCounts(data *mat64.Dense, classes map[int]int) ([]map[int]int, error) {
+	return ClassCounts(m.codebook, data, m.metric, classes)
+}
+
+// PieUMatrix generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but every map unit contains a pie chart of the class distribution of the data samples mapped to it.
+// Currently only "svg" format is supported. It fails with error if the class distribution could not be computed.
+func (m Map) PieUMatrix(w io.Writer, data *mat64.Dense, classes map[int]int, format, title string) error {
+	switch format {
+	case "svg":
+		counts, err := m.ClassCounts(data, classes)
+		if err != nil {
+			return err
+		}
+		s := m.render.style()
+		s.pies = counts
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// pieSVG returns SVG elements of the pie chart of class counts centered at x, y with radius r.
+// Slices follow the class order clockwise from the top and are colored by their classes.
+// Classes with negative IDs or non-positive counts are left out.
+func pieSVG(counts map[int]int, x, y, r float64, stroke string) []interface{} {
+	classes := make([]int, 0, len(counts))
+	total := 0
+	for class, count := range counts {
+		if class >= 0 && count > 0 {
+			classes = append(classes, class)
+			total += count
+		}
+	}
+	sort.Ints(classes)
+	fill := func(class int) string {
+		c := colors[class%len(colors)]
+		return fmt.Sprintf("fill:rgb(%d,%d,%d);%s", c[0], c[1], c[2], stroke)
+	}
+	tooltip := func(class int) string {
+		return fmt.Sprintf("class: %d\ncount: %d", class, counts[class])
+	}
+	// a single class fills the whole pie
+	if len(classes) == 1 {
+		return []interface{}{circle{Cx: x, Cy: y, R: r, Class: "pie", Style: fill(classes[0]), Title: tooltip(classes[0])}}
+	}
+	elems := make([]interface{}, 0, len(classes))
+	angle := 0.0
+	for _, class := range classes {
+		sweep := 2 * math.Pi * float64(counts[class]) / float64(total)
+		x1, y1 := x+r*math.Sin(angle), y-r*math.Cos(angle)
+		x2, y2 := x+r*math.Sin(angle+sweep), y-r*math.Cos(angle+sweep)
+		large := 0
+		if sweep > math.Pi {
+			large = 1
+		}
+		elems = append(elems, path{
+			D:     fmt.Sprintf("M %f,%f L %f,%f A %f,%f 0 %d 1 %f,%f Z", x, y, x1, y1, r, r, large, x2, y2),
+			Class: "pie",
+			Style: fill(class),
+			Title: tooltip(class),
+		})
+		angle += sweep
+	}
+	return elems
+}
@@ -0,0 +1,67 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassCounts(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 100.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 4.0, 6.0, 10.0})
+	counts, err := ClassCounts(cbook, data, "euclidean", map[int]int{0: 0, 1: 1, 2: 1, 3: 1})
+	assert.NoError(err)
+	// unlabeled samples are ignored
+	assert.Equal([]map[int]int{{0: 1, 1: 1}, {1: 2}, nil}, counts)
+	// nil parameters
+	counts, err = ClassCounts(nil, data, "euclidean", nil)
+	assert.Nil(counts)
+	assert.Error(err)
+	counts, err = ClassCounts(cbook, nil, "euclidean", nil)
+	assert.Nil(counts)
+	assert.Error(err)
+}
+
+func TestPieSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 100.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 4.0, 6.0, 10.0})
+	classes := map[int]int{0: 0, 1: 1, 2: 1, 3: 1}
+	var buf bytes.Buffer
+	err := PieSVG(cbook, data, []int{1, 3}, "rectangle", "euclidean", "Pies", &buf, classes)
+	assert.NoError(err)
+	svg := buf.String()
+	// the first unit is split in halves, the second one is filled by a single class
+	assert.Equal(2, strings.Count(svg, "<path "))
+	assert.True(strings.Contains(svg, `<path d="M 35.000000,35.000000 L 35.000000,15.000000 A 20.000000,20.000000 0 0 1 35.000000,55.000000 Z" style="fill:rgb(255,0,0);stroke:black;stroke-width:1">`))
+	assert.True(strings.Contains(svg, `<circle cx="85" cy="35" r="20" style="fill:rgb(0,255,0);stroke:black;stroke-width:1"><title>class: 1&#xA;count: 2</title></circle>`))
+	assert.Equal(1, strings.Count(svg, "<circle "))
+	// map pie charts
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var mBuf bytes.Buffer
+	assert.NoError(m.PieUMatrix(&mBuf, data, classes, "svg", "Pies"))
+	assert.Equal(svg, mBuf.String())
+	assert.Error(m.PieUMatrix(&mBuf, data, classes, "png", "Pies"))
+	assert.Error(m.PieUMatrix(&mBuf, mat64.NewDense(1, 2, nil), classes, "svg", "Pies"))
+	assert.Error(PieSVG(cbook, data, []int{1, 3}, "foobar", "euclidean", "Pies", &buf, classes))
+}
+
+func TestPieSlices(t *testing.T) {
+	assert := assert.New(t)
+
+	// slices larger than half of the pie use the large arc
+	elems := pieSVG(map[int]int{0: 3, 1: 1, -1: 5, 2: 0}, 0.0, 0.0, 1.0, "stroke:none")
+	assert.Len(elems, 2)
+	assert.Equal("M 0.000000,0.000000 L 0.000000,-1.000000 A 1.000000,1.000000 0 1 1 -1.000000,0.000000 Z", elems[0].(path).D)
+	assert.True(strings.Contains(elems[1].(path).D, "A 1.000000,1.000000 0 0 1 "))
+	assert.True(strings.HasSuffix(elems[1].(path).D, ",-1.000000 Z"))
+	assert.Empty(pieSVG(map[int]int{}, 0.0, 0.0, 1.0, "stroke:none"))
+}
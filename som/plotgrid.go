@@ -0,0 +1,112 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// PlotGrid holds values of the units of 2D map grid arranged in columns and rows of map cells.
+// It is only a data adapter: it implements GridXYZ interface of gonum/plot plotter package, but it is
+// not a plot.Plotter itself and this package does not depend on gonum/plot. Callers wrap it in a plotter
+// such as plotter.NewHeatMap or plotter.NewContour to compose the values into gonum/plot figures, e.g.:
+//
+//	g, _ := m.UMatrixGrid()
+//	p.Add(plotter.NewHeatMap(g, palette.Heat(12, 1)))
+//
+// Column c is the map grid column c and row r is the map grid row counted from the bottom of the map,
+// so the figure is oriented in the same way as the rendered map. Cells of hexagon and triangle grids
+// are plotted as rectangles without their row offsets. U-Matrix, component planes and hit maps
+// are provided as PlotGrid by UMatrixGrid, ComponentGrid and HitsGrid.
+type PlotGrid struct {
+	rows, cols int
+	values     []float64
+}
+
+// NewPlotGrid returns PlotGrid of the given unit values of the map grid of the given dimensions.
+// The values are ordered in the same way as the map units. 1D grids are plotted as a single row.
+// It fails with error if the grid is not 1D or 2D or if the number of values does not match the grid.
+func NewPlotGrid(dims []int, values []float64) (*PlotGrid, error) {
+	if len(dims) < 1 || len(dims) > 2 {
+		return nil, fmt.Errorf("unsupported plot grid dimensions: %v", dims)
+	}
+	g := &PlotGrid{rows: 1, cols: dims[0]}
+	if len(dims) == 2 {
+		g.rows, g.cols = dims[0], dims[1]
+	}
+	if g.rows <= 0 || g.cols <= 0 {
+		return nil, fmt.Errorf("unsupported plot grid dimensions: %v", dims)
+	}
+	if len(values) != g.rows*g.cols {
+		return nil, fmt.Errorf("invalid number of unit values: %d, expected: %d", len(values), g.rows*g.cols)
+	}
+	g.values = make([]float64, len(values))
+	copy(g.values, values)
+
+	return g, nil
+}
+
+// Dims returns the number of grid columns and rows
+func (g PlotGrid) Dims() (int, int) {
+	return g.cols, g.rows
+}
+
+// Z returns the value of the unit in column c and row r
+func (g PlotGrid) Z(c, r int) float64 {
+	return g.values[c*g.rows+g.rows-1-r]
+}
+
+// X returns the x coordinate of column c
+func (g PlotGrid) X(c int) float64 {
+	return float64(c)
+}
+
+// Y returns the y coordinate of row r
+func (g PlotGrid) Y(r int) float64 {
+	return float64(r)
+}
+
+// UMatrixGrid returns PlotGrid of SOM u-matrix. It fails with error if the map grid is not 1D or 2D planar grid.
+func (m Map) UMatrixGrid() (*PlotGrid, error) {
+	values, err := m.UMatrixValues()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.plotGrid(values)
+}
+
+// ComponentGrid returns PlotGrid of SOM component plane of the given feature.
+// It fails with error if the feature index is out of range or if the map grid is not 1D or 2D planar grid.
+func (m Map) ComponentGrid(feature int) (*PlotGrid, error) {
+	values, err := componentValues(m.codebook, m.grid, feature)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.plotGrid(values)
+}
+
+// HitsGrid returns PlotGrid of the numbers of data rows mapped to SOM units.
+// It fails with error if the hits could not be computed or if the map grid is not 1D or 2D planar grid.
+func (m Map) HitsGrid(data *mat64.Dense) (*PlotGrid, error) {
+	hits, err := m.HitMap(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(hits))
+	for i, h := range hits {
+		values[i] = float64(h)
+	}
+
+	return m.plotGrid(values)
+}
+
+// plotGrid returns PlotGrid of the given values of the map units
+func (m Map) plotGrid(values []float64) (*PlotGrid, error) {
+	if m.grid.gtype == "sphere" || m.grid.gtype == "graph" {
+		return nil, fmt.Errorf("unsupported plot grid: %s %v", m.grid.gtype, m.grid.size)
+	}
+
+	return NewPlotGrid(m.grid.size, values)
+}
@@ -0,0 +1,78 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gridXYZ mirrors GridXYZ interface of gonum/plot plotter package
+type gridXYZ interface {
+	Dims() (c, r int)
+	Z(c, r int) float64
+	X(c int) float64
+	Y(r int) float64
+}
+
+var _ gridXYZ = (*PlotGrid)(nil)
+
+func TestNewPlotGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	// units are stored by columns, rows are counted from the bottom of the map
+	g, err := NewPlotGrid([]int{2, 3}, []float64{0, 1, 2, 3, 4, 5})
+	assert.NoError(err)
+	c, r := g.Dims()
+	assert.Equal(3, c)
+	assert.Equal(2, r)
+	assert.Equal(1.0, g.Z(0, 0))
+	assert.Equal(0.0, g.Z(0, 1))
+	assert.Equal(5.0, g.Z(2, 0))
+	assert.Equal(2.0, g.X(2))
+	assert.Equal(1.0, g.Y(1))
+	// 1D grid is a single row
+	g, err = NewPlotGrid([]int{3}, []float64{0, 1, 2})
+	assert.NoError(err)
+	c, r = g.Dims()
+	assert.Equal(3, c)
+	assert.Equal(1, r)
+	assert.Equal(2.0, g.Z(2, 0))
+	// invalid parameters
+	_, err = NewPlotGrid([]int{2, 2, 2}, make([]float64, 8))
+	assert.Error(err)
+	_, err = NewPlotGrid([]int{}, nil)
+	assert.Error(err)
+	_, err = NewPlotGrid([]int{2, 2}, make([]float64, 3))
+	assert.Error(err)
+}
+
+func TestMapPlotGrids(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	umatrix, err := m.UMatrixValues()
+	assert.NoError(err)
+	g, err := m.UMatrixGrid()
+	assert.NoError(err)
+	rows := mSom.Grid.Size[0]
+	assert.Equal(umatrix[rows-1], g.Z(0, 0))
+	g, err = m.ComponentGrid(0)
+	assert.NoError(err)
+	assert.Equal(m.codebook.At(rows-1, 0), g.Z(0, 0))
+	_, err = m.ComponentGrid(100)
+	assert.Error(err)
+	g, err = m.HitsGrid(dataMx)
+	assert.NoError(err)
+	total := 0.0
+	c, r := g.Dims()
+	for i := 0; i < c; i++ {
+		for j := 0; j < r; j++ {
+			total += g.Z(i, j)
+		}
+	}
+	dataRows, _ := dataMx.Dims()
+	assert.Equal(float64(dataRows), total)
+	_, err = m.HitsGrid(nil)
+	assert.Error(err)
+}
@@ -0,0 +1,149 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// PMatrix computes P-Matrix of the given codebook, i.e. the density of data samples stored in data rows
+// around every codebook vector: i-th slice item holds the number of data samples whose distance from the
+// codebook vector stored in i-th codebook row computed using the supplied metric is not bigger than radius.
+// If radius is 0, it is set to the Pareto radius approximated by the 20th percentile of the pairwise data distances.
+// It fails with error if either codebook or data are nil, if their dimensions are mismatched or if radius is negative.
+func PMatrix(codebook, data *mat64.Dense, radius float64, metric string) ([]float64, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("invalid radius: %f", radius)
+	}
+	if radius == 0 {
+		var err error
+		if radius, err = paretoRadius(data, metric); err != nil {
+			return nil, err
+		}
+	}
+	rows, _ := codebook.Dims()
+	dRows, _ := data.Dims()
+	pmatrix := make([]float64, rows)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < dRows; j++ {
+			d, err := Distance(metric, codebook.RawRowView(i), data.RawRowView(j))
+			if err != nil {
+				return nil, err
+			}
+			if d <= radius {
+				pmatrix[i]++
+			}
+		}
+	}
+
+	return pmatrix, nil
+}
+
+// paretoRadius approximates the Pareto radius of data by the 20th percentile of the pairwise data distances
+func paretoRadius(data *mat64.Dense, metric string) (float64, error) {
+	distMat, err := DistanceMx(metric, data)
+	if err != nil {
+		return 0.0, err
+	}
+	rows, _ := distMat.Dims()
+	if rows < 2 {
+		return 0.0, fmt.Errorf("insufficient number of data samples: %d", rows)
+	}
+	dists := make([]float64, 0, rows*(rows-1)/2)
+	for i := 0; i < rows; i++ {
+		dists = append(dists, distMat.RawRowView(i)[i+1:]...)
+	}
+	sort.Float64s(dists)
+
+	return dists[int(0.2*float64(len(dists)-1))], nil
+}
+
+// UStarMatrix computes U*-Matrix of the given codebook whose units are laid out on a planar grid
+// of the given dimensions and unit shape. U*-Matrix combines U-Matrix and P-Matrix computed using
+// the supplied radius and metric: U-Matrix values of the units in dense data regions are decreased
+// and the values of the units in sparse regions are increased, i.e. every U-Matrix value is multiplied
+// by (P - mean(P)) / (mean(P) - max(P)) + 1. It fails in the same way as PMatrix or if the codebook does not match the grid.
+func UStarMatrix(codebook, data *mat64.Dense, dims []int, uShape string, radius float64, metric string) ([]float64, error) {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return nil, err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return ustarMatrix(codebook, data, grid, radius, metric)
+}
+
+// ustarMatrix computes U*-Matrix of the given codebook and grid
+func ustarMatrix(codebook, data *mat64.Dense, grid *Grid, radius float64, metric string) ([]float64, error) {
+	pmatrix, err := PMatrix(codebook, data, radius, metric)
+	if err != nil {
+		return nil, err
+	}
+	if len(pmatrix) != grid.Units() {
+		return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", len(pmatrix), grid.Units())
+	}
+	umatrix, _, _, err := umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return nil, err
+	}
+	meanP, maxP := floats.Sum(pmatrix)/float64(len(pmatrix)), floats.Max(pmatrix)
+	ustar := make([]float64, len(umatrix))
+	for i := range umatrix {
+		scale := 1.0
+		if maxP > meanP {
+			scale += (pmatrix[i] - meanP) / (meanP - maxP)
+		}
+		ustar[i] = umatrix[i] * scale
+	}
+
+	return ustar, nil
+}
+
+// PMatrix generates SOM P-Matrix of data computed using the supplied radius in a given format and writes the output to w.
+// The denser the data around the unit, the darker the unit. Supported formats are svg and png.
+// It fails with error if the P-Matrix could not be computed or if the write to w fails.
+func (m Map) PMatrix(w io.Writer, data *mat64.Dense, radius float64, format, title string) error {
+	values, err := PMatrix(m.codebook, data, radius, m.metric)
+	if err != nil {
+		return err
+	}
+
+	return m.renderValues(w, values, format, title)
+}
+
+// UStarMatrix generates SOM U*-Matrix of data computed using the supplied radius in a given format and writes the output to w.
+// Supported formats are svg and png. It fails with error if the U*-Matrix could not be computed or if the write to w fails.
+func (m Map) UStarMatrix(w io.Writer, data *mat64.Dense, radius float64, format, title string) error {
+	values, err := ustarMatrix(m.codebook, data, m.grid, radius, m.metric)
+	if err != nil {
+		return err
+	}
+
+	return m.renderValues(w, values, format, title)
+}
+
+// renderValues renders map units shaded by values in a given format and writes the output to w
+func (m Map) renderValues(w io.Writer, values []float64, format, title string) error {
+	switch format {
+	case "svg":
+		return valuesSVG(m.grid, values, floats.Min(values), floats.Max(values), title, w, m.render.style())
+	case "png":
+		return valuesPNG(m.grid, values, floats.Min(values), floats.Max(values), w, m.render.style())
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
@@ -0,0 +1,71 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(2, 1, []float64{0.0, 10.0})
+	data := mat64.NewDense(4, 1, []float64{0.0, 0.5, 1.0, 10.0})
+	pmatrix, err := PMatrix(cbook, data, 1.0, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]float64{3.0, 1.0}, pmatrix)
+	// Pareto radius is used by default
+	radius, err := paretoRadius(data, "euclidean")
+	assert.NoError(err)
+	assert.Equal(0.5, radius)
+	pmatrix, err = PMatrix(cbook, data, 0.0, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]float64{2.0, 1.0}, pmatrix)
+	// invalid parameters
+	_, err = PMatrix(nil, data, 1.0, "euclidean")
+	assert.Error(err)
+	_, err = PMatrix(cbook, nil, 1.0, "euclidean")
+	assert.Error(err)
+	_, err = PMatrix(cbook, data, -1.0, "euclidean")
+	assert.EqualError(err, "invalid radius: -1.000000")
+	_, err = PMatrix(cbook, mat64.NewDense(1, 1, nil), 0.0, "euclidean")
+	assert.EqualError(err, "insufficient number of data samples: 1")
+	_, err = PMatrix(cbook, mat64.NewDense(2, 2, nil), 1.0, "euclidean")
+	assert.Error(err)
+}
+
+func TestUStarMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(2, 1, []float64{0.0, 10.0})
+	data := mat64.NewDense(4, 1, []float64{0.0, 0.5, 1.0, 10.0})
+	// dense region distances are decreased and sparse region distances increased
+	ustar, err := UStarMatrix(cbook, data, []int{1, 2}, "rectangle", 1.0, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]float64{0.0, 20.0}, ustar)
+	// uniform density keeps the U-Matrix
+	ustar, err = UStarMatrix(cbook, data, []int{1, 2}, "rectangle", 100.0, "euclidean")
+	assert.NoError(err)
+	assert.Equal([]float64{10.0, 10.0}, ustar)
+	_, err = UStarMatrix(cbook, data, []int{1, 3}, "rectangle", 1.0, "euclidean")
+	assert.Error(err)
+	// map rendering
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var buf bytes.Buffer
+	assert.NoError(m.PMatrix(&buf, data, 1.0, "svg", "P-Matrix"))
+	assert.Equal(2, strings.Count(buf.String(), "<polygon "))
+	buf.Reset()
+	assert.NoError(m.UStarMatrix(&buf, data, 1.0, "svg", "U*-Matrix"))
+	svg := buf.String()
+	assert.True(strings.Contains(svg, "fill:rgb(255,255,255)"))
+	assert.True(strings.Contains(svg, "fill:rgb(0,0,0)"))
+	buf.Reset()
+	assert.NoError(m.UStarMatrix(&buf, data, 1.0, "png", "U*-Matrix"))
+	assert.Error(m.PMatrix(&buf, data, 1.0, "bmp", "P-Matrix"))
+	assert.Error(m.UStarMatrix(&buf, nil, 1.0, "svg", "U*-Matrix"))
+}
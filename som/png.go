@@ -0,0 +1,203 @@
+package som
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// UMatrixPNG creates a PNG image of the U-Matrix of the given codebook and writes it to writer.
+// It accepts the same parameters as UMatrixSVG except for the title: PNG images contain no text,
+// so the class numbers are not rendered either, but the class units are still colored by their class.
+// Only 1D and 2D planar grids can be rendered.
+func UMatrixPNG(codebook *mat64.Dense, dims []int, uShape, metric string, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return umatrixPNG(codebook, grid, metric, writer, unitStyle{classes: classes})
+}
+
+// ComponentPlanePNG creates a PNG image of the component plane of the given codebook feature and writes it to writer.
+// It accepts the same parameters as ComponentPlaneSVG except for the title and fails in the same way.
+// Only 1D and 2D planar grids can be rendered.
+func ComponentPlanePNG(codebook *mat64.Dense, dims []int, uShape string, feature int, writer io.Writer, classes map[int]int) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return componentPlanePNG(codebook, grid, feature, writer, unitStyle{classes: classes})
+}
+
+// umatrixPNG creates a PNG image of the U-Matrix of the given codebook and grid.
+func umatrixPNG(codebook *mat64.Dense, grid *Grid, metric string, writer io.Writer, s unitStyle) error {
+	umatrix, minDistance, maxDistance, err := s.umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return err
+	}
+
+	return valuesPNG(grid, umatrix, minDistance, maxDistance, writer, s)
+}
+
+// componentPlanePNG creates a PNG image of the component plane of the given codebook feature and grid.
+func componentPlanePNG(codebook *mat64.Dense, grid *Grid, feature int, writer io.Writer, s unitStyle) error {
+	values, err := componentValues(codebook, grid, feature)
+	if err != nil {
+		return err
+	}
+
+	return valuesPNG(grid, values, floats.Min(values), floats.Max(values), writer, s)
+}
+
+// valuesPNG creates a PNG image of the grid whose units are shaded by values
+// normalized to [minValue, maxValue] range and writes it to writer.
+func valuesPNG(grid *Grid, values []float64, minValue, maxValue float64, writer io.Writer, s unitStyle) error {
+	// scale the coord grid to something visible
+	mul, off := s.cellSize(), s.margin()
+
+	if grid.gtype == "sphere" || grid.gtype == "graph" || len(grid.size) > 2 {
+		return fmt.Errorf("unsupported PNG grid: %s %v", grid.gtype, grid.size)
+	}
+	// 1D grids are rendered as a ribbon of squares
+	dims, uShape := grid.size, grid.ushape
+	if len(dims) == 1 {
+		dims, uShape = []int{1, dims[0]}, "rectangle"
+	}
+	// units are placed so that their bounding box fits into the image with the margin
+	rows, _ := grid.coords.Dims()
+	polygons, centers := make([][][2]float64, rows), make([][2]float64, rows)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for row := 0; row < rows; row++ {
+		x, y := mul*grid.coords.At(row, 0), 0.0
+		if len(grid.size) == 2 {
+			y = mul * grid.coords.At(row, 1)
+		}
+		polygons[row], centers[row] = unitPolygon(dims, uShape, row, x, y, mul), [2]float64{x, y}
+		for _, v := range polygons[row] {
+			minX, maxX = math.Min(minX, v[0]), math.Max(maxX, v[0])
+			minY, maxY = math.Min(minY, v[1]), math.Max(maxY, v[1])
+		}
+	}
+	xOff, yOff := off-minX, off-minY
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(maxX+xOff+off)), int(math.Ceil(maxY+yOff+off))))
+	var bg color.Color = color.White
+	if s.background != nil {
+		bg = s.background
+	}
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: bg}, image.ZP, draw.Src)
+	for row, vertices := range polygons {
+		for i := range vertices {
+			vertices[i][0] += xOff
+			vertices[i][1] += yOff
+		}
+		r, g, b := s.unitRGB(row, values, minValue, maxValue)
+		fillPolygon(img, vertices, color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255})
+		strokePolygon(img, vertices, s.strokeWidth, s.strokeColor())
+		if row < len(s.markers) && s.markers[row] > 0.0 {
+			fillCircle(img, centers[row][0]+xOff, centers[row][1]+yOff, 0.4*mul*s.markers[row], color.RGBA{R: 255, A: 255})
+		}
+		for i, marker := range s.samples[row] {
+			mx, my := sampleCenter(i, len(s.samples[row]), centers[row][0]+xOff, centers[row][1]+yOff, mul)
+			fillCircle(img, mx, my, 0.12*mul, marker.color())
+		}
+	}
+	// cluster boundaries and trajectory are drawn over all units
+	unitPolygons := make(map[int][][2]float64, rows)
+	for row, vertices := range polygons {
+		unitPolygons[row] = vertices
+	}
+	for _, edge := range s.boundaryEdges(unitPolygons) {
+		strokePolygon(img, edge[:], s.boundaryWidth(), s.strokeColor())
+	}
+	unitCenters := make(map[int][2]float64, rows)
+	for row, c := range centers {
+		unitCenters[row] = [2]float64{c[0] + xOff, c[1] + yOff}
+	}
+	for _, seg := range s.trajectorySegments(unitCenters) {
+		strokePolygon(img, seg[:], 2.0, color.RGBA{R: 255, A: 255})
+		if head := arrowHead(seg[0], seg[1], mul); head != nil {
+			fillPolygon(img, head, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	return png.Encode(writer, img)
+}
+
+// fillPolygon fills the pixels of img whose centers lie inside the closed polygon with the given color
+func fillPolygon(img draw.Image, vertices [][2]float64, c color.Color) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, v := range vertices {
+		minX, maxX = math.Min(minX, v[0]), math.Max(maxX, v[0])
+		minY, maxY = math.Min(minY, v[1]), math.Max(maxY, v[1])
+	}
+	for py := int(math.Floor(minY)); py <= int(math.Ceil(maxY)); py++ {
+		for px := int(math.Floor(minX)); px <= int(math.Ceil(maxX)); px++ {
+			if inPolygon(vertices, float64(px)+0.5, float64(py)+0.5) {
+				img.Set(px, py, c)
+			}
+		}
+	}
+}
+
+// inPolygon checks if the point x, y lies inside the closed polygon using even-odd rule
+func inPolygon(vertices [][2]float64, x, y float64) bool {
+	in := false
+	for i := 1; i < len(vertices); i++ {
+		a, b := vertices[i-1], vertices[i]
+		if (a[1] > y) != (b[1] > y) && x < a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// strokePolygon draws the edges of the closed polygon of the given width into img using the given color.
+// If width is 0, 1 pixel wide edges are drawn.
+func strokePolygon(img draw.Image, vertices [][2]float64, width float64, c color.Color) {
+	w := int(math.Max(1.0, math.Floor(width+0.5)))
+	for i := 1; i < len(vertices); i++ {
+		a, b := vertices[i-1], vertices[i]
+		steps := int(math.Ceil(2 * math.Max(math.Abs(b[0]-a[0]), math.Abs(b[1]-a[1]))))
+		for j := 0; j <= steps; j++ {
+			t := float64(j) / math.Max(1.0, float64(steps))
+			x := int(math.Floor(a[0]+t*(b[0]-a[0]))) - (w-1)/2
+			y := int(math.Floor(a[1]+t*(b[1]-a[1]))) - (w-1)/2
+			draw.Draw(img, image.Rect(x, y, x+w, y+w), &image.Uniform{C: c}, image.ZP, draw.Src)
+		}
+	}
+}
+
+// fillCircle fills the pixels of img whose centers lie inside the circle with the given color
+func fillCircle(img draw.Image, cx, cy, radius float64, c color.Color) {
+	for py := int(math.Floor(cy - radius)); py <= int(math.Ceil(cy+radius)); py++ {
+		for px := int(math.Floor(cx - radius)); px <= int(math.Ceil(cx+radius)); px++ {
+			dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(px, py, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package som
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUMatrixPNG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 1.0, 3.0})
+	var buf bytes.Buffer
+	assert.NoError(UMatrixPNG(cbook, []int{1, 3}, "rectangle", "euclidean", &buf, make(map[int]int)))
+	img, err := png.Decode(&buf)
+	assert.NoError(err)
+	assert.Equal(170, img.Bounds().Dx())
+	assert.Equal(70, img.Bounds().Dy())
+	// units are shaded by their average distances
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBAModel.Convert(img.At(35, 35)))
+	assert.Equal(color.RGBA{R: 127, G: 127, B: 127, A: 255}, color.RGBAModel.Convert(img.At(85, 35)))
+	assert.Equal(color.RGBA{R: 0, G: 0, B: 0, A: 255}, color.RGBAModel.Convert(img.At(135, 35)))
+	// margin is left blank
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBAModel.Convert(img.At(2, 2)))
+	// hexagon grid fits the image
+	buf.Reset()
+	cbook = mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+	assert.NoError(UMatrixPNG(cbook, []int{2, 2}, "hexagon", "euclidean", &buf, make(map[int]int)))
+	_, err = png.Decode(&buf)
+	assert.NoError(err)
+	// unsupported grids
+	assert.Error(UMatrixPNG(mat64.NewDense(8, 1, nil), []int{2, 2, 2}, "rectangle", "euclidean", &buf, make(map[int]int)))
+}
+
+func TestComponentPlanePNG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 2, []float64{0.0, 2.0, 1.0, 1.0, 2.0, 0.0})
+	var buf bytes.Buffer
+	assert.NoError(ComponentPlanePNG(cbook, []int{3}, "rectangle", 1, &buf, make(map[int]int)))
+	img, err := png.Decode(&buf)
+	assert.NoError(err)
+	// 1D grids are rendered as a ribbon
+	assert.Equal(170, img.Bounds().Dx())
+	assert.Equal(70, img.Bounds().Dy())
+	assert.Equal(color.RGBA{R: 0, G: 0, B: 0, A: 255}, color.RGBAModel.Convert(img.At(35, 35)))
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBAModel.Convert(img.At(135, 35)))
+	assert.EqualError(ComponentPlanePNG(cbook, []int{3}, "rectangle", 2, &buf, make(map[int]int)), "invalid feature index: 2")
+	// map formats
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	buf.Reset()
+	assert.NoError(m.ComponentPlane(&buf, 1, "png", "Plane"))
+	_, err = png.Decode(&buf)
+	assert.NoError(err)
+	buf.Reset()
+	assert.NoError(m.UMatrix(&buf, nil, nil, "png", "UMatrix"))
+	_, err = png.Decode(&buf)
+	assert.NoError(err)
+	buf.Reset()
+	assert.NoError(m.HitsUMatrix(&buf, cbook, "png", "Hits"))
+	img, err = png.Decode(&buf)
+	assert.NoError(err)
+	// hit markers are drawn in the unit centers
+	assert.Equal(color.RGBA{R: 255, G: 0, B: 0, A: 255}, color.RGBAModel.Convert(img.At(85, 35)))
+}
+
+func TestRenderConfigPNG(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(2, 1, []float64{0.0, 1.0}),
+		grid:     grid,
+		metric:   "euclidean",
+		render: &RenderConfig{
+			CellSize:    20.0,
+			Margin:      5.0,
+			StrokeWidth: 3.0,
+			StrokeColor: color.RGBA{R: 255, A: 255},
+			Background:  color.RGBA{B: 255, A: 255},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(m.UMatrix(&buf, nil, nil, "png", "Options"))
+	img, err := png.Decode(&buf)
+	assert.NoError(err)
+	assert.Equal(50, img.Bounds().Dx())
+	assert.Equal(30, img.Bounds().Dy())
+	assert.Equal(color.RGBA{B: 255, A: 255}, color.RGBAModel.Convert(img.At(1, 1)))
+	// unit outlines are 3 pixels wide
+	assert.Equal(color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(5, 15)))
+	assert.Equal(color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(4, 15)))
+	assert.Equal(color.RGBA{R: 255, A: 255}, color.RGBAModel.Convert(img.At(6, 15)))
+	assert.Equal(color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBAModel.Convert(img.At(15, 15)))
+}
@@ -0,0 +1,53 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Project maps every vector stored in data rows to map grid coordinates so that the data samples can
+// be plotted on top of the map, e.g. on top of its U-Matrix. If k is 1 each sample is placed at
+// its BMU coordinates. If k is higher than 1 each sample is placed between its k Best Match Units:
+// its coordinates are the average of the units coordinates weighted by inverse unit distances to the sample.
+// The returned matrix has as many rows as data and as many columns as the grid coordinates.
+// It returns error if data is nil, if its dimension is different from the map codebook dimension or
+// if k is not a positive integer or is higher than the number of map units.
+func (m Map) Project(data *mat64.Dense, k int) (*mat64.Dense, error) {
+	if data == nil {
+		return nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if units := m.grid.Units(); k <= 0 || k > units {
+		return nil, fmt.Errorf("invalid number of best match units: %d", k)
+	}
+	rows, _ := data.Dims()
+	coords := m.grid.coords
+	_, cols := coords.Dims()
+	proj := mat64.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		units, dists, err := KBMU(m.codebook, data.RowView(i), k, m.metric)
+		if err != nil {
+			return nil, err
+		}
+		// sample identical to its BMU is placed at the BMU
+		if dists[0] == 0 {
+			units = units[:1]
+		}
+		var total float64
+		for j, unit := range units {
+			w := 1.0
+			if len(units) > 1 {
+				w = 1.0 / dists[j]
+			}
+			for c := 0; c < cols; c++ {
+				proj.Set(i, c, proj.At(i, c)+w*coords.At(unit, c))
+			}
+			total += w
+		}
+		for c := 0; c < cols; c++ {
+			proj.Set(i, c, proj.At(i, c)/total)
+		}
+	}
+
+	return proj, nil
+}
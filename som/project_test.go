@@ -0,0 +1,52 @@
+package som
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	coords := grid.Coords()
+	data := mat64.NewDense(2, 1, []float64{1.0, 0.25})
+	// samples are placed at their BMUs
+	proj, err := m.Project(data, 1)
+	assert.NoError(err)
+	rows, cols := proj.Dims()
+	assert.Equal(2, rows)
+	assert.Equal(2, cols)
+	for c := 0; c < cols; c++ {
+		assert.Equal(coords.At(1, c), proj.At(0, c))
+		assert.Equal(coords.At(0, c), proj.At(1, c))
+	}
+	// samples are interpolated between their BMUs
+	proj, err = m.Project(data, 2)
+	assert.NoError(err)
+	for c := 0; c < cols; c++ {
+		assert.Equal(coords.At(1, c), proj.At(0, c))
+		assert.InDelta(0.75*coords.At(0, c)+0.25*coords.At(1, c), proj.At(1, c), 1e-9)
+	}
+	// invalid parameters
+	proj, err = m.Project(nil, 1)
+	assert.Nil(proj)
+	assert.Error(err)
+	for _, k := range []int{0, 5} {
+		proj, err = m.Project(data, k)
+		assert.Nil(proj)
+		assert.EqualError(err, fmt.Sprintf("invalid number of best match units: %d", k))
+	}
+	proj, err = m.Project(mat64.NewDense(2, 2, nil), 1)
+	assert.Nil(proj)
+	assert.Error(err)
+}
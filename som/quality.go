@@ -13,6 +13,13 @@ import (
 // data vectors could not be calculated. This could be because the dimensions of passed in data and
 // codebook matrix are not the same. When the error is returned, quantization error is set to -1.0
 func QuantError(data, codebook *mat64.Dense) (float64, error) {
+	return QuantizationError(codebook, data, "euclidean")
+}
+
+// QuantizationError computes quantization error of codebook for the supplied data set, i.e. the mean
+// distance of data samples stored in data rows to their BMUs computed using the supplied distance metric.
+// It fails in the same way as QuantError. When the error is returned, quantization error is set to -1.0
+func QuantizationError(codebook, data *mat64.Dense, metric string) (float64, error) {
 	// data can't be nil
 	if data == nil {
 		return -1.0, fmt.Errorf("invalid data supplied: %v", data)
@@ -21,23 +28,16 @@ func QuantError(data, codebook *mat64.Dense) (float64, error) {
 	if codebook == nil {
 		return -1.0, fmt.Errorf("invalid codebook supplied: %v", codebook)
 	}
+	_, dists, err := MapRows(codebook, data, metric)
+	if err != nil {
+		return -1.0, err
+	}
 	var qErr float64
-	metric := "euclidean"
-	rows, _ := data.Dims()
-	for i := 0; i < rows; i++ {
-		bmuIdx, err := ClosestVec(metric, data.RawRowView(i), codebook)
-		if err != nil {
-			return -1.0, err
-		}
-		// get the BMU distance -- no need to check for errors here
-		d, err := Distance(metric, data.RawRowView(i), codebook.RawRowView(bmuIdx))
-		if err != nil {
-			return -1.0, err
-		}
+	for _, d := range dists {
 		qErr += d
 	}
 	// return the average distance
-	return qErr / float64(rows), nil
+	return qErr / float64(len(dists)), nil
 }
 
 // TopoProduct calculates topographic product for given codebook and grid.
@@ -45,23 +45,44 @@ func QuantError(data, codebook *mat64.Dense) (float64, error) {
 // is not the same as the number of grid rows. If any two codebooks turn out to be the same
 // TopoProduct returns +Inf - this can happen when map is trained using batch algorithm.
 func TopoProduct(codebook, grid *mat64.Dense) (float64, error) {
+	return TopographicProduct(codebook, grid, "euclidean")
+}
+
+// TopographicProduct calculates topographic product of codebook whose vectors belong to grid units with
+// coordinates stored in gridCoords rows. Distances between codebook vectors are computed using the supplied metric.
+// Topographic product close to 0 means the map dimensionality matches the intrinsic dimensionality of the data:
+// negative values indicate the map dimensionality is too low, positive values indicate it is too high.
+// Comparing topographic products of maps of different sizes and shapes trained on the same data helps to pick the best one.
+// It fails in the same way as TopoProduct.
+func TopographicProduct(codebook, gridCoords *mat64.Dense, metric string) (float64, error) {
 	// codebook can't be nil
 	if codebook == nil {
 		return 0.0, fmt.Errorf("invalid codebook supplied: %v", codebook)
 	}
 	// grid can't be nil
-	if grid == nil {
-		return 0.0, fmt.Errorf("invalid grid supplied: %v", grid)
+	if gridCoords == nil {
+		return 0.0, fmt.Errorf("invalid grid supplied: %v", gridCoords)
 	}
+	// unit distance matrix -- no need to check for error here
+	uDistMx, _ := DistanceMx("euclidean", gridCoords)
+
+	return topoProduct(metric, codebook, uDistMx)
+}
+
+// topoProduct calculates topographic product for given codebook and grid unit distance matrix
+// using the supplied distance metric to compute distances between codebook vectors.
+func topoProduct(metric string, codebook, uDistMx *mat64.Dense) (float64, error) {
 	// if grid and codebook don't match, throw error
-	gRows, _ := grid.Dims()
+	gRows, _ := uDistMx.Dims()
 	cRows, _ := codebook.Dims()
 	if gRows != cRows {
 		return 0.0, fmt.Errorf("Grid and codebook dimension mismatch")
 	}
-	// unit and codebook distance matrices -- no need to check for error here
-	uDistMx, _ := DistanceMx("euclidean", grid)
-	cDistMx, _ := DistanceMx("euclidean", codebook)
+	// codebook distance matrix
+	cDistMx, err := DistanceMx(metric, codebook)
+	if err != nil {
+		return 0.0, err
+	}
 	// tp is the topographic product
 	var tp float64
 	// loop through all neurons
@@ -105,6 +126,15 @@ func TopoProduct(codebook, grid *mat64.Dense) (float64, error) {
 // TopoError calculate topographice error for given data set, codebook and grid and returns it
 // It returns error if either data, codebook or grid are nil or if their dimensions are mismatched.
 func TopoError(data, codebook, grid *mat64.Dense) (float64, error) {
+	return TopographicError(codebook, data, grid, "euclidean")
+}
+
+// TopographicError computes topographic error of codebook for the supplied data set, i.e. the fraction of data
+// samples stored in data rows whose first and second BMUs are not neighbours on the grid. The BMUs are found
+// using the supplied distance metric. gridCoords holds coordinates of the grid units the codebook vectors belong to.
+// It returns error if either data, codebook or gridCoords are nil or if their dimensions are mismatched.
+// When the error is returned, topographic error is set to -1.0
+func TopographicError(codebook, data, gridCoords *mat64.Dense, metric string) (float64, error) {
 	// data can't be nil
 	if data == nil {
 		return -1.0, fmt.Errorf("invalid data supplied: %v", data)
@@ -114,16 +144,27 @@ func TopoError(data, codebook, grid *mat64.Dense) (float64, error) {
 		return -1.0, fmt.Errorf("invalid codebook supplied: %v", codebook)
 	}
 	// grid can't be nil
-	if grid == nil {
-		return -1.0, fmt.Errorf("invalid grid supplied: %v", grid)
+	if gridCoords == nil {
+		return -1.0, fmt.Errorf("invalid grid supplied: %v", gridCoords)
+	}
+	// every codebook vector must have its grid unit
+	if cRows, _ := codebook.Dims(); cRows != gridCoords.RawMatrix().Rows {
+		return -1.0, fmt.Errorf("Grid and codebook dimension mismatch")
 	}
 	// unit distance matrix -- no need to check for error
-	uDistMx, _ := DistanceMx("euclidean", grid)
+	uDistMx, _ := DistanceMx("euclidean", gridCoords)
+
+	return topoError(metric, data, codebook, uDistMx)
+}
+
+// topoError calculates topographic error for given data set, codebook and grid unit distance
+// matrix using the supplied distance metric to find BMUs of data samples.
+func topoError(metric string, data, codebook, uDistMx *mat64.Dense) (float64, error) {
 	var te float64
 	// iterate through all data samples
 	rows, _ := data.Dims()
 	for i := 0; i < rows; i++ {
-		closest, err := ClosestNVec("euclidean", 2, data.RawRowView(i), codebook)
+		closest, err := ClosestNVec(metric, 2, data.RawRowView(i), codebook)
 		if err != nil {
 			return -1.0, err
 		}
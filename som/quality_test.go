@@ -45,6 +45,34 @@ func TestQuantError(t *testing.T) {
 	assert.True(qe >= 0.0)
 }
 
+func TestQuantizationError(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(2, 2, []float64{0.0, 0.0, 10.0, 10.0})
+	data := mat64.NewDense(3, 2, []float64{3.0, 4.0, 10.0, 10.0, 10.0, 12.0})
+	qe, err := QuantizationError(cbook, data, "euclidean")
+	assert.NoError(err)
+	assert.InDelta(7.0/3.0, qe, 1e-9)
+	qe, err = QuantizationError(cbook, data, "manhattan")
+	assert.NoError(err)
+	assert.InDelta(3.0, qe, 1e-9)
+	// euclidean metric is used by QuantError
+	qe2, err := QuantError(data, cbook)
+	assert.NoError(err)
+	assert.InDelta(7.0/3.0, qe2, 1e-9)
+	// nil parameters
+	qe, err = QuantizationError(nil, data, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, qe)
+	qe, err = QuantizationError(cbook, nil, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, qe)
+	// mismatched dimensions
+	qe, err = QuantizationError(cbook, mat64.NewDense(1, 3, nil), "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, qe)
+}
+
 func TestTopoProduct(t *testing.T) {
 	assert := assert.New(t)
 
@@ -102,3 +130,60 @@ func TestTopoError(t *testing.T) {
 	assert.NoError(err)
 	assert.True(te > 0.0)
 }
+
+func TestTopographicError(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := GridCoords("rectangle", []int{1, 3})
+	assert.NoError(err)
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 10.0, 1.0})
+	// the first sample BMUs are not grid neighbours
+	data := mat64.NewDense(2, 1, []float64{0.4, 9.0})
+	te, err := TopographicError(cbook, data, grid, "euclidean")
+	assert.NoError(err)
+	assert.Equal(0.5, te)
+	te, err = TopographicError(cbook, data, grid, "manhattan")
+	assert.NoError(err)
+	assert.Equal(0.5, te)
+	// mismatched codebook and grid
+	te, err = TopographicError(mat64.NewDense(2, 1, []float64{0.0, 1.0}), data, grid, "euclidean")
+	assert.EqualError(err, "Grid and codebook dimension mismatch")
+	assert.Equal(-1.0, te)
+	// nil parameters
+	te, err = TopographicError(nil, data, grid, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, te)
+	te, err = TopographicError(cbook, nil, grid, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, te)
+	te, err = TopographicError(cbook, data, nil, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, te)
+}
+
+func TestTopographicProduct(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := GridCoords("rectangle", []int{1, 3})
+	assert.NoError(err)
+	// ordered chain preserves the topology
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 1.0, 2.0})
+	tp, err := TopographicProduct(cbook, grid, "euclidean")
+	assert.NoError(err)
+	assert.InDelta(0.0, tp, 1e-9)
+	tp, err = TopographicProduct(cbook, grid, "manhattan")
+	assert.NoError(err)
+	assert.InDelta(0.0, tp, 1e-9)
+	// twisted chain does not
+	cbook = mat64.NewDense(3, 1, []float64{0.0, 2.0, 1.0})
+	tp, err = TopographicProduct(cbook, grid, "euclidean")
+	assert.NoError(err)
+	assert.NotEqual(0.0, tp)
+	// nil parameters
+	tp, err = TopographicProduct(nil, grid, "euclidean")
+	assert.Error(err)
+	assert.Equal(0.0, tp)
+	tp, err = TopographicProduct(cbook, nil, "euclidean")
+	assert.Error(err)
+	assert.Equal(0.0, tp)
+}
@@ -9,8 +9,8 @@ import (
 const MinRadius = 1.0
 
 // Radius is a decay function for the SOM neighbourhood radius parameter.
-// It supports exponential and linear decay strategies denoted as "exp" and "lin".
-// Any other strategy defaults to "exp". At the first iteration the function returns
+// It supports exponential, linear, inverse-time and power-law decay strategies denoted as
+// "exp", "lin", "inv" and "pow". Any other strategy defaults to "exp". At the first iteration the function returns
 // the initRadius, at totalIterations-1 it returns MinRadius.
 // It returns error if initRadius is not a positive integer
 func Radius(iteration, totalIterations int, strategy string, initRadius float64) (float64, error) {
@@ -22,6 +22,10 @@ func Radius(iteration, totalIterations int, strategy string, initRadius float64)
 		return expRadius(iteration, totalIterations, initRadius), nil
 	case "lin":
 		return linRadius(iteration, totalIterations, initRadius), nil
+	case "inv":
+		return invRadius(iteration, totalIterations, initRadius), nil
+	case "pow":
+		return powRadius(iteration, totalIterations, initRadius), nil
 	default:
 		return expRadius(iteration, totalIterations, initRadius), nil
 	}
@@ -35,3 +39,32 @@ func expRadius(iteration, totalIterations int, initRadius float64) float64 {
 func linRadius(iteration, totalIterations int, initRadius float64) float64 {
 	return initRadius - float64(iteration)/float64(totalIterations-1)*(initRadius-MinRadius)
 }
+
+func invRadius(iteration, totalIterations int, initRadius float64) float64 {
+	lambda := float64(totalIterations-1) / (initRadius/MinRadius - 1.0)
+	return initRadius / (1.0 + float64(iteration)/lambda)
+}
+
+func powRadius(iteration, totalIterations int, initRadius float64) float64 {
+	k := math.Log(initRadius/MinRadius) / math.Log(float64(totalIterations))
+	return initRadius * math.Pow(1.0+float64(iteration), -k)
+}
+
+// radius returns the radius at the given training iteration out of iters iterations
+// using the decay schedule or strategy and parameters of the training configuration
+func (c *TrainConfig) radius(iteration, iters int) float64 {
+	if c.RSchedule != nil {
+		return c.RSchedule.Value(iteration, iters)
+	}
+	if c.RDecayTime > 0 {
+		switch c.RDecay {
+		case "exp":
+			return c.Radius * math.Exp(-float64(iteration)/c.RDecayTime)
+		case "inv":
+			return c.Radius / (1.0 + float64(iteration)/c.RDecayTime)
+		}
+	}
+	// no need to check for errors: initial radius is checked by config validation
+	r, _ := Radius(iteration, iters, c.RDecay, c.Radius)
+	return r
+}
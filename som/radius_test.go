@@ -15,6 +15,14 @@ func TestLinRadius(t *testing.T) {
 	testRadius(t, "lin")
 }
 
+func TestInvRadius(t *testing.T) {
+	testRadius(t, "inv")
+}
+
+func TestPowRadius(t *testing.T) {
+	testRadius(t, "pow")
+}
+
 func TestDefaultRadius(t *testing.T) {
 	testRadius(t, "some other")
 }
@@ -42,3 +50,33 @@ func testRadius(t *testing.T, strategy string) {
 	assert.NoError(err)
 
 }
+
+func TestTrainConfigRadius(t *testing.T) {
+	assert := assert.New(t)
+
+	tc := &TrainConfig{Radius: 10.0, RDecay: "exp"}
+	// default exponential decay ends at MinRadius
+	assert.InDelta(MinRadius, tc.radius(99, 100), 0.0001)
+	// decay time constant
+	tc.RDecayTime = 20.0
+	assert.Equal(10.0, tc.radius(0, 100))
+	assert.InDelta(10.0*math.Exp(-1.0), tc.radius(20, 100), 0.0001)
+	tc.RDecay = "inv"
+	assert.InDelta(5.0, tc.radius(20, 100), 0.0001)
+	// decay time constant is ignored by linear decay
+	tc.RDecay = "lin"
+	assert.InDelta(MinRadius, tc.radius(99, 100), 0.0001)
+}
+
+func TestTrainConfigRSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	// step schedule halves the radius every 10 iterations
+	step := DecayFunc(func(iteration, totalIterations int) float64 {
+		return 8.0 / math.Pow(2, float64(iteration/10))
+	})
+	tc := &TrainConfig{Radius: 10.0, RDecay: "exp", RSchedule: step}
+	assert.Equal(8.0, tc.radius(9, 100))
+	assert.Equal(4.0, tc.radius(10, 100))
+	assert.Equal(1.0, tc.radius(35, 100))
+}
@@ -0,0 +1,179 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// RecSOM is a Recursive Self Organizing Map for sequence data.
+// Every unit holds an input weight vector and a context vector which is compared with the map
+// activation at the previous time step, so units respond to the input along with its history.
+type RecSOM struct {
+	// m holds SOM grid and input weight vectors
+	m *Map
+	// context holds unit context vectors: units x units
+	context *mat64.Dense
+	// alpha is the weight of input distance
+	alpha float64
+	// beta is the weight of context distance
+	beta float64
+	// activation holds map activation at the last processed time step
+	activation []float64
+}
+
+// NewRecSOM creates new Recursive SOM based on the provided configuration.
+// Input weights are initialized by the configured codebook initialization function
+// and unit contexts are initialized to zero vectors.
+// NewRecSOM returns error if the provided configuration is not valid or if the map could not be created.
+func NewRecSOM(c *RecSOMConfig, data *mat64.Dense) (*RecSOM, error) {
+	if err := validateRecSOMConfig(c); err != nil {
+		return nil, err
+	}
+	m, err := NewMap(c.Map, data)
+	if err != nil {
+		return nil, err
+	}
+	units := m.grid.Units()
+
+	return &RecSOM{
+		m:          m,
+		context:    mat64.NewDense(units, units, nil),
+		alpha:      c.Alpha,
+		beta:       c.Beta,
+		activation: make([]float64, units),
+	}, nil
+}
+
+// Map returns SOM which holds RecSOM grid and input weight vectors
+func (r RecSOM) Map() *Map {
+	return r.m
+}
+
+// Context returns a matrix which contains unit context vectors stored row by row
+func (r RecSOM) Context() mat64.Matrix {
+	return r.context
+}
+
+// Activation returns map activation at the last processed time step
+func (r RecSOM) Activation() []float64 {
+	return r.activation
+}
+
+// Reset resets map activation so that the next processed sequence starts without any history
+func (r *RecSOM) Reset() {
+	for i := range r.activation {
+		r.activation[i] = 0.0
+	}
+}
+
+// Train runs sequential RecSOM training for a given number of time steps. Data rows are processed
+// in their order as a single sequence which is repeated if iters exceeds the number of rows.
+// BMU of every time step is the unit with the smallest distance alpha*|x-w|^2 + beta*|y-c|^2 where
+// y is the map activation at the previous time step; the activation of every unit is exp(-distance).
+// Input weights and contexts of units in the BMU neighbourhood are moved towards x and y, respectively.
+// The training continues from the current map activation. Only sequential training algorithm is supported.
+// It returns error if the training configuration is invalid or if the data dimensions don't match the map.
+func (r *RecSOM) Train(tc *TrainConfig, data *mat64.Dense, iters int) error {
+	if err := validateTemporalTraining(tc, data, r.m.codebook, iters); err != nil {
+		return err
+	}
+	unitDist, err := r.m.UnitDist()
+	if err != nil {
+		return err
+	}
+	nb := tc.neighbourhood()
+	rows, _ := data.Dims()
+	for i := 0; i < iters; i++ {
+		sample := data.RawRowView(i % rows)
+		act, bmu := r.activate(sample, r.activation)
+		lRate := tc.lRate(i, iters)
+		radius := tc.radius(i, iters)
+		bmuDists := unitDist.RawRowView(bmu)
+		for j := 0; j < len(bmuDists); j++ {
+			nghb := unitWeight(nb, bmuDists[j], radius)
+			if nghb == 0.0 {
+				continue
+			}
+			l := math.Min(lRate*nghb, 1.0)
+			move(r.m.codebook.RawRowView(j), sample, l)
+			move(r.context.RawRowView(j), r.activation, l)
+		}
+		r.activation = act
+	}
+
+	return nil
+}
+
+// BMUs returns a slice which contains BMU indices of every time step of the sequence stored in data rows.
+// The sequence is processed from the current map activation which is updated accordingly.
+// It returns error if the data is nil or if its dimensions don't match the map input weights.
+func (r *RecSOM) BMUs(data *mat64.Dense) ([]int, error) {
+	if err := validateTemporalData(data, r.m.codebook); err != nil {
+		return nil, err
+	}
+	rows, _ := data.Dims()
+	bmus := make([]int, rows)
+	for i := 0; i < rows; i++ {
+		r.activation, bmus[i] = r.activate(data.RawRowView(i), r.activation)
+	}
+	return bmus, nil
+}
+
+// activate computes map activation for input x given the previous activation prev.
+// It returns the new activation along with BMU index.
+func (r RecSOM) activate(x, prev []float64) ([]float64, int) {
+	units, _ := r.m.codebook.Dims()
+	act := make([]float64, units)
+	bmu, minDist := 0, math.Inf(1)
+	for i := 0; i < units; i++ {
+		d := r.alpha*sqDist(x, r.m.codebook.RawRowView(i)) + r.beta*sqDist(prev, r.context.RawRowView(i))
+		if d < minDist {
+			bmu, minDist = i, d
+		}
+		act[i] = math.Exp(-d)
+	}
+	return act, bmu
+}
+
+// validateTemporalTraining validates temporal SOM training parameters
+// It returns error if any of them is invalid
+func validateTemporalTraining(tc *TrainConfig, data, codebook *mat64.Dense, iters int) error {
+	// number of iterations must be a positive integer
+	if iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", iters)
+	}
+	if err := validateTemporalData(data, codebook); err != nil {
+		return err
+	}
+	if err := validateTrainConfig(tc); err != nil {
+		return err
+	}
+	if tc.Algorithm != "seq" {
+		return fmt.Errorf("unsupported temporal SOM training algorithm: %s", tc.Algorithm)
+	}
+	return nil
+}
+
+// validateTemporalData validates sequence data processed by temporal SOM with the given codebook
+// It returns error if the data is nil or if its dimensions don't match the codebook
+func validateTemporalData(data, codebook *mat64.Dense) error {
+	// nil data passed in
+	if data == nil {
+		return fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if _, cols := data.Dims(); cols != codebook.RawMatrix().Cols {
+		return fmt.Errorf("invalid data dimensions: %d", cols)
+	}
+	return nil
+}
+
+// sqDist computes squared euclidean distance between vectors a and b
+func sqDist(a, b []float64) float64 {
+	d := 0.0
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
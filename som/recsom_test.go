@@ -0,0 +1,67 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeSequence returns a sequence which repeats the pattern 0, 0, 1
+func makeSequence(repeats int) *mat64.Dense {
+	seq := []float64{}
+	for i := 0; i < repeats; i++ {
+		seq = append(seq, 0.0, 0.0, 1.0)
+	}
+	return mat64.NewDense(len(seq), 1, seq)
+}
+
+func makeTemporalTrainConfig() *TrainConfig {
+	return &TrainConfig{
+		Algorithm: "seq",
+		Radius:    2.0,
+		RDecay:    "lin",
+		NeighbFn:  Gaussian,
+		LRate:     0.3,
+		LDecay:    "lin",
+	}
+}
+
+func TestRecSOM(t *testing.T) {
+	assert := assert.New(t)
+
+	data := makeSequence(100)
+	c := &RecSOMConfig{
+		Map: &MapConfig{
+			Grid: &GridConfig{Size: []int{6}, Type: "planar", UShape: "rectangle"},
+			Cb:   &CbConfig{Dim: 1, InitFunc: RandInit},
+		},
+		Alpha: 2.0,
+		Beta:  0.5,
+	}
+	r, err := NewRecSOM(c, data)
+	assert.NoError(err)
+	assert.NotNil(r)
+	rows, cols := r.Context().Dims()
+	assert.Equal(6, rows)
+	assert.Equal(6, cols)
+	assert.NoError(r.Train(makeTemporalTrainConfig(), data, 3000))
+	assert.Len(r.Activation(), 6)
+	// the two zero inputs of the pattern are told apart by their context
+	r.Reset()
+	bmus, err := r.BMUs(makeSequence(3))
+	assert.NoError(err)
+	assert.Len(bmus, 9)
+	assert.NotEqual(bmus[6], bmus[7])
+	// invalid parameters
+	tc := makeTemporalTrainConfig()
+	assert.Error(r.Train(tc, data, 0))
+	assert.Error(r.Train(tc, nil, 10))
+	assert.EqualError(r.Train(tc, mat64.NewDense(2, 2, nil), 10), "invalid data dimensions: 2")
+	tc.Algorithm = "batch"
+	assert.EqualError(r.Train(tc, data, 10), "unsupported temporal SOM training algorithm: batch")
+	c.Alpha = 0.0
+	r, err = NewRecSOM(c, data)
+	assert.Nil(r)
+	assert.EqualError(err, "invalid RecSOM alpha: 0.000000")
+}
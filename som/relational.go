@@ -0,0 +1,214 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// RelationalSOM is a Self Organizing Map trained on pairwise dissimilarities between data samples
+// instead of their feature vectors. Every unit prototype is a convex combination of the data samples
+// represented by its coefficients, which allows to compute distances between the prototypes and
+// the samples from the dissimilarities only.
+type RelationalSOM struct {
+	// grid is SOM grid
+	grid *Grid
+	// diss holds dissimilarities between training samples: samples x samples
+	diss *mat64.Dense
+	// coeffs holds prototype coefficients: units x samples
+	coeffs *mat64.Dense
+}
+
+// NewRelationalSOM creates new relational SOM for data samples whose pairwise dissimilarities are
+// stored in diss. The dissimilarities are expected to be squared distances: relational SOM trained
+// on squared euclidean distances is equivalent to batch SOM trained on the feature vectors.
+// Every prototype is initialized to a randomly chosen data sample; the samples are chosen without
+// replacement if there are at least as many samples as map units.
+// NewRelationalSOM returns error if the configuration is invalid or if diss is not a square
+// symmetric non-negative matrix with zero diagonal.
+func NewRelationalSOM(c *RelationalConfig, diss *mat64.Dense) (*RelationalSOM, error) {
+	if err := validateDissimilarity(diss); err != nil {
+		return nil, err
+	}
+	grid, err := NewGrid(c.Grid)
+	if err != nil {
+		return nil, err
+	}
+	samples, _ := diss.Dims()
+	units := grid.Units()
+	r := rand.New(randSource(c.Source))
+	perm := r.Perm(samples)
+	coeffs := mat64.NewDense(units, samples, nil)
+	for i := 0; i < units; i++ {
+		sample := r.Intn(samples)
+		if i < samples {
+			sample = perm[i]
+		}
+		coeffs.Set(i, sample, 1.0)
+	}
+
+	return &RelationalSOM{
+		grid:   grid,
+		diss:   diss,
+		coeffs: coeffs,
+	}, nil
+}
+
+// Grid returns relational SOM grid
+func (r RelationalSOM) Grid() *Grid {
+	return r.grid
+}
+
+// Coeffs returns a matrix which contains prototype coefficients stored row by row.
+// Every row holds the weights of the data samples in the unit prototype; the weights sum up to 1.
+func (r RelationalSOM) Coeffs() mat64.Matrix {
+	return r.coeffs
+}
+
+// Train runs batch relational SOM training for a given number of iterations. In every iteration
+// each prototype is replaced by the neighbourhood weighted mean of the data samples.
+// Only batch training algorithm is supported.
+// It returns error if the training configuration is invalid.
+func (r *RelationalSOM) Train(tc *TrainConfig, iters int) error {
+	// number of iterations must be a positive integer
+	if iters <= 0 {
+		return fmt.Errorf("invalid number of iterations: %d", iters)
+	}
+	if err := validateTrainConfig(tc); err != nil {
+		return err
+	}
+	if tc.Algorithm != "batch" {
+		return fmt.Errorf("unsupported relational SOM training algorithm: %s", tc.Algorithm)
+	}
+	unitDist, err := r.grid.UnitDist()
+	if err != nil {
+		return err
+	}
+	nb := tc.neighbourhood()
+	units, samples := r.coeffs.Dims()
+	for i := 0; i < iters; i++ {
+		bmus := r.BMUs()
+		radius := tc.radius(i, iters)
+		for k := 0; k < units; k++ {
+			coeffs := make([]float64, samples)
+			sum := 0.0
+			for j, bmu := range bmus {
+				// negative weights can't be averaged
+				if w := nb.Weight(unitDist.At(k, bmu), radius); w > 0.0 {
+					coeffs[j] = w
+					sum += w
+				}
+			}
+			// prototypes without any samples in their neighbourhood are left intact
+			if sum == 0.0 {
+				continue
+			}
+			for j := range coeffs {
+				coeffs[j] /= sum
+			}
+			r.coeffs.SetRow(k, coeffs)
+		}
+	}
+
+	return nil
+}
+
+// BMUs returns a slice which contains BMU indices of all training samples
+func (r RelationalSOM) BMUs() []int {
+	dist := r.distances()
+	units, samples := dist.Dims()
+	bmus := make([]int, samples)
+	for j := 0; j < samples; j++ {
+		minDist := math.Inf(1)
+		for k := 0; k < units; k++ {
+			if d := dist.At(k, j); d < minDist {
+				bmus[j], minDist = k, d
+			}
+		}
+	}
+	return bmus
+}
+
+// BMU returns BMU index of a new data sample whose dissimilarities to all training samples are stored in diss.
+// It returns error if the number of dissimilarities doesn't match the number of training samples.
+func (r RelationalSOM) BMU(diss []float64) (int, error) {
+	units, samples := r.coeffs.Dims()
+	if len(diss) != samples {
+		return -1, fmt.Errorf("invalid number of dissimilarities: %d", len(diss))
+	}
+	self := r.selfDist()
+	bmu, minDist := -1, math.Inf(1)
+	for k := 0; k < units; k++ {
+		d := mat64.Dot(mat64.NewVector(samples, diss), r.coeffs.RowView(k)) - 0.5*self[k]
+		if d < minDist {
+			bmu, minDist = k, d
+		}
+	}
+	return bmu, nil
+}
+
+// QuantError computes mean dissimilarity between the training samples and their BMU prototypes
+func (r RelationalSOM) QuantError() float64 {
+	dist := r.distances()
+	bmus := r.BMUs()
+	qErr := 0.0
+	for j, bmu := range bmus {
+		qErr += dist.At(bmu, j)
+	}
+	return qErr / float64(len(bmus))
+}
+
+// distances returns a matrix of dissimilarities between prototypes and training samples:
+// d(k, j) = (D*a_k)_j - 0.5*a_k'*D*a_k where a_k are the coefficients of k-th prototype
+func (r RelationalSOM) distances() *mat64.Dense {
+	dist := new(mat64.Dense)
+	dist.Mul(r.coeffs, r.diss)
+	self := r.selfDist()
+	units, samples := dist.Dims()
+	for k := 0; k < units; k++ {
+		for j := 0; j < samples; j++ {
+			dist.Set(k, j, dist.At(k, j)-0.5*self[k])
+		}
+	}
+	return dist
+}
+
+// selfDist returns a_k'*D*a_k for every prototype k
+func (r RelationalSOM) selfDist() []float64 {
+	units, _ := r.coeffs.Dims()
+	da := new(mat64.Dense)
+	da.Mul(r.coeffs, r.diss)
+	self := make([]float64, units)
+	for k := 0; k < units; k++ {
+		self[k] = mat64.Dot(da.RowView(k), r.coeffs.RowView(k))
+	}
+	return self
+}
+
+// validateDissimilarity validates relational SOM dissimilarity matrix
+// It returns error if the matrix is not a square symmetric non-negative matrix with zero diagonal
+func validateDissimilarity(diss *mat64.Dense) error {
+	if diss == nil {
+		return fmt.Errorf("invalid dissimilarity matrix: %v", diss)
+	}
+	rows, cols := diss.Dims()
+	if rows != cols {
+		return fmt.Errorf("dissimilarity matrix must be a square matrix: %dx%d", rows, cols)
+	}
+	for i := 0; i < rows; i++ {
+		if diss.At(i, i) != 0.0 {
+			return fmt.Errorf("dissimilarity matrix must have zero diagonal")
+		}
+		for j := 0; j < cols; j++ {
+			if diss.At(i, j) < 0.0 {
+				return fmt.Errorf("dissimilarity matrix contains negative value: %f", diss.At(i, j))
+			}
+			if diss.At(i, j) != diss.At(j, i) {
+				return fmt.Errorf("dissimilarity matrix must be symmetric")
+			}
+		}
+	}
+	return nil
+}
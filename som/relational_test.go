@@ -0,0 +1,76 @@
+package som
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationalSOM(t *testing.T) {
+	assert := assert.New(t)
+
+	// two clusters of 1D samples
+	points := []float64{0.0, 0.1, 0.2, 5.0, 5.1, 5.2}
+	n := len(points)
+	diss := mat64.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			diss.Set(i, j, (points[i]-points[j])*(points[i]-points[j]))
+		}
+	}
+	c := &RelationalConfig{
+		Grid:   &GridConfig{Size: []int{2}, Type: "planar", UShape: "rectangle"},
+		Source: rand.NewSource(1),
+	}
+	r, err := NewRelationalSOM(c, diss)
+	assert.NoError(err)
+	assert.NotNil(r)
+	assert.Equal(2, r.Grid().Units())
+	tc := &TrainConfig{
+		Algorithm: "batch",
+		RDecay:    "lin",
+		NeighbFn:  Bubble,
+		// neighbourhood contains BMU only
+		RSchedule: DecayFunc(func(iteration, totalIterations int) float64 { return 0.5 }),
+		LRate:     0.5,
+		LDecay:    "lin",
+	}
+	assert.NoError(r.Train(tc, 10))
+	// every prototype is the mean of one cluster
+	bmus := r.BMUs()
+	assert.Equal(bmus[0], bmus[1])
+	assert.Equal(bmus[0], bmus[2])
+	assert.Equal(bmus[3], bmus[4])
+	assert.NotEqual(bmus[0], bmus[3])
+	// quantization error is the mean squared distance to cluster means
+	assert.InDelta(0.02/3.0, r.QuantError(), 0.0001)
+	// prototype coefficients sum up to 1
+	for k := 0; k < 2; k++ {
+		assert.InDelta(1.0, mat64.Sum(r.Coeffs().(*mat64.Dense).RowView(k)), 0.0001)
+	}
+	// new sample close to the second cluster
+	newDiss := make([]float64, n)
+	for j := range newDiss {
+		newDiss[j] = (4.9 - points[j]) * (4.9 - points[j])
+	}
+	bmu, err := r.BMU(newDiss)
+	assert.NoError(err)
+	assert.Equal(bmus[3], bmu)
+	_, err = r.BMU(newDiss[:2])
+	assert.Error(err)
+	// invalid parameters
+	assert.Error(r.Train(tc, 0))
+	tc.Algorithm = "seq"
+	assert.EqualError(r.Train(tc, 1), "unsupported relational SOM training algorithm: seq")
+	r, err = NewRelationalSOM(c, mat64.NewDense(2, 2, []float64{0, 1, 2, 0}))
+	assert.Nil(r)
+	assert.EqualError(err, "dissimilarity matrix must be symmetric")
+	r, err = NewRelationalSOM(c, mat64.NewDense(2, 2, []float64{1, 1, 1, 0}))
+	assert.Nil(r)
+	assert.EqualError(err, "dissimilarity matrix must have zero diagonal")
+	r, err = NewRelationalSOM(c, nil)
+	assert.Nil(r)
+	assert.Error(err)
+}
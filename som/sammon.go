@@ -0,0 +1,178 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Sammon projects vectors stored in data rows into 2D using Sammon mapping, i.e. it finds the projection
+// which preserves the pairwise distances of the data vectors computed using the configured metric
+// by minimizing Sammon stress using pseudo-Newton updates. It returns a matrix whose i-th row holds
+// the 2D coordinates of the vector stored in i-th data row along with the final Sammon stress.
+// It fails with error if the configuration is invalid, if data is nil or if the pairwise distances could not be computed.
+func Sammon(data *mat64.Dense, c *SammonConfig) (*mat64.Dense, float64, error) {
+	if data == nil {
+		return nil, -1.0, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if err := validateSammonConfig(c); err != nil {
+		return nil, -1.0, err
+	}
+	metric := c.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	dist, err := DistanceMx(metric, data)
+	if err != nil {
+		return nil, -1.0, err
+	}
+	rows, _ := data.Dims()
+	// identical vectors are kept slightly apart to avoid division by zero
+	const eps = 1e-9
+	var scale float64
+	for i := 0; i < rows; i++ {
+		for j := i + 1; j < rows; j++ {
+			scale += math.Max(dist.At(i, j), eps)
+		}
+	}
+	rnd := rand.New(randSource(c.Source))
+	proj := mat64.NewDense(rows, 2, nil)
+	for i := 0; i < rows; i++ {
+		proj.Set(i, 0, rnd.Float64())
+		proj.Set(i, 1, rnd.Float64())
+	}
+	for iter := 0; iter < c.Iters; iter++ {
+		for i := 0; i < rows; i++ {
+			yi := proj.RawRowView(i)
+			var grad, hess [2]float64
+			for j := 0; j < rows; j++ {
+				if j == i {
+					continue
+				}
+				yj := proj.RawRowView(j)
+				dStar := math.Max(dist.At(i, j), eps)
+				d := math.Max(math.Hypot(yi[0]-yj[0], yi[1]-yj[1]), eps)
+				diff := dStar - d
+				for p := 0; p < 2; p++ {
+					delta := yi[p] - yj[p]
+					grad[p] += diff / (d * dStar) * delta
+					hess[p] += (diff - delta*delta/d*(1+diff/d)) / (d * dStar)
+				}
+			}
+			for p := 0; p < 2; p++ {
+				if h := math.Abs(hess[p]); h > 0 {
+					yi[p] += c.LRate * grad[p] / h
+				}
+			}
+		}
+	}
+
+	return proj, sammonStress(dist, proj, scale, eps), nil
+}
+
+// sammonStress computes Sammon stress of projection proj of vectors with pairwise distances dist
+func sammonStress(dist, proj *mat64.Dense, scale, eps float64) float64 {
+	rows, _ := proj.Dims()
+	var stress float64
+	for i := 0; i < rows; i++ {
+		for j := i + 1; j < rows; j++ {
+			dStar := math.Max(dist.At(i, j), eps)
+			d := math.Hypot(proj.At(i, 0)-proj.At(j, 0), proj.At(i, 1)-proj.At(j, 1))
+			stress += (dStar - d) * (dStar - d) / dStar
+		}
+	}
+	return stress / scale
+}
+
+// Sammon projects the map codebook vectors into 2D using Sammon mapping. If no metric is configured,
+// the map metric is used. It returns the projected codebook and Sammon stress and fails in the same way as Sammon.
+func (m Map) Sammon(c *SammonConfig) (*mat64.Dense, float64, error) {
+	if c != nil && c.Metric == "" {
+		mc := *c
+		mc.Metric = m.metric
+		c = &mc
+	}
+	return Sammon(m.codebook, c)
+}
+
+// SammonScatter generates Sammon mapping of the map codebook in a given format and writes the output to w.
+// Every unit is drawn as a point shaded by its U-Matrix value and the neighbouring units are connected by lines.
+// At the moment only SVG format is supported. It fails with error if the Sammon mapping fails or if the write to w fails.
+func (m Map) SammonScatter(w io.Writer, c *SammonConfig, format, title string) error {
+	if format != "svg" {
+		return fmt.Errorf("invalid format %s", format)
+	}
+	proj, _, err := m.Sammon(c)
+	if err != nil {
+		return err
+	}
+	umatrix, minDistance, maxDistance, err := umatrixValues(m.codebook, m.grid, m.metric)
+	if err != nil {
+		return err
+	}
+	neighbs, err := unitNeighbours(m.grid)
+	if err != nil {
+		return err
+	}
+
+	return scatterSVG(proj, neighbs, umatrix, minDistance, maxDistance, title, w, m.render.style())
+}
+
+// scatterSVG creates an SVG scatter plot of points stored in rows of proj shaded by values normalized
+// to [minValue, maxValue] range. Points are connected to their neighbours by lines.
+func scatterSVG(proj *mat64.Dense, neighbs [][]int, values []float64, minValue, maxValue float64,
+	title string, writer io.Writer, s unitStyle) error {
+	// the plot area is 10 units big
+	mul, off := s.cellSize(), s.margin()
+	size := 10 * mul
+	rows, _ := proj.Dims()
+	minX, maxX := mat64.Min(proj.ColView(0)), mat64.Max(proj.ColView(0))
+	minY, maxY := mat64.Min(proj.ColView(1)), mat64.Max(proj.ColView(1))
+	span := math.Max(maxX-minX, maxY-minY)
+	if span == 0 {
+		span = 1.0
+	}
+	// points are placed so that the unit markers fit into the plot
+	pad := 0.2 * mul
+	point := func(i int) (float64, float64) {
+		return off + pad + (proj.At(i, 0)-minX)/span*(size-2*pad), off + pad + (proj.At(i, 1)-minY)/span*(size-2*pad)
+	}
+	svgElem := svgElement{
+		Width:    size + 2*off,
+		Height:   size + 2*off,
+		Polygons: []interface{}{},
+	}
+	for i := 0; i < rows; i++ {
+		for _, j := range neighbs[i] {
+			if j > i {
+				x1, y1 := point(i)
+				x2, y2 := point(j)
+				svgElem.Polygons = append(svgElem.Polygons, line{X1: x1, Y1: y1, X2: x2, Y2: y2, Class: "edge", Style: s.strokeStyle()})
+			}
+		}
+	}
+	for i := 0; i < rows; i++ {
+		x, y := point(i)
+		r, g, b := s.unitRGB(i, values, minValue, maxValue)
+		svgElem.Polygons = append(svgElem.Polygons, circle{
+			Cx:    x,
+			Cy:    y,
+			R:     0.15 * mul,
+			Class: "unit",
+			Style: s.fillStyle(r, g, b),
+			Title: s.tooltip(i),
+		})
+	}
+
+	st := newSVGStream(writer)
+	st.css = s.css
+	desc := fmt.Sprintf("Sammon mapping of %d points", rows)
+	st.document(title, desc, stackedRows(svgFrame{width: svgElem.Width, height: svgElem.Height}), func(i int, frame svgFrame) {
+		st.element(frame, svgElem, s)
+	})
+
+	return st.flush()
+}
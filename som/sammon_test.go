@@ -0,0 +1,69 @@
+package som
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSammon(t *testing.T) {
+	assert := assert.New(t)
+
+	// square corners lifted into 3D can be projected without distortion
+	data := mat64.NewDense(4, 3, []float64{
+		0.0, 0.0, 1.0,
+		1.0, 0.0, 1.0,
+		0.0, 1.0, 1.0,
+		1.0, 1.0, 1.0,
+	})
+	c := &SammonConfig{Iters: 200, LRate: 0.3, Source: rand.NewSource(1)}
+	proj, stress, err := Sammon(data, c)
+	assert.NoError(err)
+	rows, cols := proj.Dims()
+	assert.Equal(4, rows)
+	assert.Equal(2, cols)
+	assert.InDelta(0.0, stress, 1e-3)
+	d := math.Hypot(proj.At(0, 0)-proj.At(3, 0), proj.At(0, 1)-proj.At(3, 1))
+	assert.InDelta(math.Sqrt2, d, 1e-2)
+	// invalid parameters
+	_, _, err = Sammon(nil, c)
+	assert.Error(err)
+	_, _, err = Sammon(data, &SammonConfig{Iters: 0, LRate: 0.3})
+	assert.EqualError(err, "invalid number of iterations: 0")
+	_, _, err = Sammon(data, &SammonConfig{Iters: 10, LRate: 1.5})
+	assert.EqualError(err, "invalid Sammon learning rate: 1.500000")
+	_, _, err = Sammon(data, &SammonConfig{Iters: 10, LRate: 0.3, Metric: "foobar"})
+	assert.Error(err)
+}
+
+func TestMapSammonScatter(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(3, 2, []float64{0.0, 0.0, 1.0, 0.0, 3.0, 0.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	c := &SammonConfig{Iters: 500, LRate: 0.3, Source: rand.NewSource(1)}
+	proj, stress, err := m.Sammon(c)
+	assert.NoError(err)
+	assert.InDelta(0.0, stress, 1e-3)
+	assert.InDelta(3.0, math.Hypot(proj.At(0, 0)-proj.At(2, 0), proj.At(0, 1)-proj.At(2, 1)), 1e-2)
+	// configured metric is not changed
+	assert.Equal("", c.Metric)
+	var buf bytes.Buffer
+	assert.NoError(m.SammonScatter(&buf, c, "svg", "Sammon"))
+	svg := buf.String()
+	assert.Equal(3, strings.Count(svg, "<circle "))
+	// neighbouring units are connected
+	assert.Equal(2, strings.Count(svg, "<line "))
+	assert.Error(m.SammonScatter(&buf, c, "png", "Sammon"))
+	assert.Error(m.SammonScatter(&buf, &SammonConfig{}, "svg", "Sammon"))
+}
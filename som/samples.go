@@ -0,0 +1,129 @@
+package som
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// SampleMarker is a data sample drawn as a marker at the position of its Best Match Unit on the map
+type SampleMarker struct {
+	// Sample is the data sample whose BMU is marked
+	Sample []float64
+	// Label is drawn next to the marker. If it is empty, the marker is not labeled
+	Label string
+	// Color is the color of the marker. If it is nil, red markers are drawn
+	Color color.Color
+}
+
+// color returns the color of the marker
+func (s SampleMarker) color() color.Color {
+	if s.Color != nil {
+		return s.Color
+	}
+	return color.RGBA{R: 255, A: 255}
+}
+
+// SampleUMatrixSVG creates an SVG representation of the U-Matrix of the given codebook with markers of the given
+// samples drawn at the positions of their Best Match Units found using the supplied metric, so that the known
+// reference samples can be located on the map. Markers of samples sharing the BMU are spread around the unit center.
+// It accepts the same parameters as UMatrixSVG and samples - the marked samples.
+// It fails with error if any sample BMU could not be found or if the grid coordinates could not be computed.
+func SampleUMatrixSVG(codebook *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer, samples []SampleMarker) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	bmus, err := sampleBMUs(codebook, metric, samples)
+	if err != nil {
+		return err
+	}
+
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{samples: bmus})
+}
+
+// SampleUMatrix generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but the u-matrix is overlaid with markers of the given samples drawn at the positions of their BMUs.
+// Currently only "svg" and "png" formats are supported. PNG images contain no marker labels.
+// It fails with error if any sample BMU could not be found.
+func (m Map) SampleUMatrix(w io.Writer, samples []SampleMarker, format, title string) error {
+	switch format {
+	case "svg", "png":
+		bmus, err := sampleBMUs(m.codebook, m.metric, samples)
+		if err != nil {
+			return err
+		}
+		s := m.render.style()
+		s.samples = bmus
+		if format == "png" {
+			return umatrixPNG(m.codebook, m.grid, m.metric, w, s)
+		}
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// sampleBMUs returns a map of BMUs of the given samples to the sample markers in the order of samples
+func sampleBMUs(codebook *mat64.Dense, metric string, samples []SampleMarker) (map[int][]SampleMarker, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	bmus := make(map[int][]SampleMarker)
+	for _, sample := range samples {
+		bmu, err := ClosestVec(metric, sample.Sample, codebook)
+		if err != nil {
+			return nil, err
+		}
+		bmus[bmu] = append(bmus[bmu], sample)
+	}
+
+	return bmus, nil
+}
+
+// sampleSVG returns SVG markers and labels of the samples mapped to the unit whose center is at x, y
+func (s unitStyle) sampleSVG(unit int, x, y, size float64) []interface{} {
+	var elems []interface{}
+	markers := s.samples[unit]
+	for i, marker := range markers {
+		mx, my := sampleCenter(i, len(markers), x, y, size)
+		r := 0.12 * size
+		elems = append(elems, circle{
+			Cx:    mx,
+			Cy:    my,
+			R:     r,
+			Class: "sample",
+			Style: fmt.Sprintf("fill:%s;%s", svgColor(marker.color()), s.strokeStyle()),
+			Title: marker.Label,
+		})
+		if marker.Label != "" {
+			elems = append(elems, textElement{
+				X:        mx + r + 2,
+				Y:        my,
+				Baseline: "middle",
+				Class:    "sample",
+				Text:     marker.Label,
+			})
+		}
+	}
+	return elems
+}
+
+// sampleCenter returns the center of i-th of n sample markers drawn on the unit of the given size centered at x, y.
+// A single marker is drawn in the unit center, multiple markers are spread evenly around it.
+func sampleCenter(i, n int, x, y, size float64) (float64, float64) {
+	if n <= 1 {
+		return x, y
+	}
+	angle := 2 * math.Pi * float64(i) / float64(n)
+	return x + 0.25*size*math.Sin(angle), y - 0.25*size*math.Cos(angle)
+}
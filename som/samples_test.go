@@ -0,0 +1,60 @@
+package som
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleUMatrixSVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 10.0})
+	samples := []SampleMarker{
+		{Sample: []float64{0.1}, Label: "a<b"},
+		{Sample: []float64{9.0}, Color: color.RGBA{B: 255, A: 255}},
+		{Sample: []float64{11.0}},
+	}
+	var buf bytes.Buffer
+	err := SampleUMatrixSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Samples", &buf, samples)
+	assert.NoError(err)
+	svg := buf.String()
+	assert.Equal(3, strings.Count(svg, "<circle "))
+	// single marker is drawn in the unit center and labeled
+	assert.True(strings.Contains(svg, `<circle cx="35" cy="35" r="6" style="fill:rgb(255,0,0);stroke:black;stroke-width:1"><title>a&lt;b</title></circle>`))
+	assert.True(strings.Contains(svg, `<text x="43" y="35" dominant-baseline="middle">a&lt;b</text>`))
+	// markers sharing the BMU are spread around the unit center
+	assert.True(strings.Contains(svg, `<circle cx="135" cy="22.5" r="6" style="fill:rgb(0,0,255);stroke:black;stroke-width:1"></circle>`))
+	assert.True(strings.Contains(svg, `cy="47.5" r="6" style="fill:rgb(255,0,0);stroke:black;stroke-width:1"></circle>`))
+	assert.Equal(1, strings.Count(svg, "<text "))
+	// map sample markers
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var mBuf bytes.Buffer
+	assert.NoError(m.SampleUMatrix(&mBuf, samples, "svg", "Samples"))
+	assert.Equal(svg, mBuf.String())
+	var pngBuf bytes.Buffer
+	assert.NoError(m.SampleUMatrix(&pngBuf, samples, "png", "Samples"))
+	assert.True(pngBuf.Len() > 0)
+	// invalid parameters
+	assert.Error(m.SampleUMatrix(&mBuf, samples, "bmp", "Samples"))
+	assert.Error(m.SampleUMatrix(&mBuf, []SampleMarker{{Sample: []float64{1.0, 2.0}}}, "svg", "Samples"))
+	assert.Error(SampleUMatrixSVG(cbook, []int{1, 3}, "rectangle", "euclidean", "Samples", &buf, []SampleMarker{{}}))
+	assert.Error(SampleUMatrixSVG(nil, []int{1, 3}, "rectangle", "euclidean", "Samples", &buf, samples))
+}
+
+func TestSampleCenter(t *testing.T) {
+	assert := assert.New(t)
+
+	x, y := sampleCenter(0, 1, 10.0, 20.0, 40.0)
+	assert.Equal(10.0, x)
+	assert.Equal(20.0, y)
+	x, y = sampleCenter(1, 4, 10.0, 20.0, 40.0)
+	assert.InDelta(20.0, x, 1e-9)
+	assert.InDelta(20.0, y, 1e-9)
+}
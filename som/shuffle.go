@@ -0,0 +1,65 @@
+package som
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// sampler returns function which returns the data row used in sequential training iteration i
+// according to the configured sample order. Epochs are as long as the number of data rows.
+func (c *TrainConfig) sampler(rows int, r *rand.Rand) func(i int) int {
+	switch c.Shuffle {
+	case "none":
+		return func(i int) int { return i % rows }
+	case "shuffle", "stratified":
+		var order []int
+		return func(i int) int {
+			if i%rows == 0 {
+				if c.Shuffle == "stratified" {
+					order = stratifiedOrder(rows, c.Classes, r)
+				} else {
+					order = r.Perm(rows)
+				}
+			}
+			return order[i%rows]
+		}
+	}
+	return func(i int) int { return r.Intn(rows) }
+}
+
+// stratifiedOrder returns a random order of data rows in which the rows of every class are spread
+// evenly over the whole order. Rows missing in classes are treated as a separate class.
+func stratifiedOrder(rows int, classes map[int]int, r *rand.Rand) []int {
+	// shuffled rows of every class
+	groups := make(map[int][]int)
+	labels := []int{}
+	for _, row := range r.Perm(rows) {
+		class, ok := classes[row]
+		if !ok {
+			class = -1
+		}
+		if _, ok := groups[class]; !ok {
+			labels = append(labels, class)
+		}
+		groups[class] = append(groups[class], row)
+	}
+	sort.Ints(labels)
+	// k-th row of class c is placed at the relative position (k+0.5)/len(c)
+	type item struct {
+		row int
+		pos float64
+	}
+	items := make([]item, 0, rows)
+	for _, class := range labels {
+		group := groups[class]
+		for k, row := range group {
+			items = append(items, item{row: row, pos: (float64(k) + 0.5) / float64(len(group))})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].pos < items[j].pos })
+	order := make([]int, rows)
+	for i, it := range items {
+		order[i] = it.row
+	}
+	return order
+}
@@ -0,0 +1,67 @@
+package som
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	assert := assert.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	rows := 5
+	// no shuffling presents rows in their order
+	tc := &TrainConfig{Shuffle: "none"}
+	next := tc.sampler(rows, r)
+	for i := 0; i < 2*rows; i++ {
+		assert.Equal(i%rows, next(i))
+	}
+	// shuffling presents every row once per epoch
+	tc.Shuffle = "shuffle"
+	next = tc.sampler(rows, r)
+	for epoch := 0; epoch < 3; epoch++ {
+		order := []int{}
+		for i := 0; i < rows; i++ {
+			order = append(order, next(epoch*rows+i))
+		}
+		sort.Ints(order)
+		assert.Equal([]int{0, 1, 2, 3, 4}, order)
+	}
+	// random order picks any row
+	tc.Shuffle = ""
+	next = tc.sampler(rows, r)
+	for i := 0; i < 20; i++ {
+		row := next(i)
+		assert.True(row >= 0 && row < rows)
+	}
+}
+
+func TestStratifiedOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	r := rand.New(rand.NewSource(1))
+	// rows 0-3 are class 1, rows 4-5 are class 2
+	classes := map[int]int{0: 1, 1: 1, 2: 1, 3: 1, 4: 2, 5: 2}
+	order := stratifiedOrder(6, classes, r)
+	assert.Len(order, 6)
+	// class 2 rows are spread over the first and second half of the epoch
+	half := map[int]int{}
+	for i, row := range order {
+		if classes[row] == 2 {
+			half[i/3]++
+		}
+	}
+	assert.Equal(map[int]int{0: 1, 1: 1}, half)
+	// every row is present exactly once
+	sort.Ints(order)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, order)
+	// seq training with stratified shuffling
+	tc := *tSom
+	tc.Shuffle, tc.Classes = "stratified", map[int]int{0: 1, 1: 2}
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	assert.NoError(m.Train(&tc, dataMx, 20))
+}
@@ -0,0 +1,66 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// SmoothValues smooths unit values of the given grid using Gaussian kernel with the given sigma in grid units:
+// every smoothed value is the weighted average of all unit values where the weights decay with the distance
+// between the units on the grid. i-th item of values holds the value of i-th grid unit, e.g. its U-Matrix value.
+// Smoothing suppresses the noise of the values and makes the large scale structure of the map visible.
+// It fails with error if sigma is not positive, if the number of values is different from the number of grid units
+// or if the grid unit distances could not be computed.
+func SmoothValues(grid *Grid, values []float64, sigma float64) ([]float64, error) {
+	if sigma <= 0 {
+		return nil, fmt.Errorf("invalid smoothing sigma: %f", sigma)
+	}
+	if grid == nil {
+		return nil, fmt.Errorf("invalid grid supplied: %v", grid)
+	}
+	if len(values) != grid.Units() {
+		return nil, fmt.Errorf("invalid number of unit values: %d, expected: %d", len(values), grid.Units())
+	}
+	uDistMx, err := grid.UnitDist()
+	if err != nil {
+		return nil, err
+	}
+
+	return smoothValues(uDistMx, values, sigma), nil
+}
+
+// smoothValues smooths values using Gaussian kernel with the given sigma and unit distances stored in uDistMx
+func smoothValues(uDistMx *mat64.Dense, values []float64, sigma float64) []float64 {
+	smoothed := make([]float64, len(values))
+	for i := range values {
+		var sum, weights float64
+		for j, d := range uDistMx.RawRowView(i) {
+			w := math.Exp(-d * d / (2 * sigma * sigma))
+			sum += w * values[j]
+			weights += w
+		}
+		smoothed[i] = sum / weights
+	}
+	return smoothed
+}
+
+// umatrixValues computes U-Matrix values of the given codebook and grid along with their minimum and maximum.
+// The values are smoothed if the smoothing is enabled. It fails with error if the codebook does not match the grid.
+func (s unitStyle) umatrixValues(codebook *mat64.Dense, grid *Grid, metric string) ([]float64, float64, float64, error) {
+	umatrix, err := unitUMatrix(codebook, grid, metric)
+	if err != nil {
+		return nil, 0.0, 0.0, err
+	}
+	if s.smooth == 0 {
+		return umatrix, floats.Min(umatrix), floats.Max(umatrix), nil
+	}
+	umatrix, err = SmoothValues(grid, umatrix, s.smooth)
+	if err != nil {
+		return nil, 0.0, 0.0, err
+	}
+
+	return umatrix, floats.Min(umatrix), floats.Max(umatrix), nil
+}
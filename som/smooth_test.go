@@ -0,0 +1,71 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoothValues(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	values := []float64{0.0, 3.0, 0.0}
+	smoothed, err := SmoothValues(grid, values, 1.0)
+	assert.NoError(err)
+	// the peak is spread to its neighbours
+	w := 0.6065306597126334
+	assert.InDeltaSlice([]float64{3.0 * w / (1 + w + 0.1353352832366127), 3.0 / (1 + 2*w), 3.0 * w / (1 + w + 0.1353352832366127)}, smoothed, 1e-9)
+	assert.True(floats.Max(smoothed) < 3.0)
+	assert.True(floats.Min(smoothed) > 0.0)
+	// narrow kernel keeps the values
+	smoothed, err = SmoothValues(grid, values, 0.01)
+	assert.NoError(err)
+	assert.InDeltaSlice(values, smoothed, 1e-9)
+	// invalid parameters
+	for _, sigma := range []float64{0.0, -1.0} {
+		smoothed, err = SmoothValues(grid, values, sigma)
+		assert.Nil(smoothed)
+		assert.Error(err)
+	}
+	smoothed, err = SmoothValues(grid, values[:2], 1.0)
+	assert.Nil(smoothed)
+	assert.Error(err)
+	smoothed, err = SmoothValues(nil, values, 1.0)
+	assert.Nil(smoothed)
+	assert.Error(err)
+}
+
+func TestSmoothUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 4}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(4, 1, []float64{0.0, 1.0, 5.0, 6.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	var raw, smooth bytes.Buffer
+	assert.NoError(m.UMatrix(&raw, nil, nil, "svg", "Smooth"))
+	m.render = &RenderConfig{Smooth: 1.0}
+	assert.NoError(m.UMatrix(&smooth, nil, nil, "svg", "Smooth"))
+	assert.NotEqual(raw.String(), smooth.String())
+	assert.Equal(4, strings.Count(smooth.String(), "<polygon "))
+	// smoothed values are rendered
+	umatrix, _, _, err := umatrixValues(m.codebook, grid, "euclidean")
+	assert.NoError(err)
+	smoothed, err := SmoothValues(grid, umatrix, 1.0)
+	assert.NoError(err)
+	var expected bytes.Buffer
+	assert.NoError(valuesSVG(grid, smoothed, floats.Min(smoothed), floats.Max(smoothed), "Smooth", &expected, m.render.style()))
+	assert.Equal(expected.String(), smooth.String())
+	var png bytes.Buffer
+	assert.NoError(m.UMatrix(&png, nil, nil, "png", "Smooth"))
+	assert.True(png.Len() > 0)
+}
@@ -1,8 +1,11 @@
 package som
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"runtime"
 	"sort"
@@ -20,6 +23,10 @@ type Map struct {
 	// grid is a matrix which contains SOM unit coordinages
 	// grid dimensions depend on chosen configuration
 	grid *Grid
+	// metric is a distance metric used to compare codebook and data vectors
+	metric string
+	// render is a rendering configuration of the map
+	render *RenderConfig
 }
 
 // NewMap creates new SOM based on the provided configuration.
@@ -36,20 +43,37 @@ func NewMap(c *MapConfig, data *mat64.Dense) (*Map, error) {
 	if err := validateCbConfig(c.Cb); err != nil {
 		return nil, err
 	}
-	// initialize codebook
-	codebook, err := c.Cb.InitFunc(data, c.Grid.Size)
-	if err != nil {
-		return nil, err
+	// validate rendering config
+	if c.Render != nil {
+		if err := validateRenderConfig(c.Render); err != nil {
+			return nil, err
+		}
 	}
 	// make new grid
 	grid, err := NewGrid(c.Grid)
 	if err != nil {
 		return nil, err
 	}
+	// initialize codebook
+	codebook, err := c.Cb.initializer().Init(data, grid.cbDims())
+	if err != nil {
+		return nil, err
+	}
+	// custom initializers might return codebook of wrong size
+	if rows, cols := codebook.Dims(); rows != grid.Units() || cols != c.Cb.Dim {
+		return nil, fmt.Errorf("invalid codebook dimensions: [%d, %d], expected: [%d, %d]", rows, cols, grid.Units(), c.Cb.Dim)
+	}
+	// use euclidean metric if none was specified
+	metric := c.Cb.Metric
+	if metric == "" {
+		metric = "euclidean"
+	}
 	// return pointer to new map
 	return &Map{
 		codebook: codebook,
 		grid:     grid,
+		metric:   metric,
+		render:   c.Render,
 	}, nil
 }
 
@@ -63,16 +87,39 @@ func (m Map) Grid() *Grid {
 	return m.grid
 }
 
-// UnitDist returns a matrix which contains Euclidean distances between SOM units
+// Metric returns distance metric used by SOM
+func (m Map) Metric() string {
+	return m.metric
+}
+
+// UnitDist returns a matrix which contains distances between SOM units.
+// The distances respect SOM grid topology.
 func (m Map) UnitDist() (*mat64.Dense, error) {
-	return DistanceMx("euclidean", m.grid.coords)
+	return m.grid.UnitDist()
 }
 
 // BMUs returns a slice which contains indices of Best Match Unit vectors to the map
 // codebook for each vector stored in data rows.
 // It returns error if the data dimension and map codebook dimensions are not the same.
 func (m Map) BMUs(data *mat64.Dense) ([]int, error) {
-	return BMUs(data, m.codebook)
+	return bmus(m.metric, data, m.codebook)
+}
+
+// Quantize returns a matrix in which every vector stored in data rows is replaced by its BMU codebook vector,
+// i.e. it uses the map as a vector quantizer. The returned matrix has the same dimensions as data.
+// It returns error if the data is nil or if the data dimension and map codebook dimensions are not the same.
+func (m Map) Quantize(data *mat64.Dense) (*mat64.Dense, error) {
+	units, _, err := MapRows(m.codebook, data, m.metric)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := data.Dims()
+	quant := mat64.NewDense(rows, cols, nil)
+	for i, unit := range units {
+		quant.SetRow(i, m.codebook.RawRowView(unit))
+	}
+
+	return quant, nil
 }
 
 // MarshalTo serializes SOM codebook in a given format to writer w.
@@ -88,10 +135,12 @@ func (m *Map) MarshalTo(format string, w io.Writer) (int, error) {
 }
 
 // UMatrix generates SOM u-matrix in a given format and writes the output to w.
-// At the moment only SVG format is supported. It fails with error if the write to w fails.
+// Supported formats are svg, png and html. PNG images contain no text, i.e. neither the title nor the class numbers are rendered.
+// HTML documents show the unit index, average neighbour distance, hit count of data and codebook vector when hovering over the units.
+// It fails with error if the write to w fails.
 func (m Map) UMatrix(w io.Writer, data *mat64.Dense, classMap map[int]int, format, title string) error {
 	switch format {
-	case "svg":
+	case "svg", "png", "html":
 		{
 			// map that contains most frequent BMU class of all of its classes
 			bmuClassMap := make(map[int]int)
@@ -122,13 +171,94 @@ func (m Map) UMatrix(w io.Writer, data *mat64.Dense, classMap map[int]int, forma
 				}
 			}
 
-			return UMatrixSVG(m.codebook, m.grid.size, m.grid.ushape, title, w, bmuClassMap)
+			s := m.render.style()
+			s.classes = bmuClassMap
+			switch format {
+			case "png":
+				return umatrixPNG(m.codebook, m.grid, m.metric, w, s)
+			case "html":
+				return umatrixHTML(m.codebook, data, m.grid, m.metric, title, w, s)
+			}
+			return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
 		}
 	}
 
 	return fmt.Errorf("invalid format %s", format)
 }
 
+// ComponentPlane generates SOM component plane of the given codebook feature in a given format and writes
+// the output to w. Supported formats are svg and png. It fails with error if the feature index
+// is out of range or if the write to w fails.
+func (m Map) ComponentPlane(w io.Writer, feature int, format, title string) error {
+	switch format {
+	case "svg":
+		return componentPlaneSVG(m.codebook, m.grid, feature, title, w, m.render.style())
+	case "png":
+		return componentPlanePNG(m.codebook, m.grid, feature, w, m.render.style())
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// HitsUMatrix generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but every map unit is overlaid with a marker whose area is proportional to the number of data samples
+// the unit is the Best Match Unit of. Supported formats are svg and png.
+// It fails with error if the hits could not be computed or if the write to w fails.
+func (m Map) HitsUMatrix(w io.Writer, data *mat64.Dense, format, title string) error {
+	switch format {
+	case "svg", "png":
+		hits, err := m.HitMap(data)
+		if err != nil {
+			return err
+		}
+		s := m.render.style()
+		s.markers = hitMarkers(hits)
+		if format == "png" {
+			return umatrixPNG(m.codebook, m.grid, m.metric, w, s)
+		}
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// LabelUMatrix generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but every map unit is labeled by its label drawn in the unit center. Units with empty labels are not labeled.
+// It fails with error if the number of labels is different from the number of map units.
+func (m Map) LabelUMatrix(w io.Writer, labels []string, format, title string) error {
+	if len(labels) != m.grid.Units() {
+		return fmt.Errorf("invalid number of unit labels: %d, expected: %d", len(labels), m.grid.Units())
+	}
+	switch format {
+	case "svg":
+		s := m.render.style()
+		s.labels = labels
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// ClusterUMatrix generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but the map units are colored by their clusters, e.g. as returned by KMeans, and the cells belonging to different
+// clusters are separated by thick boundary strokes. Units with negative cluster IDs are rendered in shades of gray.
+// Currently only "svg" and "png" formats are supported. It fails with error if the number of clusters is different from the number of map units.
+func (m Map) ClusterUMatrix(w io.Writer, clusters []int, format, title string) error {
+	if len(clusters) != m.grid.Units() {
+		return fmt.Errorf("invalid number of unit clusters: %d, expected: %d", len(clusters), m.grid.Units())
+	}
+	switch format {
+	case "svg", "png":
+		s := clusterStyle(m.render.style(), clusters)
+		if format == "png" {
+			return umatrixPNG(m.codebook, m.grid, m.metric, w, s)
+		}
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
 // mapBMUclasses returns a map which contains a list of classes to which this BMUs input samples are members of
 // We go through all data samples and add their classes to the list of classes of their respective BMUs.
 func (m Map) mapBMUclasses(data *mat64.Dense, classes map[int]int) (map[int][]int, error) {
@@ -137,7 +267,7 @@ func (m Map) mapBMUclasses(data *mat64.Dense, classes map[int]int) (map[int][]in
 	bmuClasses := make(map[int][]int)
 	for row := 0; row < rows; row++ {
 		// find BMU
-		cbi, err := ClosestVec("euclidean", data.RawRowView(row), m.codebook)
+		cbi, err := ClosestVec(m.metric, data.RawRowView(row), m.codebook)
 		if err != nil {
 			return nil, err
 		}
@@ -159,8 +289,43 @@ func (m Map) mapBMUclasses(data *mat64.Dense, classes map[int]int) (map[int][]in
 
 // Train runs a SOM training for a given data set and training configuration parameters.
 // It modifies the map codebook vectors based on the chosen training algorithm.
+// Calling Train on an already trained map continues the training from its current codebook vectors.
 // It returns error if the supplied training configuration is invalid or training fails
 func (m *Map) Train(c *TrainConfig, data *mat64.Dense, iters int) error {
+	return m.train(context.Background(), c, data, iters, nil)
+}
+
+// TrainContext runs SOM training in the same way as Train, but aborts the training when ctx is
+// cancelled or its deadline expires. The map then holds the codebook vectors trained so far.
+// It returns ctx.Err() if the training has been aborted or fails in the same way as Train.
+func (m *Map) TrainContext(ctx context.Context, c *TrainConfig, data *mat64.Dense, iters int) error {
+	return m.train(ctx, c, data, iters, nil)
+}
+
+// ErrStopTraining can be returned by TrainConfig.OnEpochEnd hook to stop the training without error
+var ErrStopTraining = errors.New("stop training")
+
+// TrainStats holds SOM training statistics at the end of a training epoch
+type TrainStats struct {
+	// Iteration is the number of completed training iterations
+	Iteration int
+	// Iters is the total number of training iterations
+	Iters int
+	// Radius is the neighbourhood radius used in the last iteration
+	Radius float64
+	// LRate is the learning rate used in the last iteration
+	LRate float64
+}
+
+// epochFunc is called at the end of every training epoch with the number of completed epochs
+// and iterations. The training stops if it returns true or error.
+type epochFunc func(epoch, iteration int) (bool, error)
+
+// train validates the training parameters and runs the training until it completes or ctx is done.
+// If epochEnd is not nil, it is called at the end of every training epoch: every iteration of batch
+// training and every as many iterations of sequential training as there are data rows.
+// TrainConfig.OnEpochEnd hook is called after epochEnd.
+func (m *Map) train(ctx context.Context, c *TrainConfig, data *mat64.Dense, iters int, epochEnd epochFunc) error {
 	// number of iterations must be a positive integer
 	if iters <= 0 {
 		return fmt.Errorf("invalid number of iterations: %d", iters)
@@ -173,71 +338,151 @@ func (m *Map) Train(c *TrainConfig, data *mat64.Dense, iters int) error {
 	if err := validateTrainConfig(c); err != nil {
 		return err
 	}
+	// data row weights must match the data rows
+	if c.Weights != nil {
+		rows, _ := data.Dims()
+		if err := validateWeights(c.Weights, rows); err != nil {
+			return err
+		}
+	}
+	// reinitialize dead units after the epoch function
+	if c.DeadUnits != nil {
+		epochEnd = m.deadUnitsEpochFunc(c, data, epochEnd)
+	}
+	// call the training hook after the epoch function
+	if c.OnEpochEnd != nil {
+		epochEnd = hookEpochFunc(c, iters, epochEnd)
+	}
 	// run the training
 	switch c.Algorithm {
 	case "seq":
-		return m.seqTrain(c, data, iters)
+		return m.seqTrain(ctx, c, data, iters, epochEnd)
 	case "batch":
-		return m.batchTrain(c, data, iters)
+		return m.batchTrain(ctx, c, data, iters, epochEnd)
 	}
 
 	return nil
 }
 
-// QuantError computes SOM quantization error for the supplied data set
+// hookEpochFunc returns epoch function which calls epochEnd, if it is not nil, and then c.OnEpochEnd hook
+func hookEpochFunc(c *TrainConfig, iters int, epochEnd epochFunc) epochFunc {
+	return func(epoch, iteration int) (bool, error) {
+		if epochEnd != nil {
+			if stop, err := epochEnd(epoch, iteration); stop || err != nil {
+				return stop, err
+			}
+		}
+		stats := TrainStats{
+			Iteration: iteration,
+			Iters:     iters,
+			Radius:    c.radius(iteration-1, iters),
+			LRate:     c.lRate(iteration-1, iters),
+		}
+		if err := c.OnEpochEnd(epoch, stats); err != nil {
+			if err == ErrStopTraining {
+				return true, nil
+			}
+			return true, err
+		}
+		return false, nil
+	}
+}
+
+// QuantError computes SOM quantization error for the supplied data set using the map distance metric
 // It returns the quantization error or fails with error if the passed in data is nil
 // or the distance betweent vectors could not be calculated.
 // When the error is returned, quantization error is set to -1.0.
 func (m Map) QuantError(data *mat64.Dense) (float64, error) {
-	return QuantError(data, m.codebook)
+	return QuantizationError(m.codebook, data, m.metric)
 }
 
 // TopoProduct computes SOM topographic product
 // It returns a single number or fails with error if the product could not be computed
 func (m Map) TopoProduct() (float64, error) {
-	return TopoProduct(m.codebook, m.grid.coords)
+	uDistMx, err := m.UnitDist()
+	if err != nil {
+		return 0.0, err
+	}
+	return topoProduct(m.metric, m.codebook, uDistMx)
 }
 
 // TopoError computes SOM topographic error for a given data set.
 // It returns a single number or fails with error if the error could not be computed
 func (m Map) TopoError(data *mat64.Dense) (float64, error) {
-	return TopoError(data, m.codebook, m.grid.coords)
+	// data can't be nil
+	if data == nil {
+		return -1.0, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	uDistMx, err := m.UnitDist()
+	if err != nil {
+		return -1.0, err
+	}
+	return topoError(m.metric, data, m.codebook, uDistMx)
 }
 
 // seqTrain runs sequential SOM training algorithm on a given data set
-func (m *Map) seqTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
+func (m *Map) seqTrain(ctx context.Context, tc *TrainConfig, data *mat64.Dense, iters int, epochEnd epochFunc) error {
 	rows, _ := data.Dims()
-	// create random number generator
-	rSrc := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(rSrc)
+	// create random number generator and sample order
+	r := rand.New(randSource(tc.Source))
+	next := tc.sampler(rows, r)
 	// calculate unit distances
 	unitDist, err := m.UnitDist()
 	if err != nil {
 		return err
 	}
 	// retrieve Neighbourhood function
-	nFn := tc.NeighbFn
+	nb := tc.neighbourhood()
+	// winning frequencies of units used by conscience mechanism
+	var freqs []float64
+	if tc.Conscience != nil {
+		freqs = make([]float64, m.grid.Units())
+		for j := range freqs {
+			freqs[j] = 1.0 / float64(len(freqs))
+		}
+	}
 	// perform iters number of learning iterations
 	for i := 0; i < iters; i++ {
-		// pick a random sample from dataset
-		sample := data.RawRowView(r.Intn(rows))
+		// stop if the training has been cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// pick a sample from dataset
+		row := next(i)
+		sample := data.RawRowView(row)
+		// row weight scales the learning rate
+		weight := 1.0
+		if tc.Weights != nil {
+			weight = tc.Weights[row]
+		}
 		// no need to check for error here:
 		// sample and codebook are not nil and have the same dimension
-		bmu, _ := ClosestVec("euclidean", sample, m.codebook)
-		// no need to check for errors:
-		// LRate and Radius are checked by config validation
-		lRate, _ := LRate(i, iters, tc.LDecay, tc.LRate)
-		radius, _ := Radius(i, iters, tc.RDecay, tc.Radius)
+		var bmu int
+		if tc.Conscience != nil {
+			bmu, _ = m.conscienceBMU(tc.Conscience, sample, freqs)
+		} else {
+			bmu, _ = ClosestVec(m.metric, sample, m.codebook)
+		}
+		lRate := tc.lRate(i, iters)
+		radius := tc.radius(i, iters)
 		// pick the bmu unit distance row
 		bmuDists := unitDist.RawRowView(bmu)
-		// find units which are within the radius
-		for i := 0; i < len(bmuDists); i++ {
-			// bmu distance to i-th map unit
-			dist := bmuDists[i]
-			// we are within BMU radius
-			if dist < radius {
-				// update particular codebook vector
-				m.seqUpdateCbVec(i, sample, lRate, radius, dist, nFn)
+		// update all units within the BMU neighbourhood
+		for j := 0; j < len(bmuDists); j++ {
+			// bmu distance to j-th map unit
+			dist := bmuDists[j]
+			nghb := unitWeight(nb, dist, radius)
+			// skip units outside of the neighbourhood
+			if nghb == 0.0 {
+				continue
+			}
+			// update particular codebook vector; heavy rows can't overshoot the sample
+			m.seqUpdateCbVec(j, sample, math.Min(lRate*nghb*weight, 1.0))
+		}
+		// epoch ends after as many iterations as there are data rows
+		if epochEnd != nil && (i+1)%rows == 0 {
+			if stop, err := epochEnd((i+1)/rows, i+1); stop || err != nil {
+				return err
 			}
 		}
 	}
@@ -245,19 +490,58 @@ func (m *Map) seqTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
 	return nil
 }
 
-// seqUpdateCbVec updates codebook vector on row cbIdx given the learning rate l,
-// radius r, distance d and neihgbourhood function nFn
-func (m *Map) seqUpdateCbVec(cbIdx int, vec []float64, l, r, d float64, nFn NeighbFunc) {
+// unitWeight returns sequential training update weight of a unit at grid distance dist from BMU.
+// BMU itself is always updated using the full learning rate. It returns 0 for units outside
+// of the neighbourhood and if the weight is NaN, e.g. if no initial radius was set.
+func unitWeight(nb Neighbourhood, dist, radius float64) float64 {
+	if dist == 0.0 {
+		return 1.0
+	}
+	if w := nb.Weight(dist, radius); !math.IsNaN(w) {
+		return w
+	}
+	return 0.0
+}
+
+// randSource returns src or a new random number source seeded by the current time if src is nil
+func randSource(src rand.Source) rand.Source {
+	if src != nil {
+		return src
+	}
+	return rand.NewSource(time.Now().UnixNano())
+}
+
+// conscienceBMU finds BMU of vec biased by the units winning frequencies freqs and updates the frequencies
+func (m Map) conscienceBMU(c *ConscienceConfig, vec []float64, freqs []float64) (int, error) {
+	bmu, min := -1, math.Inf(1)
+	for j := range freqs {
+		d, err := Distance(m.metric, vec, m.codebook.RawRowView(j))
+		if err != nil {
+			return -1, err
+		}
+		if d -= c.Bias * (1.0/float64(len(freqs)) - freqs[j]); d < min {
+			bmu, min = j, d
+		}
+	}
+	for j := range freqs {
+		win := 0.0
+		if j == bmu {
+			win = 1.0
+		}
+		freqs[j] += c.Rate * (win - freqs[j])
+	}
+	return bmu, nil
+}
+
+// seqUpdateCbVec moves codebook vector on row cbIdx towards vec by the given scaled learning rate l
+func (m *Map) seqUpdateCbVec(cbIdx int, vec []float64, l float64) {
 	// pick codebook vector that should be updated
 	cbVec := m.codebook.RawRowView(cbIdx)
-	mul := l
-	// Update codebook vector element by element
+	// Update codebook vector element by element; missing elements are skipped
 	for i := 0; i < len(cbVec); i++ {
-		// nFn returns 1 for d == 0; skipping this case will save us some CPU time
-		if d > 0.0 {
-			mul *= nFn(d, r)
+		if !math.IsNaN(vec[i]) {
+			cbVec[i] = cbVec[i] + l*(vec[i]-cbVec[i])
 		}
-		cbVec[i] = cbVec[i] + mul*(vec[i]-cbVec[i])
 	}
 }
 
@@ -273,12 +557,12 @@ type batchConfig struct {
 type batchResult struct {
 	// vecs is a slice of nghb scaled data vectors
 	vecs [][]float64
-	// nghbs is a slice of BMU neighbourhoods
-	nghbs []float64
+	// nghbs is a slice of BMU neighbourhoods summed over observed vector elements
+	nghbs [][]float64
 }
 
 // batchTrain runs batch SOM training on a given data set
-func (m *Map) batchTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
+func (m *Map) batchTrain(ctx context.Context, tc *TrainConfig, data *mat64.Dense, iters int, epochEnd epochFunc) error {
 	cbRows, _ := m.codebook.Dims()
 	rows, _ := data.Dims()
 	// batchConfig holds training config and number of iterations
@@ -291,17 +575,27 @@ func (m *Map) batchTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
 	if err != nil {
 		return err
 	}
-	// number of worker goroutines
-	workers := runtime.NumCPU()
+	// number of worker goroutines; every worker processes at least one data row
+	workers := tc.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > rows {
+		workers = rows
+	}
 	// evenly distribute batch work between workers
 	workerBatch := rows / workers
 	// train for a number of iterations
 	for i := 0; i < iters; i++ {
+		// stop if the training has been cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// reset from index and input count
 		from := 0
 		count := workerBatch
-		// create batch results channel
-		results := make(chan *batchResult, workers*40)
+		// batch results of all workers
+		results := make([]*batchResult, workers)
 		wg := &sync.WaitGroup{}
 		// start worker goroutines
 		for j := 0; j < workers; j++ {
@@ -316,37 +610,43 @@ func (m *Map) batchTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
 				count = rows - from
 			}
 			wg.Add(1)
-			go m.processBatch(results, wg, bc, unitDist, data, from, count, i)
+			go m.processBatch(results, j, wg, bc, unitDist, data, from, count, i)
 		}
-		// wait for workers to finish and close the result channel
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
-		// collect batch results from all workers
+		// wait for workers to finish
+		wg.Wait()
+		// collect batch results from all workers in the same order every time
 		vecs := make([][]float64, cbRows)
-		nghbs := make([]float64, cbRows)
-		for result := range results {
+		nghbs := make([][]float64, cbRows)
+		for _, result := range results {
 			for k := 0; k < len(result.vecs); k++ {
 				if result.vecs[k] != nil {
 					if vecs[k] != nil {
 						for l := 0; l < len(vecs[k]); l++ {
 							vecs[k][l] += result.vecs[k][l]
+							nghbs[k][l] += result.nghbs[k][l]
 						}
 					} else {
 						vecs[k] = result.vecs[k]
+						nghbs[k] = result.nghbs[k]
 					}
-					nghbs[k] += result.nghbs[k]
 				}
 			}
 		}
-		// update codebook vectors
+		// update codebook vectors; elements missing in all neighbourhood data are left intact
 		for k := 0; k < cbRows; k++ {
 			if vecs[k] != nil {
+				cbVec := m.codebook.RawRowView(k)
 				for l := 0; l < len(vecs[k]); l++ {
-					vecs[k][l] = vecs[k][l] / nghbs[k]
+					if nghbs[k][l] > 0.0 {
+						cbVec[l] = vecs[k][l] / nghbs[k][l]
+					}
 				}
-				m.codebook.SetRow(k, vecs[k])
+			}
+		}
+		// every batch iteration is an epoch
+		if epochEnd != nil {
+			if stop, err := epochEnd(i+1, i+1); stop || err != nil {
+				return err
 			}
 		}
 	}
@@ -354,46 +654,54 @@ func (m *Map) batchTrain(tc *TrainConfig, data *mat64.Dense, iters int) error {
 	return nil
 }
 
-// processRow processes data rows and sends tehm down the results channel
-func (m Map) processBatch(res chan<- *batchResult, wg *sync.WaitGroup,
+// processBatch processes data rows and stores the batch result of the worker in res
+func (m Map) processBatch(res []*batchResult, worker int, wg *sync.WaitGroup,
 	bc *batchConfig, unitDist, data *mat64.Dense, from, count, iter int) {
 	// allocate codebook vectors and neighbourhoods
 	rows, _ := m.codebook.Dims()
 	vecs := make([][]float64, rows)
-	nghbs := make([]float64, rows)
+	nghbs := make([][]float64, rows)
 	// retrieve Neighbourhood function
-	nFn := bc.tc.NeighbFn
+	nb := bc.tc.neighbourhood()
 	// iterate through the whole batch
 	for i := from; i < count+from; i++ {
+		// row weight scales its contribution to all neighbourhoods
+		weight := 1.0
+		if bc.tc.Weights != nil {
+			weight = bc.tc.Weights[i]
+		}
+		if weight == 0.0 {
+			continue
+		}
 		row := data.RawRowView(i)
 		// find codebook BMU for this data row
-		bmu, _ := ClosestVec("euclidean", row, m.codebook)
+		bmu, _ := ClosestVec(m.metric, row, m.codebook)
 		// calculate radius for this iteration
-		radius, _ := Radius(iter, bc.iters, bc.tc.RDecay, bc.tc.Radius)
+		radius := bc.tc.radius(iter, bc.iters)
 		// pick the BMU's distance row
 		bmuDists := unitDist.RawRowView(bmu)
 		for j := 0; j < len(bmuDists); j++ {
 			// bmu distance to i-th map unit
 			dist := bmuDists[j]
-			// when in BMU radius, scale and add to all neighbourhood vecs
-			if dist < radius {
-				// calculate neighbourhood function
-				nghb := nFn(dist, radius)
-				if vecs[j] != nil {
-					for k := 0; k < len(vecs[j]); k++ {
-						vecs[j][k] += nghb * row[k]
-					}
-				} else {
+			// calculate neighbourhood function
+			nghb := weight * nb.Weight(dist, radius)
+			// scale and add to all neighbourhood vecs; negative weights can't be averaged
+			if nghb > 0.0 {
+				if vecs[j] == nil {
 					vecs[j] = make([]float64, len(row))
-					for k := 0; k < len(vecs[j]); k++ {
-						vecs[j][k] = nghb * row[k]
+					nghbs[j] = make([]float64, len(row))
+				}
+				// missing row elements don't contribute to the neighbourhood
+				for k := 0; k < len(vecs[j]); k++ {
+					if !math.IsNaN(row[k]) {
+						vecs[j][k] += nghb * row[k]
+						nghbs[j][k] += nghb
 					}
 				}
-				nghbs[j] += nghb
 			}
 		}
 	}
-	// send batchResult down results channel
-	res <- &batchResult{vecs: vecs, nghbs: nghbs}
+	// store batchResult of the worker
+	res[worker] = &batchResult{vecs: vecs, nghbs: nghbs}
 	wg.Done()
 }
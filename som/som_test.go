@@ -1,10 +1,16 @@
 package som
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gonum/matrix/mat64"
 	"github.com/milosgajdos83/gosom/pkg/utils"
@@ -65,6 +71,59 @@ func mockInit(d *mat64.Dense, dims []int) (*mat64.Dense, error) {
 	return nil, errors.New("Test error")
 }
 
+// constInit initializes all codebook vectors to the same value
+type constInit struct {
+	val  float64
+	cols int
+}
+
+func (c constInit) Init(d *mat64.Dense, dims []int) (*mat64.Dense, error) {
+	rows := 1
+	for _, dim := range dims {
+		rows *= dim
+	}
+	cb := mat64.NewDense(rows, c.cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < c.cols; j++ {
+			cb.Set(i, j, c.val)
+		}
+	}
+	return cb, nil
+}
+
+func TestNewMapInitializer(t *testing.T) {
+	assert := assert.New(t)
+
+	origInitFunc := mSom.Cb.InitFunc
+	defer func() {
+		mSom.Cb.InitFunc = origInitFunc
+		mSom.Cb.Initializer = nil
+	}()
+	// initializer is used instead of init function
+	mSom.Cb.InitFunc = nil
+	mSom.Cb.Initializer = constInit{val: 2.0, cols: 4}
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	rows, _ := m.codebook.Dims()
+	for i := 0; i < rows; i++ {
+		assert.Equal([]float64{2.0, 2.0, 2.0, 2.0}, m.codebook.RawRowView(i))
+	}
+	mSom.Cb.InitFunc = mockInit
+	m, err = NewMap(mSom, dataMx)
+	assert.NoError(err)
+	assert.NotNil(m)
+	// init function implements initializer
+	mSom.Cb.Initializer = CbInitFunc(mockInit)
+	m, err = NewMap(mSom, dataMx)
+	assert.Nil(m)
+	assert.Error(err)
+	// initializer returns codebook of wrong dimension
+	mSom.Cb.Initializer = constInit{val: 2.0, cols: 3}
+	m, err = NewMap(mSom, dataMx)
+	assert.Nil(m)
+	assert.EqualError(err, "invalid codebook dimensions: [6, 3], expected: [6, 4]")
+}
+
 func TestNewMap(t *testing.T) {
 	assert := assert.New(t)
 
@@ -128,6 +187,24 @@ func TestGrid(t *testing.T) {
 	assert.Equal(rows, mSom.Grid.Size[0]*mSom.Grid.Size[1])
 }
 
+func TestMetric(t *testing.T) {
+	assert := assert.New(t)
+
+	// default metric is euclidean
+	m, err := NewMap(mSom, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	assert.Equal("euclidean", m.Metric())
+	// cosine metric
+	origMetric := mSom.Cb.Metric
+	mSom.Cb.Metric = "cosine"
+	m, err = NewMap(mSom, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	assert.Equal("cosine", m.Metric())
+	mSom.Cb.Metric = origMetric
+}
+
 func TestUnitDist(t *testing.T) {
 	assert := assert.New(t)
 
@@ -158,6 +235,33 @@ func TestMapBmus(t *testing.T) {
 	assert.Equal(rows, len(bmus))
 }
 
+func TestMapQuantize(t *testing.T) {
+	assert := assert.New(t)
+
+	// default config should not throw any errors
+	m, err := NewMap(mSom, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	quant, err := m.Quantize(dataMx)
+	assert.NoError(err)
+	rows, cols := quant.Dims()
+	dataRows, dataCols := dataMx.Dims()
+	assert.Equal(dataRows, rows)
+	assert.Equal(dataCols, cols)
+	bmus, err := m.BMUs(dataMx)
+	assert.NoError(err)
+	for i, bmu := range bmus {
+		assert.Equal(m.codebook.RawRowView(bmu), quant.RawRowView(i))
+	}
+	// invalid data
+	quant, err = m.Quantize(nil)
+	assert.Nil(quant)
+	assert.Error(err)
+	quant, err = m.Quantize(mat64.NewDense(2, 2, nil))
+	assert.Nil(quant)
+	assert.Error(err)
+}
+
 func TestTrain(t *testing.T) {
 	assert := assert.New(t)
 
@@ -193,6 +297,328 @@ func TestTrain(t *testing.T) {
 	tSom.Algorithm = origAlgorithm
 }
 
+func TestSeqTrainNeighb(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	data := mat64.NewDense(1, 2, []float64{0.0, 0.0})
+	tc := &TrainConfig{
+		Algorithm: "seq",
+		Radius:    1.0,
+		RDecay:    "lin",
+		LRate:     0.5,
+		LDecay:    "lin",
+	}
+	testCases := []struct {
+		nFn   NeighbFunc
+		moved bool
+	}{
+		{Gaussian, true},
+		{Bubble, false},
+		{CutGaussian, false},
+		{Epanechnikov, false},
+	}
+	for _, tc2 := range testCases {
+		m := &Map{
+			codebook: mat64.NewDense(3, 2, []float64{0.1, 0.1, 5.0, 5.0, 10.0, 10.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc.NeighbFn = tc2.nFn
+		assert.NoError(m.Train(tc, data, 2))
+		// units are updated equally in all dimensions
+		for i := 0; i < 3; i++ {
+			assert.InDelta(m.codebook.At(i, 0), m.codebook.At(i, 1), 0.0001)
+		}
+		// gaussian neighbourhood is not cut off at the radius
+		assert.Equal(tc2.moved, m.codebook.At(2, 0) < 10.0)
+	}
+	// custom neighbourhood takes precedence over neighbourhood function
+	tc.Neighb = constNeighb(0.5)
+	for _, alg := range []string{"seq", "batch"} {
+		m := &Map{
+			codebook: mat64.NewDense(3, 2, []float64{0.1, 0.1, 5.0, 5.0, 10.0, 10.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc.Algorithm = alg
+		assert.NoError(m.Train(tc, data, 2))
+		assert.True(m.codebook.At(2, 0) < 10.0)
+	}
+}
+
+func TestBatchTrainWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	cb := mat64.DenseCopyOf(m.codebook)
+	tc := &TrainConfig{
+		Algorithm: "batch",
+		Radius:    2.0,
+		RDecay:    "lin",
+		NeighbFn:  Gaussian,
+		LRate:     0.5,
+		LDecay:    "lin",
+		Workers:   1,
+	}
+	assert.NoError(m.Train(tc, dataMx, 10))
+	// results don't depend on the number of workers, even if it exceeds the number of rows
+	for _, workers := range []int{2, 3, 10} {
+		wm := &Map{
+			codebook: mat64.DenseCopyOf(cb),
+			grid:     m.grid,
+			metric:   m.metric,
+		}
+		tc.Workers = workers
+		assert.NoError(wm.Train(tc, dataMx, 10))
+		assert.True(mat64.EqualApprox(m.codebook, wm.codebook, 1e-9))
+	}
+}
+
+func TestTrainWeights(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	data := mat64.NewDense(2, 1, []float64{0.0, 10.0})
+	tc := &TrainConfig{
+		Radius: 1.0,
+		RDecay: "lin",
+		Neighb: constNeighb(1.0),
+		LRate:  0.5,
+		LDecay: "lin",
+		// only the second row is used for training
+		Weights: []float64{0.0, 1.0},
+	}
+	for _, alg := range []string{"seq", "batch"} {
+		m := &Map{
+			codebook: mat64.NewDense(2, 1, []float64{5.0, 5.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc.Algorithm = alg
+		assert.NoError(m.Train(tc, data, 20))
+		assert.True(m.codebook.At(0, 0) > 5.0)
+	}
+	// batch units are weighted means of data rows
+	m := &Map{
+		codebook: mat64.NewDense(2, 1, []float64{5.0, 5.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	tc.Weights = []float64{1.0, 3.0}
+	assert.NoError(m.Train(tc, data, 1))
+	assert.InDelta(7.5, m.codebook.At(0, 0), 0.0001)
+	assert.InDelta(7.5, m.codebook.At(1, 0), 0.0001)
+	// invalid weights
+	tc.Weights = []float64{1.0}
+	assert.EqualError(m.Train(tc, data, 1), "invalid number of weights: 1")
+	tc.Weights = []float64{1.0, -1.0}
+	assert.EqualError(m.Train(tc, data, 1), "invalid weight: -1.000000")
+	tc.Weights = []float64{0.0, 0.0}
+	assert.EqualError(m.Train(tc, data, 1), "invalid weights: all weights are zero")
+}
+
+func TestTrainMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	nan := math.NaN()
+	data := mat64.NewDense(2, 2, []float64{0.0, nan, 10.0, nan})
+	tc := &TrainConfig{
+		Radius: 1.0,
+		RDecay: "lin",
+		Neighb: constNeighb(1.0),
+		LRate:  0.5,
+		LDecay: "lin",
+	}
+	for _, alg := range []string{"seq", "batch"} {
+		m := &Map{
+			codebook: mat64.NewDense(2, 2, []float64{5.0, 5.0, 5.0, 5.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+		tc.Algorithm = alg
+		assert.NoError(m.Train(tc, data, 10))
+		// missing components are never updated
+		for i := 0; i < 2; i++ {
+			assert.False(math.IsNaN(m.codebook.At(i, 0)), alg)
+			assert.Equal(5.0, m.codebook.At(i, 1), alg)
+		}
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	assert.NoError(m.Train(tSom, dataMx, 100))
+	// resume training from the trained codebook with reduced parameters
+	mc := &MapConfig{
+		Grid: mSom.Grid,
+		Cb: &CbConfig{
+			Dim:      mSom.Cb.Dim,
+			InitFunc: CodebookInit(m.Codebook()),
+		},
+	}
+	wm, err := NewMap(mc, dataMx)
+	assert.NoError(err)
+	assert.True(mat64.Equal(m.Codebook(), wm.Codebook()))
+	tc := *tSom
+	tc.Radius, tc.LRate = 1.0, 0.05
+	assert.NoError(wm.Train(&tc, dataMx, 10))
+	// the original map is not modified
+	assert.False(mat64.Equal(m.Codebook(), wm.Codebook()))
+}
+
+// constNeighb is a neighbourhood which updates all units with the same weight
+type constNeighb float64
+
+func (c constNeighb) Weight(gridDist, radius float64) float64 {
+	return float64(c)
+}
+
+func TestToroidMap(t *testing.T) {
+	testWrappedMap(t, "toroid")
+}
+
+func TestCylinderMap(t *testing.T) {
+	testWrappedMap(t, "cylinder")
+}
+
+func testWrappedMap(t *testing.T, gridType string) {
+	assert := assert.New(t)
+
+	origType := mSom.Grid.Type
+	mSom.Grid.Type = gridType
+	m, err := NewMap(mSom, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	// unit distances wrap around grid edges
+	unitDist, err := m.UnitDist()
+	assert.NoError(err)
+	gridDist, err := GridUnitDist(gridType, mSom.Grid.UShape, mSom.Grid.Size)
+	assert.NoError(err)
+	assert.True(mat64.Equal(gridDist, unitDist))
+	// both training algorithms work on toroid grid
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	origAlgorithm := tSom.Algorithm
+	tSom.Algorithm = "batch"
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	tSom.Algorithm = origAlgorithm
+	// topology measures
+	_, err = m.TopoProduct()
+	assert.NoError(err)
+	te, err := m.TopoError(dataMx)
+	assert.NoError(err)
+	assert.True(te >= 0.0)
+	_, err = m.TopoError(nil)
+	assert.Error(err)
+	// u-matrix is rendered using wrapped neighbourhoods
+	var buf bytes.Buffer
+	err = m.UMatrix(&buf, dataMx, map[int]int{}, "svg", gridType)
+	assert.NoError(err)
+	assert.True(strings.Contains(buf.String(), "<svg"))
+	mSom.Grid.Type = origType
+}
+
+func Test3DMap(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := &MapConfig{
+		Grid: &GridConfig{
+			Size:   []int{2, 2, 3},
+			Type:   "planar",
+			UShape: "rectangle",
+		},
+		Cb: &CbConfig{
+			Dim:      4,
+			InitFunc: RandInit,
+		},
+	}
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	rows, cols := m.Grid().Coords().Dims()
+	assert.Equal(12, rows)
+	assert.Equal(3, cols)
+	// both training algorithms work on 3D grid
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	origAlgorithm := tSom.Algorithm
+	tSom.Algorithm = "batch"
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	tSom.Algorithm = origAlgorithm
+	// u-matrix is rendered as one svg panel per z-layer
+	var buf bytes.Buffer
+	err = m.UMatrix(&buf, dataMx, map[int]int{}, "svg", "3D")
+	assert.NoError(err)
+	out := buf.String()
+	assert.Equal(4, strings.Count(out, "<svg "))
+	assert.True(strings.Contains(out, "<title>3D (z=0)</title>"))
+	assert.True(strings.Contains(out, "<title>3D (z=2)</title>"))
+	assert.Equal(12, strings.Count(out, "<polygon "))
+}
+
+func TestChainMap(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := &MapConfig{
+		Grid: &GridConfig{
+			Size:   []int{6},
+			Type:   "planar",
+			UShape: "hexagon",
+		},
+		Cb: &CbConfig{
+			Dim:      4,
+			InitFunc: LinInit,
+		},
+	}
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	rows, cols := m.Grid().Coords().Dims()
+	assert.Equal(6, rows)
+	assert.Equal(1, cols)
+	// chain units only neighbour their predecessor and successor
+	uDist, err := m.UnitDist()
+	assert.NoError(err)
+	assert.Equal(1.0, uDist.At(2, 1))
+	assert.Equal(1.0, uDist.At(2, 3))
+	assert.Equal(5.0, uDist.At(0, 5))
+	// both training algorithms work on 1D grid
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	origAlgorithm := tSom.Algorithm
+	tSom.Algorithm = "batch"
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	tSom.Algorithm = origAlgorithm
+	// u-matrix is rendered as a ribbon
+	var buf bytes.Buffer
+	err = m.UMatrix(&buf, dataMx, map[int]int{0: 1}, "svg", "1D")
+	assert.NoError(err)
+	out := buf.String()
+	assert.Equal(1, strings.Count(out, "<svg "))
+	assert.True(strings.Contains(out, `width="320" height="70" viewBox="0 0 320 70">`))
+	assert.Equal(6, strings.Count(out, "<polygon "))
+	// 1D toroid grid is a ring
+	mapCfg.Grid.Type = "toroid"
+	m, err = NewMap(mapCfg, dataMx)
+	assert.NoError(err)
+	uDist, err = m.UnitDist()
+	assert.NoError(err)
+	assert.Equal(1.0, uDist.At(0, 5))
+}
+
 func TestMapQuantError(t *testing.T) {
 	assert := assert.New(t)
 
@@ -205,3 +631,135 @@ func TestMapQuantError(t *testing.T) {
 	assert.NoError(err)
 	assert.True(qe > 0.0)
 }
+
+func TestSeededTraining(t *testing.T) {
+	assert := assert.New(t)
+
+	train := func(alg string, seed int64) *mat64.Dense {
+		mc := &MapConfig{
+			Grid: mSom.Grid,
+			Cb: &CbConfig{
+				Dim:      4,
+				InitFunc: RandInitSource(rand.NewSource(seed)),
+			},
+		}
+		m, err := NewMap(mc, dataMx)
+		assert.NoError(err)
+		tc := *tSom
+		tc.Algorithm, tc.Source = alg, rand.NewSource(seed)
+		assert.NoError(m.Train(&tc, dataMx, 50))
+		return m.codebook
+	}
+	for _, alg := range []string{"seq", "batch"} {
+		assert.Equal(train(alg, 1).RawMatrix().Data, train(alg, 1).RawMatrix().Data, alg)
+	}
+	assert.NotEqual(train("seq", 1).RawMatrix().Data, train("seq", 2).RawMatrix().Data)
+	// nil source
+	cb, err := RandInitSource(nil)(dataMx, []int{2, 2})
+	assert.Nil(cb)
+	assert.Error(err)
+}
+
+func TestTrainContext(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	cb := mat64.DenseCopyOf(m.codebook)
+	// cancelled training doesn't modify the map
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, alg := range []string{"seq", "batch"} {
+		tc := *tSom
+		tc.Algorithm = alg
+		assert.Equal(context.Canceled, m.TrainContext(ctx, &tc, dataMx, 100))
+		assert.True(mat64.Equal(cb, m.codebook))
+	}
+	// training completes before the deadline
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.NoError(m.TrainContext(ctx, tSom, dataMx, 100))
+	// GNG training
+	g, err := NewGNG(makeDefaultGNGConfig(), dataMx)
+	assert.NoError(err)
+	expCtx, expCancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer expCancel()
+	assert.Equal(context.DeadlineExceeded, g.TrainContext(expCtx, dataMx, 100))
+}
+
+func TestTrainOnEpochEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	rows, _ := dataMx.Dims()
+	epochs := []int{}
+	stats := []TrainStats{}
+	tc := *tSom
+	tc.OnEpochEnd = func(epoch int, s TrainStats) error {
+		epochs = append(epochs, epoch)
+		stats = append(stats, s)
+		return nil
+	}
+	// sequential epoch is as long as the data set
+	assert.NoError(m.Train(&tc, dataMx, 3*rows+1))
+	assert.Equal([]int{1, 2, 3}, epochs)
+	assert.Equal(rows, stats[0].Iteration)
+	assert.Equal(3*rows+1, stats[0].Iters)
+	assert.Equal(tc.radius(rows-1, 3*rows+1), stats[0].Radius)
+	assert.Equal(tc.lRate(rows-1, 3*rows+1), stats[0].LRate)
+	// hook stops batch training
+	tc.Algorithm = "batch"
+	epochs = epochs[:0]
+	tc.OnEpochEnd = func(epoch int, s TrainStats) error {
+		epochs = append(epochs, epoch)
+		if epoch == 2 {
+			return ErrStopTraining
+		}
+		return nil
+	}
+	assert.NoError(m.Train(&tc, dataMx, 10))
+	assert.Equal([]int{1, 2}, epochs)
+	// hook errors abort the training
+	tc.OnEpochEnd = func(epoch int, s TrainStats) error {
+		return errors.New("hook error")
+	}
+	assert.EqualError(m.Train(&tc, dataMx, 10), "hook error")
+}
+
+func TestTrainConscience(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	// the first unit is the closest to all samples
+	data := mat64.NewDense(4, 1, []float64{0.0, 0.1, 0.2, 0.3})
+	tc := &TrainConfig{
+		Algorithm: "seq",
+		RDecay:    "lin",
+		NeighbFn:  Bubble,
+		RSchedule: DecayFunc(func(iteration, totalIterations int) float64 { return 0.5 }),
+		LRate:     0.5,
+		LDecay:    "lin",
+	}
+	newMap := func() *Map {
+		return &Map{
+			codebook: mat64.NewDense(2, 1, []float64{0.0, 10.0}),
+			grid:     grid,
+			metric:   "euclidean",
+		}
+	}
+	m := newMap()
+	assert.NoError(m.Train(tc, data, 20))
+	assert.Equal(10.0, m.codebook.At(1, 0))
+	// conscience makes the second unit win too
+	tc.Conscience = &ConscienceConfig{Bias: 100.0, Rate: 0.1}
+	m = newMap()
+	assert.NoError(m.Train(tc, data, 20))
+	assert.True(m.codebook.At(1, 0) < 1.0)
+	// invalid conscience
+	tc.Conscience.Rate = 0.0
+	assert.EqualError(m.Train(tc, data, 20), "invalid conscience rate: 0.000000")
+	tc.Conscience = &ConscienceConfig{Bias: -1.0, Rate: 0.1}
+	assert.EqualError(m.Train(tc, data, 20), "invalid conscience bias: -1.000000")
+}
@@ -0,0 +1,132 @@
+package som
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// sompakTopologies maps SOM_PAK map topologies to unit shapes
+var sompakTopologies = map[string]string{
+	"hexa": "hexagon",
+	"rect": "rectangle",
+}
+
+// saveSOMPAK writes the codebook of the map in SOM_PAK .cod format to w. The header line holds the codebook
+// dimension, topology, x and y dimensions of the grid and the neighbourhood function which is gaussian unless
+// info holds bubble training neighbourhood. The codebook vectors follow one per line ordered by grid rows.
+// It fails with error if the map grid is not 2D planar grid of hexagons or rectangles or if the write to w fails.
+func (m Map) saveSOMPAK(w io.Writer, info *ModelInfo) error {
+	var topology string
+	for t, shape := range sompakTopologies {
+		if shape == m.grid.ushape {
+			topology = t
+		}
+	}
+	if m.grid.gtype != "planar" || len(m.grid.size) != 2 || topology == "" {
+		return fmt.Errorf("unsupported SOM_PAK grid: %s %s %v", m.grid.gtype, m.grid.ushape, m.grid.size)
+	}
+	neighb := "gaussian"
+	if info != nil && info.Train != nil && info.Train.NeighbFn == "bubble" {
+		neighb = "bubble"
+	}
+	rows, cols := m.grid.size[0], m.grid.size[1]
+	_, dim := m.codebook.Dims()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %s %d %d %s\n", dim, topology, cols, rows, neighb)
+	// SOM_PAK stores units by grid rows, map units are stored by grid columns
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			for i, v := range m.codebook.RawRowView(x*rows + y) {
+				if i > 0 {
+					bw.WriteByte(' ')
+				}
+				bw.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+			}
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// loadSOMPAK reads the map saved in SOM_PAK .cod format from r. Comment lines starting with # and unit labels
+// following the codebook vector values are ignored. The map uses euclidean metric and the returned info holds
+// the neighbourhood function of the model or it is nil if the header does not specify it. It fails with error if the header or the codebook vectors are invalid.
+func loadSOMPAK(r io.Reader) (*Map, *ModelInfo, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<26)
+	// next returns the fields of the next line which is not empty or a comment
+	next := func() ([]string, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				return strings.Fields(line), nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	header, err := next()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(header) < 4 {
+		return nil, nil, fmt.Errorf("invalid SOM_PAK header: %v", header)
+	}
+	dim, errDim := strconv.Atoi(header[0])
+	cols, errCols := strconv.Atoi(header[2])
+	rows, errRows := strconv.Atoi(header[3])
+	if errDim != nil || errCols != nil || errRows != nil || dim <= 0 || cols <= 0 || rows <= 0 {
+		return nil, nil, fmt.Errorf("invalid SOM_PAK header: %v", header)
+	}
+	uShape, ok := sompakTopologies[header[1]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported SOM_PAK topology: %s", header[1])
+	}
+	var info *ModelInfo
+	if len(header) > 4 {
+		switch header[4] {
+		case "bubble", "gaussian":
+			info = &ModelInfo{Train: &TrainInfo{NeighbFn: header[4]}}
+		default:
+			return nil, nil, fmt.Errorf("unsupported SOM_PAK neighbourhood: %s", header[4])
+		}
+	}
+	grid, err := NewGrid(&GridConfig{Size: []int{rows, cols}, Type: "planar", UShape: uShape})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codebook := mat64.NewDense(rows*cols, dim, nil)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			fields, err := next()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(fields) < dim {
+				return nil, nil, fmt.Errorf("invalid number of codebook vector values: %d, expected: %d", len(fields), dim)
+			}
+			vec := codebook.RawRowView(x*rows + y)
+			for i := range vec {
+				if vec[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	return &Map{
+		codebook: codebook,
+		grid:     grid,
+		metric:   "euclidean",
+	}, info, nil
+}
@@ -0,0 +1,75 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSOMPAK(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 3}, Type: "planar", UShape: "hexagon"})
+	assert.NoError(err)
+	// units are stored by columns
+	m := Map{codebook: mat64.NewDense(6, 2, []float64{0, 0.5, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 5.5}), grid: grid, metric: "euclidean"}
+	var buf bytes.Buffer
+	assert.NoError(m.Save("sompak", &buf, nil))
+	assert.Equal("2 hexa 3 2 gaussian\n0 0.5\n2 2.5\n4 4.5\n1 1.5\n3 3.5\n5 5.5\n", buf.String())
+	buf.Reset()
+	assert.NoError(m.Save("sompak", &buf, &ModelInfo{Train: &TrainInfo{NeighbFn: "bubble"}}))
+	assert.True(strings.HasPrefix(buf.String(), "2 hexa 3 2 bubble\n"))
+	// the model can be loaded back
+	loaded, info, err := Load("sompak", &buf)
+	assert.NoError(err)
+	assert.True(mat64.Equal(m.codebook, loaded.codebook))
+	assert.Equal(m.grid.size, loaded.grid.size)
+	assert.True(mat64.Equal(m.grid.coords, loaded.grid.coords))
+	assert.Equal("bubble", info.Train.NeighbFn)
+	// unsupported grids
+	for _, c := range []*GridConfig{
+		{Size: []int{2, 3}, Type: "planar", UShape: "triangle"},
+		{Size: []int{2, 3}, Type: "toroid", UShape: "hexagon"},
+		{Size: []int{6}, Type: "planar", UShape: "hexagon"},
+	} {
+		grid, err := NewGrid(c)
+		assert.NoError(err)
+		m.grid = grid
+		assert.Error(m.Save("sompak", &buf, nil))
+	}
+	m.grid, _ = NewGrid(&GridConfig{Size: []int{2, 3}, Type: "planar", UShape: "rectangle"})
+	assert.Error(m.Save("sompak", failingWriter{}, nil))
+}
+
+func TestLoadSOMPAK(t *testing.T) {
+	assert := assert.New(t)
+
+	// comments, empty lines and labels are skipped
+	cod := "# generated by som_pak\n2 rect 2 1\n\n0 1 label\n#n x y\n2 3\n"
+	m, info, err := Load("sompak", strings.NewReader(cod))
+	assert.NoError(err)
+	assert.Nil(info)
+	assert.Equal([]int{1, 2}, m.grid.size)
+	assert.Equal("rectangle", m.grid.ushape)
+	assert.Equal("euclidean", m.metric)
+	assert.Equal([]float64{0, 1, 2, 3}, m.codebook.RawMatrix().Data)
+
+	testCases := []string{
+		"",
+		"2 rect 2\n",
+		"x rect 2 1\n0 1\n2 3\n",
+		"2 rect 0 1\n",
+		"2 cyl 2 1\n0 1\n2 3\n",
+		"2 rect 2 1 cutgauss\n0 1\n2 3\n",
+		"2 rect 2 1\n0 1\n",
+		"2 rect 2 1\n0 1\n2\n",
+		"2 rect 2 1\n0 1\n2 x\n",
+	}
+	for _, tc := range testCases {
+		_, _, err := Load("sompak", strings.NewReader(tc))
+		assert.Error(err, tc)
+	}
+}
@@ -0,0 +1,185 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// phi is the golden ratio
+var phi = (1.0 + math.Sqrt(5.0)) / 2.0
+
+// icosahedron vertices
+var icoVertices = [][]float64{
+	{-1, phi, 0}, {1, phi, 0}, {-1, -phi, 0}, {1, -phi, 0},
+	{0, -1, phi}, {0, 1, phi}, {0, -1, -phi}, {0, 1, -phi},
+	{phi, 0, -1}, {phi, 0, 1}, {-phi, 0, -1}, {-phi, 0, 1},
+}
+
+// icosahedron faces stored as triplets of vertex indices
+var icoFaces = [][]int{
+	{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+	{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+	{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+	{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+}
+
+// icoEdgeAngle is the angle between two neighbouring icosahedron vertices
+var icoEdgeAngle = math.Atan(2.0)
+
+// SphereCoords returns a matrix which contains coordinates of all units of spherical SOM grid
+// stored row by row. The units are placed in the vertices of geodesic grid created by subdividing
+// every edge of icosahedron into dims[0] segments and projecting the new vertices onto unit sphere.
+// The returned matrix has 10*dims[0]^2+2 rows and 3 columns. uShape is ignored as the units of
+// geodesic grid are always either hexagons or pentagons. SphereCoords fails with error if dims
+// does not contain exactly one positive number.
+func SphereCoords(uShape string, dims []int) (*mat64.Dense, error) {
+	if len(dims) != 1 || dims[0] <= 0 {
+		return nil, fmt.Errorf("incorrect SOM sphere grid dimensions supplied: %v", dims)
+	}
+	coords, _ := sphereLattice(dims[0])
+
+	return coords, nil
+}
+
+// latticeVertex identifies a vertex of geodesic grid by the icosahedron vertices it is interpolated from
+// and their integer weights which sum up to the subdivision frequency. The pairs of vertex indices and
+// weights are sorted by vertex index and padded by -1 indices, so the vertices shared by neighbouring
+// faces have the same identity regardless of the rounding of their coordinates.
+type latticeVertex [3][2]int
+
+// newLatticeVertex returns identity of the vertex of the given face interpolated with the given weights
+func newLatticeVertex(face []int, weights [3]int) latticeVertex {
+	var v latticeVertex
+	for k := range v {
+		v[k] = [2]int{-1, 0}
+		if weights[k] > 0 {
+			v[k] = [2]int{face[k], weights[k]}
+		}
+	}
+	sort.Slice(v[:], func(a, b int) bool { return v[a][0] < v[b][0] })
+	return v
+}
+
+// sphereLattice returns the coordinates of units of geodesic grid with subdivision frequency freq stored
+// row by row and the indices of the immediate neighbours of every unit.
+func sphereLattice(freq int) (*mat64.Dense, [][]int) {
+	mUnits := 10*freq*freq + 2
+	coords := mat64.NewDense(mUnits, 3, nil)
+	// units stores the indices of already generated vertices shared by neighbouring faces
+	units := make(map[latticeVertex]int)
+	adj := make([]map[int]bool, mUnits)
+	for _, face := range icoFaces {
+		a, b, c := icoVertices[face[0]], icoVertices[face[1]], icoVertices[face[2]]
+		// unit returns the index of the face vertex interpolated with weights i and j of the vertices b and c
+		unit := func(i, j int) int {
+			key := newLatticeVertex(face, [3]int{freq - i - j, i, j})
+			if u, ok := units[key]; ok {
+				return u
+			}
+			v := make([]float64, 3)
+			for k := 0; k < 3; k++ {
+				v[k] = a[k] + (b[k]-a[k])*float64(i)/float64(freq) + (c[k]-a[k])*float64(j)/float64(freq)
+			}
+			// project the vertex onto unit sphere
+			norm := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+			for k := 0; k < 3; k++ {
+				v[k] /= norm
+			}
+			u := len(units)
+			units[key] = u
+			coords.SetRow(u, v)
+			adj[u] = make(map[int]bool)
+			return u
+		}
+		for i := 0; i <= freq; i++ {
+			for j := 0; j <= freq-i; j++ {
+				u := unit(i, j)
+				// every vertex is connected to its lattice neighbours in the face
+				for _, n := range [][2]int{{i + 1, j}, {i, j + 1}, {i + 1, j - 1}} {
+					if n[1] < 0 || n[0]+n[1] > freq {
+						continue
+					}
+					nu := unit(n[0], n[1])
+					adj[u][nu], adj[nu][u] = true, true
+				}
+			}
+		}
+	}
+	neighbs := make([][]int, mUnits)
+	for u := range neighbs {
+		for n := range adj[u] {
+			neighbs[u] = append(neighbs[u], n)
+		}
+		sort.Ints(neighbs[u])
+	}
+
+	return coords, neighbs
+}
+
+// sphereRadius returns the radius of the sphere of geodesic grid with subdivision frequency freq.
+// The radius is chosen so that the distance between two neighbouring units is approximately 1.
+func sphereRadius(freq int) float64 {
+	return float64(freq) / icoEdgeAngle
+}
+
+// sphereDistMx computes a matrix of great-circle distances between units stored in coords rows
+// on a sphere of the given radius
+func sphereDistMx(coords *mat64.Dense, radius float64) (*mat64.Dense, error) {
+	return distanceMx(coords, func(a, b []float64) (float64, error) {
+		return sphereDist(a, b, radius), nil
+	})
+}
+
+// sphereDist computes great-circle distance between units a and b on a sphere of the given radius
+func sphereDist(a, b []float64, radius float64) float64 {
+	dot := a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+	// guard against rounding errors
+	dot = math.Max(-1.0, math.Min(1.0, dot))
+	return radius * math.Acos(dot)
+}
+
+// sphereSVG creates an SVG element which contains the U-Matrix of spherical grid units
+// projected onto a plane using equirectangular projection. Each unit is drawn as a circle.
+func sphereSVG(grid *Grid, umatrix []float64, minDistance, maxDistance float64, s unitStyle) svgElement {
+	// scale the coord grid to something visible
+	mul, off := s.cellSize(), s.margin()
+	radius := sphereRadius(grid.size[0])
+	width := 2 * math.Pi * radius * mul
+	height := math.Pi * radius * mul
+
+	rows, _ := grid.coords.Dims()
+	svgElem := svgElement{
+		Width:    width + 2*off,
+		Height:   height + 2*off,
+		Polygons: make([]interface{}, 0, rows*2),
+	}
+	centers := make(map[int][2]float64)
+	for row := 0; row < rows; row++ {
+		coord := grid.coords.RawRowView(row)
+		// longitude and latitude of the unit
+		lon := math.Atan2(coord[1], coord[0])
+		lat := math.Asin(math.Max(-1.0, math.Min(1.0, coord[2])))
+		x := (lon+math.Pi)/(2*math.Pi)*width + off
+		y := (math.Pi/2-lat)/math.Pi*height + off
+
+		r, g, b := s.unitRGB(row, umatrix, minDistance, maxDistance)
+		svgElem.Polygons = append(svgElem.Polygons, circle{
+			Cx:      x,
+			Cy:      y,
+			R:       0.45 * mul,
+			Class:   "unit",
+			Style:   s.fillStyle(r, g, b),
+			Title:   s.tooltip(row),
+			Animate: s.unitAnimation(row, minDistance, maxDistance),
+		})
+		svgElem.Polygons = append(svgElem.Polygons, s.unitSVG(row, x, y, mul)...)
+		centers[row] = [2]float64{x, y}
+	}
+
+	svgElem.Polygons = append(svgElem.Polygons, s.trajectorySVG(centers, mul)...)
+
+	return svgElem
+}
@@ -0,0 +1,134 @@
+package som
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSphereCoords(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, freq := range []int{1, 2, 3, 4} {
+		coords, err := SphereCoords("hexagon", []int{freq})
+		assert.NoError(err)
+		rows, cols := coords.Dims()
+		assert.Equal(10*freq*freq+2, rows)
+		assert.Equal(3, cols)
+		// all units lie on unit sphere
+		for i := 0; i < rows; i++ {
+			assert.InDelta(1.0, mat64.Norm(coords.RowView(i), 2), 0.0001)
+		}
+	}
+	// incorrect dimensions
+	for _, dims := range [][]int{nil, {}, {0}, {-1}, {2, 2}} {
+		coords, err := SphereCoords("hexagon", dims)
+		assert.Nil(coords)
+		assert.Error(err)
+	}
+}
+
+func TestSphereGridUnits(t *testing.T) {
+	assert := assert.New(t)
+
+	for freq := 1; freq <= 20; freq++ {
+		grid, err := NewGrid(&GridConfig{Size: []int{freq}, Type: "sphere", UShape: "hexagon"})
+		assert.NoError(err, freq)
+		// every shared vertex is generated only once
+		assert.Equal(10*freq*freq+2, grid.Units(), freq)
+		coords, neighbs := sphereLattice(freq)
+		assert.True(mat64.Equal(coords, grid.coords), freq)
+		// geodesic grid always contains 12 pentagons, all the other units are hexagons
+		pentagons := 0
+		for u, n := range neighbs {
+			switch len(n) {
+			case 5:
+				pentagons++
+			case 6:
+			default:
+				assert.Fail("invalid number of unit neighbours", "freq: %d, unit: %d, neighbours: %d", freq, u, len(n))
+			}
+			// the neighbours are close to each other
+			for _, v := range n {
+				d := sphereDist(coords.RawRowView(u), coords.RawRowView(v), sphereRadius(freq))
+				assert.InDelta(1.0, d, 0.25, freq)
+			}
+		}
+		assert.Equal(12, pentagons, freq)
+	}
+}
+
+func TestSphereUnitDist(t *testing.T) {
+	assert := assert.New(t)
+
+	freq := 3
+	uDist, err := GridUnitDist("sphere", "hexagon", []int{freq})
+	assert.NoError(err)
+	rows, _ := uDist.Dims()
+	// every unit has either 5 or 6 immediate neighbours
+	pentagons := 0
+	for i := 0; i < rows; i++ {
+		neighbs := 0
+		for _, d := range uDist.RawRowView(i) {
+			if d > 0.0 && d < math.Sqrt2*1.01 {
+				neighbs++
+				assert.InDelta(1.0, d, 0.25)
+			}
+		}
+		assert.True(neighbs == 5 || neighbs == 6)
+		if neighbs == 5 {
+			pentagons++
+		}
+	}
+	// geodesic grid always contains 12 pentagons
+	assert.Equal(12, pentagons)
+	// no unit is further than half of the circumference
+	assert.True(mat64.Max(uDist) <= math.Pi*sphereRadius(freq)+0.0001)
+}
+
+func TestSphereMap(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := &MapConfig{
+		Grid: &GridConfig{
+			Size:   []int{2},
+			Type:   "sphere",
+			UShape: "hexagon",
+		},
+		Cb: &CbConfig{
+			Dim:      4,
+			InitFunc: LinInit,
+		},
+	}
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	assert.Equal(42, m.Grid().Units())
+	cbRows, _ := m.Codebook().Dims()
+	assert.Equal(42, cbRows)
+	// both training algorithms work on spherical grid
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	origAlgorithm := tSom.Algorithm
+	tSom.Algorithm = "batch"
+	err = m.Train(tSom, dataMx, 100)
+	assert.NoError(err)
+	tSom.Algorithm = origAlgorithm
+	// u-matrix is rendered using map projection
+	var buf bytes.Buffer
+	err = m.UMatrix(&buf, dataMx, map[int]int{0: 1}, "svg", "Sphere")
+	assert.NoError(err)
+	out := buf.String()
+	assert.Equal(1, strings.Count(out, "<svg "))
+	assert.Equal(42, strings.Count(out, "<circle "))
+	assert.Equal(1, strings.Count(out, "<text "))
+	// incorrect sphere dimensions
+	mapCfg.Grid.Size = []int{2, 2}
+	m, err = NewMap(mapCfg, dataMx)
+	assert.Nil(m)
+	assert.Error(err)
+}
@@ -0,0 +1,133 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// SupervisedMap is a supervised Self Organizing Map
+type SupervisedMap struct {
+	// m is SOM trained on data extended by class indicator dimensions
+	m *Map
+	// classes holds sorted class labels of the class indicator dimensions
+	classes []int
+	// dim is the number of data features
+	dim int
+}
+
+// NewSupervisedMap creates and trains new supervised SOM based on the provided configuration.
+// Every data sample is extended by class indicator dimensions: the dimension of the sample class
+// is set to ClassWeight and the others to 0, so the classes shape the map during the training.
+// Indicator dimensions of samples missing in classes are treated as missing NaN values.
+// The indicator dimensions are stripped from the map used for inference.
+// NewSupervisedMap returns error if the provided configuration is not valid, the data is nil,
+// classes are empty or if the map could not be created or trained.
+func NewSupervisedMap(c *SupervisedConfig, data *mat64.Dense, classes map[int]int) (*SupervisedMap, error) {
+	// if input data is empty throw error
+	if data == nil {
+		return nil, fmt.Errorf("invalid input data: %v", data)
+	}
+	if err := validateSupervisedConfig(c); err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("invalid data classes: %v", classes)
+	}
+	// sorted class labels
+	labels := []int{}
+	index := make(map[int]int)
+	for _, class := range classes {
+		if _, ok := index[class]; !ok {
+			index[class] = 0
+			labels = append(labels, class)
+		}
+	}
+	sort.Ints(labels)
+	for i, class := range labels {
+		index[class] = i
+	}
+	// extend data by class indicator dimensions
+	rows, cols := data.Dims()
+	augData := mat64.NewDense(rows, cols+len(labels), nil)
+	for i := 0; i < rows; i++ {
+		row := augData.RawRowView(i)
+		copy(row, data.RawRowView(i))
+		class, ok := classes[i]
+		for j := range labels {
+			switch {
+			case !ok:
+				row[cols+j] = math.NaN()
+			case index[class] == j:
+				row[cols+j] = c.ClassWeight
+			}
+		}
+	}
+	cb := *c.Map.Cb
+	cb.Dim = cols + len(labels)
+	m, err := NewMap(&MapConfig{Grid: c.Map.Grid, Cb: &cb}, augData)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Train(c.Train, augData, c.Iters); err != nil {
+		return nil, err
+	}
+
+	return &SupervisedMap{
+		m:       m,
+		classes: labels,
+		dim:     cols,
+	}, nil
+}
+
+// Map returns SOM whose codebook vectors don't contain class indicator dimensions.
+// It can be used for inference on unlabeled data.
+func (s SupervisedMap) Map() *Map {
+	units, _ := s.m.codebook.Dims()
+	return &Map{
+		codebook: mat64.DenseCopyOf(s.m.codebook.View(0, 0, units, s.dim)),
+		grid:     s.m.grid,
+		metric:   s.m.metric,
+	}
+}
+
+// Classes returns sorted class labels the map has been trained on
+func (s SupervisedMap) Classes() []int {
+	return s.classes
+}
+
+// UnitClasses returns a slice which contains class labels of all map units.
+// Unit class is the class of its highest class indicator dimension.
+func (s SupervisedMap) UnitClasses() []int {
+	units, _ := s.m.codebook.Dims()
+	unitClasses := make([]int, units)
+	for i := range unitClasses {
+		indicators := s.m.codebook.RawRowView(i)[s.dim:]
+		best := 0
+		for j, v := range indicators {
+			if v > indicators[best] {
+				best = j
+			}
+		}
+		unitClasses[i] = s.classes[best]
+	}
+	return unitClasses
+}
+
+// Classify returns a slice which contains class labels of data rows.
+// Every row is assigned the class of its BMU found without using class indicator dimensions.
+// It returns error if the data is nil or if its dimensions don't match the map codebook.
+func (s SupervisedMap) Classify(data *mat64.Dense) ([]int, error) {
+	bmus, err := s.Map().BMUs(data)
+	if err != nil {
+		return nil, err
+	}
+	unitClasses := s.UnitClasses()
+	classes := make([]int, len(bmus))
+	for i, bmu := range bmus {
+		classes[i] = unitClasses[bmu]
+	}
+	return classes, nil
+}
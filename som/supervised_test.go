@@ -0,0 +1,69 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervisedMap(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(6, 2, []float64{
+		0.0, 0.1,
+		0.1, 0.0,
+		0.1, 0.1,
+		5.0, 5.1,
+		5.1, 5.0,
+		5.1, 5.1,
+	})
+	// last sample is unlabeled
+	classes := map[int]int{0: 3, 1: 3, 2: 3, 3: 7, 4: 7}
+	c := &SupervisedConfig{
+		Map: &MapConfig{
+			Grid: &GridConfig{
+				Size:   []int{2, 2},
+				Type:   "planar",
+				UShape: "hexagon",
+			},
+			Cb: &CbConfig{
+				Dim:      2,
+				InitFunc: RandInit,
+			},
+		},
+		Train: &TrainConfig{
+			Algorithm: "batch",
+			Radius:    1.0,
+			RDecay:    "lin",
+			NeighbFn:  Bubble,
+			LRate:     0.5,
+			LDecay:    "lin",
+		},
+		Iters:       20,
+		ClassWeight: 10.0,
+	}
+	s, err := NewSupervisedMap(c, data, classes)
+	assert.NoError(err)
+	assert.NotNil(s)
+	assert.Equal([]int{3, 7}, s.Classes())
+	assert.Len(s.UnitClasses(), 4)
+	// inference map doesn't contain class indicator dimensions
+	rows, cols := s.Map().Codebook().Dims()
+	assert.Equal(4, rows)
+	assert.Equal(2, cols)
+	pred, err := s.Classify(mat64.NewDense(2, 2, []float64{0.05, 0.05, 5.05, 5.05}))
+	assert.NoError(err)
+	assert.Equal([]int{3, 7}, pred)
+	// invalid parameters
+	s, err = NewSupervisedMap(c, nil, classes)
+	assert.Nil(s)
+	assert.Error(err)
+	s, err = NewSupervisedMap(c, data, nil)
+	assert.Nil(s)
+	assert.Error(err)
+	c.ClassWeight = 0.0
+	s, err = NewSupervisedMap(c, data, classes)
+	assert.Nil(s)
+	assert.EqualError(err, "invalid class weight: 0.000000")
+}
@@ -0,0 +1,168 @@
+package som
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// surfaceMesh is a polygon mesh of the U-Matrix surface
+type surfaceMesh struct {
+	// vertices holds x, y, z coordinates of the mesh vertices
+	vertices [][3]float64
+	// colors holds r, g, b colors of the mesh vertices
+	colors [][3]int
+	// faces holds counter-clockwise ordered vertex indices of the mesh faces seen from their outside
+	faces [][]int
+}
+
+// UMatrixSurface exports the U-Matrix of the given codebook as a 3D surface mesh in a given format
+// and writes it to writer. Every unit is a prism standing on its grid cell whose height is the unit
+// U-Matrix value multiplied by scale, so the cluster boundaries show up as ridges.
+// The grid units are one unit apart in x, y plane. Supported formats are Wavefront "obj"
+// and ASCII "ply" whose vertices are colored by the grayscale U-Matrix colors.
+// It accepts the same parameters as UMatrixSVG and fails with error if the format is not supported,
+// the grid coordinates could not be computed or if the codebook does not match the grid.
+// Only 1D and 2D planar grids can be exported.
+func UMatrixSurface(codebook *mat64.Dense, dims []int, uShape, metric, format string, scale float64, writer io.Writer) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+
+	return umatrixSurface(codebook, grid, metric, format, scale, writer, unitStyle{})
+}
+
+// Surface exports SOM u-matrix as a 3D surface mesh in a given format and writes it to w in the same way
+// as UMatrixSurface. The u-matrix is smoothed and colored as configured by the map render configuration.
+// Currently only "obj" and "ply" formats are supported.
+func (m Map) Surface(w io.Writer, format string, scale float64) error {
+	return umatrixSurface(m.codebook, m.grid, m.metric, format, scale, w, m.render.style())
+}
+
+// umatrixSurface exports the U-Matrix of the given codebook and grid as a 3D surface mesh
+func umatrixSurface(codebook *mat64.Dense, grid *Grid, metric, format string, scale float64, writer io.Writer, s unitStyle) error {
+	if format != "obj" && format != "ply" {
+		return fmt.Errorf("invalid format %s", format)
+	}
+	if grid.gtype == "sphere" || grid.gtype == "graph" || len(grid.size) > 2 {
+		return fmt.Errorf("unsupported surface grid: %s %v", grid.gtype, grid.size)
+	}
+	umatrix, minDistance, maxDistance, err := s.umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return err
+	}
+	mesh := umatrixMesh(grid, umatrix, minDistance, maxDistance, scale, s)
+
+	w := bufio.NewWriter(writer)
+	if format == "obj" {
+		mesh.writeOBJ(w)
+	} else {
+		mesh.writePLY(w)
+	}
+	return w.Flush()
+}
+
+// umatrixMesh returns the mesh of unit prisms whose heights are the U-Matrix values multiplied by scale
+func umatrixMesh(grid *Grid, umatrix []float64, minDistance, maxDistance, scale float64, s unitStyle) *surfaceMesh {
+	mesh := &surfaceMesh{}
+	units, _ := grid.coords.Dims()
+	for row := 0; row < units; row++ {
+		var vertices [][2]float64
+		if len(grid.size) == 1 {
+			x := grid.coords.At(row, 0)
+			vertices = [][2]float64{{x, 0}, {x + 1, 0}, {x + 1, 1}, {x, 1}, {x, 0}}
+		} else {
+			x, y := grid.coords.At(row, 0), grid.coords.At(row, 1)
+			vertices = unitPolygon(grid.size, grid.ushape, row, x, y, 1.0)
+		}
+		// the y axis points up in 3D space, the last vertex closes the polygon
+		base := make([][2]float64, len(vertices)-1)
+		for i := range base {
+			base[i] = [2]float64{vertices[i][0], -vertices[i][1]}
+		}
+		r, g, b := s.unitRGB(row, umatrix, minDistance, maxDistance)
+		mesh.addPrism(base, scale*umatrix[row], [3]int{r, g, b})
+	}
+	return mesh
+}
+
+// addPrism adds the prism of the given height standing on the base polygon to the mesh.
+// Its vertices are colored by c.
+func (mesh *surfaceMesh) addPrism(base [][2]float64, height float64, c [3]int) {
+	// the faces are oriented counter-clockwise
+	area := 0.0
+	for i := range base {
+		j := (i + 1) % len(base)
+		area += base[i][0]*base[j][1] - base[j][0]*base[i][1]
+	}
+	if area < 0 {
+		for i, j := 0, len(base)-1; i < j; i, j = i+1, j-1 {
+			base[i], base[j] = base[j], base[i]
+		}
+	}
+	n := len(base)
+	first := len(mesh.vertices)
+	for _, z := range []float64{0, height} {
+		for _, p := range base {
+			mesh.vertices = append(mesh.vertices, [3]float64{p[0], p[1], z})
+			mesh.colors = append(mesh.colors, c)
+		}
+	}
+	bottom, top := make([]int, n), make([]int, n)
+	for i := 0; i < n; i++ {
+		bottom[i], top[i] = first+n-1-i, first+n+i
+		j := (i + 1) % n
+		mesh.faces = append(mesh.faces, []int{first + i, first + j, first + n + j, first + n + i})
+	}
+	mesh.faces = append(mesh.faces, top, bottom)
+}
+
+// writeOBJ writes the mesh in Wavefront OBJ format to w
+func (mesh *surfaceMesh) writeOBJ(w io.Writer) {
+	fmt.Fprintln(w, "# U-Matrix surface")
+	for _, v := range mesh.vertices {
+		fmt.Fprintf(w, "v %f %f %f\n", v[0], v[1], v[2])
+	}
+	for _, face := range mesh.faces {
+		fmt.Fprint(w, "f")
+		// OBJ vertex indices start at 1
+		for _, i := range face {
+			fmt.Fprintf(w, " %d", i+1)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writePLY writes the mesh in ASCII PLY format with colored vertices to w
+func (mesh *surfaceMesh) writePLY(w io.Writer) {
+	fmt.Fprintln(w, "ply")
+	fmt.Fprintln(w, "format ascii 1.0")
+	fmt.Fprintln(w, "comment U-Matrix surface")
+	fmt.Fprintf(w, "element vertex %d\n", len(mesh.vertices))
+	for _, p := range []string{"float x", "float y", "float z", "uchar red", "uchar green", "uchar blue"} {
+		fmt.Fprintf(w, "property %s\n", p)
+	}
+	fmt.Fprintf(w, "element face %d\n", len(mesh.faces))
+	fmt.Fprintln(w, "property list uchar int vertex_indices")
+	fmt.Fprintln(w, "end_header")
+	for i, v := range mesh.vertices {
+		c := mesh.colors[i]
+		fmt.Fprintf(w, "%f %f %f %d %d %d\n", v[0], v[1], v[2], c[0], c[1], c[2])
+	}
+	for _, face := range mesh.faces {
+		fmt.Fprintf(w, "%d", len(face))
+		for _, i := range face {
+			fmt.Fprintf(w, " %d", i)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,69 @@
+package som
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUMatrixSurface(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+	umatrix, err := UMatrix(cbook, []int{2, 2}, "rectangle", "euclidean")
+	assert.NoError(err)
+	// every rectangle unit is a box of 8 vertices and 6 faces
+	var buf bytes.Buffer
+	assert.NoError(UMatrixSurface(cbook, []int{2, 2}, "rectangle", "euclidean", "obj", 2.0, &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal("# U-Matrix surface", lines[0])
+	assert.Len(lines, 1+32+24)
+	assert.Equal("v 0.500000 -0.500000 0.000000", lines[1])
+	// top vertices are raised by the scaled unit value
+	top := []float64{}
+	for _, l := range lines[5:9] {
+		var x, y, z float64
+		_, err := fmt.Sscanf(l, "v %f %f %f", &x, &y, &z)
+		assert.NoError(err)
+		top = append(top, z)
+	}
+	assert.InDeltaSlice([]float64{2 * umatrix.At(0, 0), 2 * umatrix.At(0, 0), 2 * umatrix.At(0, 0), 2 * umatrix.At(0, 0)}, top, 1e-5)
+	assert.Equal("f 1 2 6 5", lines[33])
+	// faces are ordered counter-clockwise when seen from above
+	assert.Equal("f 5 6 7 8", lines[37])
+	assert.Equal("f 4 3 2 1", lines[38])
+	// hexagons have 6 walls
+	buf.Reset()
+	assert.NoError(UMatrixSurface(cbook, []int{2, 2}, "hexagon", "euclidean", "ply", 1.0, &buf))
+	out := buf.String()
+	assert.True(strings.HasPrefix(out, "ply\nformat ascii 1.0\n"))
+	assert.Contains(out, "element vertex 48\n")
+	assert.Contains(out, "element face 32\n")
+	assert.Contains(out, "property uchar red\n")
+	assert.Equal(8, strings.Count(out, "\n6 "))
+	// 1D grid units are squares
+	buf.Reset()
+	assert.NoError(UMatrixSurface(cbook, []int{4}, "rectangle", "euclidean", "obj", 1.0, &buf))
+	assert.Equal(24, strings.Count(buf.String(), "\nf "))
+	// invalid parameters
+	assert.Error(UMatrixSurface(cbook, []int{2, 2}, "rectangle", "euclidean", "stl", 1.0, &buf))
+	assert.Error(UMatrixSurface(cbook, []int{2, 2}, "foo", "euclidean", "obj", 1.0, &buf))
+	assert.Error(UMatrixSurface(cbook, []int{2, 3}, "rectangle", "euclidean", "obj", 1.0, &buf))
+	assert.Error(UMatrixSurface(mat64.NewDense(8, 1, nil), []int{2, 2, 2}, "rectangle", "euclidean", "obj", 1.0, &buf))
+	assert.Error(UMatrixSurface(cbook, []int{2, 2}, "rectangle", "euclidean", "obj", 1.0, failingWriter{}))
+}
+
+func TestMapSurface(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	var buf bytes.Buffer
+	assert.NoError(m.Surface(&buf, "ply", 1.0))
+	assert.Contains(buf.String(), "end_header\n")
+	assert.Error(m.Surface(&buf, "svg", 1.0))
+}
@@ -0,0 +1,232 @@
+package som
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// svgNamespace is the XML namespace of SVG documents
+const svgNamespace = "http://www.w3.org/2000/svg"
+
+// svgFrame describes an svg element: the document root element carries the SVG namespace and view box,
+// nested elements are placed at their x, y position inside the root element.
+type svgFrame struct {
+	x, y          float64
+	width, height float64
+	// root marks the document root element
+	root bool
+	// title and desc are the title and description of the element, they are omitted if empty
+	title, desc string
+}
+
+// svgStyle is the style sheet of an SVG document
+type svgStyle struct {
+	XMLName xml.Name `xml:"style"`
+	Type    string   `xml:"type,attr"`
+	Text    string   `xml:",chardata"`
+}
+
+// svgStream encodes SVG elements to the underlying writer as they are produced, so large maps
+// are never held in memory as a whole. The first encoding error is recorded and any further
+// elements are discarded; it is returned by flush.
+type svgStream struct {
+	w   *bufio.Writer
+	enc *xml.Encoder
+	err error
+	// css replaces inline styles of the encoded elements by classes of the document style sheet
+	css bool
+	// classes maps the replaced inline styles to their classes listed in the order of styles
+	classes map[string]string
+	styles  []string
+	// depth is the number of open svg elements
+	depth int
+}
+
+// newSVGStream returns a new svgStream which writes the encoded elements to w
+func newSVGStream(w io.Writer) *svgStream {
+	bw := bufio.NewWriter(w)
+	return &svgStream{w: bw, enc: xml.NewEncoder(bw)}
+}
+
+// document streams an SVG document with the given title and description which contains rows of panels of the
+// given sizes: the rows are stacked vertically and the panels of every row are placed next to each other.
+// draw is called for every panel with its index counted across all rows and the frame the panel must be
+// rendered into. A single panel is rendered directly into the document root element.
+func (st *svgStream) document(title, desc string, rows [][]svgFrame, draw func(i int, frame svgFrame)) {
+	var panels []svgFrame
+	root := svgFrame{root: true, title: title, desc: desc}
+	for _, row := range rows {
+		x, height := 0.0, 0.0
+		for _, frame := range row {
+			frame.x, frame.y = x, root.height
+			panels = append(panels, frame)
+			x += frame.width
+			height = math.Max(height, frame.height)
+		}
+		root.width = math.Max(root.width, x)
+		root.height += height
+	}
+	if len(panels) == 1 {
+		frame := panels[0]
+		frame.root, frame.title, frame.desc = true, title, desc
+		draw(0, frame)
+		return
+	}
+	st.start(root, unitStyle{})
+	for i, frame := range panels {
+		draw(i, frame)
+	}
+	st.end()
+}
+
+// stackedRows returns rows of panels which contain one panel each, i.e. the panels are stacked vertically
+func stackedRows(panels ...svgFrame) [][]svgFrame {
+	rows := make([][]svgFrame, len(panels))
+	for i := range panels {
+		rows[i] = panels[i : i+1]
+	}
+	return rows
+}
+
+// encode encodes the given elements in order
+func (st *svgStream) encode(elems ...interface{}) {
+	for _, elem := range elems {
+		if st.err != nil {
+			return
+		}
+		st.err = st.enc.Encode(st.classed(elem))
+	}
+}
+
+// classed returns elem whose inline style is replaced by a class of the document style sheet if st uses CSS classes.
+// Otherwise it returns elem without its class attribute so that its inline style is the only one which applies.
+func (st *svgStream) classed(elem interface{}) interface{} {
+	switch e := elem.(type) {
+	case polygon:
+		e.Class, e.Style = st.class(e.Class, e.Style)
+		return e
+	case circle:
+		e.Class, e.Style = st.class(e.Class, e.Style)
+		return e
+	case line:
+		e.Class, e.Style = st.class(e.Class, e.Style)
+		return e
+	case path:
+		e.Class, e.Style = st.class(e.Class, e.Style)
+		return e
+	case textElement:
+		e.Class, _ = st.class(e.Class, "")
+		return e
+	}
+	return elem
+}
+
+// class returns the class attribute and inline style of an element of the given kind with the given inline style.
+// Elements with the same style share the same class which is named by the order the style was first used in.
+func (st *svgStream) class(kind, style string) (string, string) {
+	if !st.css {
+		return "", style
+	}
+	if style == "" {
+		return kind, ""
+	}
+	name, ok := st.classes[style]
+	if !ok {
+		if st.classes == nil {
+			st.classes = make(map[string]string)
+		}
+		name = fmt.Sprintf("s%d", len(st.styles))
+		st.classes[style] = name
+		st.styles = append(st.styles, style)
+	}
+	if kind == "" {
+		return name, ""
+	}
+	return kind + " " + name, ""
+}
+
+// styleSheet returns the style sheet which defines the classes of all styles replaced so far
+func (st *svgStream) styleSheet() svgStyle {
+	rules := ""
+	for _, style := range st.styles {
+		rules += fmt.Sprintf(".%s{%s}", st.classes[style], style)
+	}
+	return svgStyle{Type: "text/css", Text: rules}
+}
+
+// start opens an svg element described by frame and draws the background color of s into it if it is set
+func (st *svgStream) start(frame svgFrame, s unitStyle) {
+	if st.err != nil {
+		return
+	}
+	attr := func(name, value string) xml.Attr { return xml.Attr{Name: xml.Name{Local: name}, Value: value} }
+	num := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	elem := xml.StartElement{Name: xml.Name{Local: "svg"}}
+	if frame.root {
+		elem.Attr = append(elem.Attr, attr("xmlns", svgNamespace))
+	} else {
+		elem.Attr = append(elem.Attr, attr("x", num(frame.x)), attr("y", num(frame.y)))
+	}
+	elem.Attr = append(elem.Attr, attr("width", num(frame.width)), attr("height", num(frame.height)))
+	if frame.root {
+		elem.Attr = append(elem.Attr, attr("viewBox", fmt.Sprintf("0 0 %s %s", num(frame.width), num(frame.height))))
+	}
+	st.err = st.enc.EncodeToken(elem)
+	st.depth++
+	if frame.title != "" {
+		st.encode(svgTitle{Text: frame.title})
+	}
+	if frame.desc != "" {
+		st.encode(svgDesc{Text: frame.desc})
+	}
+	if s.background != nil {
+		st.encode(backgroundPolygon(frame.width, frame.height, s))
+	}
+}
+
+// end closes the svg element opened by start. If st uses CSS classes, the style sheet of the whole document
+// is written at the end of the root element as the styles are only known once all elements are encoded.
+func (st *svgStream) end() {
+	if st.err != nil {
+		return
+	}
+	st.depth--
+	if st.css && st.depth == 0 {
+		st.encode(st.styleSheet())
+		if st.err != nil {
+			return
+		}
+	}
+	st.err = st.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "svg"}})
+}
+
+// element encodes the child elements of svgElem into the svg element described by frame
+func (st *svgStream) element(frame svgFrame, svgElem svgElement, s unitStyle) {
+	st.start(frame, s)
+	st.encode(svgElem.Polygons...)
+	st.end()
+}
+
+// flush writes any buffered data to the underlying writer and returns the first error encountered
+func (st *svgStream) flush() error {
+	if st.err != nil {
+		return st.err
+	}
+	if err := st.enc.Flush(); err != nil {
+		return err
+	}
+	return st.w.Flush()
+}
+
+// backgroundPolygon returns the polygon which fills the svg element of the given size with the background color of s
+func backgroundPolygon(width, height float64, s unitStyle) polygon {
+	return polygon{
+		Points: []byte(fmt.Sprintf("0,0 %f,0 %f,%f 0,%f 0,0 ", width, width, height, height)),
+		Class:  "background",
+		Style:  fmt.Sprintf("fill:%s;stroke:none", svgColor(s.background)),
+	}
+}
@@ -0,0 +1,142 @@
+package som
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestSVGStream(t *testing.T) {
+	assert := assert.New(t)
+
+	svgElem := svgElement{
+		Width:  20.5,
+		Height: 10,
+		Polygons: []interface{}{
+			polygon{Points: []byte("0,0 1,1 "), Style: "fill:red"},
+			circle{Cx: 1, Cy: 2, R: 3, Style: "fill:blue"},
+		},
+	}
+	const children = `<polygon points="0,0 1,1 " style="fill:red"></polygon><circle cx="1" cy="2" r="3" style="fill:blue"></circle>`
+	// single panel is rendered into the document root
+	var buf bytes.Buffer
+	st := newSVGStream(&buf)
+	st.document("A <b> & c", "Desc", stackedRows(svgFrame{width: svgElem.Width, height: svgElem.Height}), func(i int, frame svgFrame) {
+		st.element(frame, svgElem, unitStyle{})
+	})
+	assert.NoError(st.flush())
+	assert.Equal(`<svg xmlns="http://www.w3.org/2000/svg" width="20.5" height="10" viewBox="0 0 20.5 10">`+
+		`<title>A &lt;b&gt; &amp; c</title><desc>Desc</desc>`+children+`</svg>`, buf.String())
+	// multiple panels are stacked vertically and drawn with their backgrounds
+	buf.Reset()
+	st = newSVGStream(&buf)
+	st.document("Panels", "", stackedRows(svgFrame{width: 20.5, height: 10, title: "First"}, svgFrame{width: 5, height: 2}), func(i int, frame svgFrame) {
+		st.element(frame, svgElem, unitStyle{background: color.White})
+	})
+	assert.NoError(st.flush())
+	const bg = `<polygon points="0,0 %f,0 %f,%f 0,%f 0,0 " style="fill:rgb(255,255,255);stroke:none"></polygon>`
+	assert.Equal(`<svg xmlns="http://www.w3.org/2000/svg" width="20.5" height="12" viewBox="0 0 20.5 12"><title>Panels</title>`+
+		`<svg x="0" y="0" width="20.5" height="10"><title>First</title>`+fmt.Sprintf(bg, 20.5, 20.5, 10.0, 10.0)+children+`</svg>`+
+		`<svg x="0" y="10" width="5" height="2">`+fmt.Sprintf(bg, 5.0, 5.0, 2.0, 2.0)+children+`</svg></svg>`, buf.String())
+	// the output is a well-formed XML document
+	assert.NoError(xml.Unmarshal(buf.Bytes(), new(interface{})))
+}
+
+func TestSVGStreamWriteError(t *testing.T) {
+	assert := assert.New(t)
+
+	st := newSVGStream(failingWriter{})
+	st.encode(svgTitle{Text: "Stream"})
+	assert.EqualError(st.flush(), "write failed")
+	// write errors are returned by the renderers
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+	assert.Error(UMatrixSVG(cbook, []int{2, 2}, "rectangle", "euclidean", "Done", failingWriter{}, nil))
+	assert.Error(UMatrixSVG(mat64.NewDense(3, 1, []float64{0.0, 1.0, 2.0}), []int{3}, "rectangle", "euclidean", "Done", failingWriter{}, nil))
+}
+
+func TestSVGStreamCSS(t *testing.T) {
+	assert := assert.New(t)
+
+	svgElem := svgElement{
+		Width:  20,
+		Height: 10,
+		Polygons: []interface{}{
+			polygon{Points: []byte("0,0 1,1 "), Class: "unit", Style: "fill:red"},
+			polygon{Points: []byte("1,1 2,2 "), Class: "unit", Style: "fill:red"},
+			circle{Cx: 1, Cy: 2, R: 3, Style: "fill:blue"},
+			textElement{X: 1, Y: 2, Class: "label", Text: "A"},
+		},
+	}
+	// classes are omitted from inline styled output
+	var buf bytes.Buffer
+	st := newSVGStream(&buf)
+	st.element(svgFrame{width: 20, height: 10, root: true}, svgElem, unitStyle{})
+	assert.NoError(st.flush())
+	assert.NotContains(buf.String(), "class=")
+	// inline styles are replaced by classes shared by elements of the same style
+	buf.Reset()
+	st = newSVGStream(&buf)
+	st.css = true
+	st.document("CSS", "", stackedRows(svgFrame{width: 20, height: 10}, svgFrame{width: 20, height: 10}), func(i int, frame svgFrame) {
+		st.element(frame, svgElem, unitStyle{})
+	})
+	assert.NoError(st.flush())
+	out := buf.String()
+	assert.NotContains(out, "style=")
+	assert.Equal(4, strings.Count(out, `class="unit s0"`))
+	assert.Equal(2, strings.Count(out, `<circle cx="1" cy="2" r="3" class="s1">`))
+	assert.Equal(2, strings.Count(out, `class="label"`))
+	// the style sheet is written once at the end of the root element
+	assert.Equal(1, strings.Count(out, "<style"))
+	assert.True(strings.HasSuffix(out, "</svg><style type=\"text/css\">.s0{fill:red}.s1{fill:blue}</style></svg>"))
+	assert.NoError(xml.Unmarshal(buf.Bytes(), new(interface{})))
+	// renderers use classes if requested by the render configuration
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+	grid := &Grid{size: []int{2, 2}, ushape: "rectangle", gtype: "planar"}
+	var err error
+	grid.coords, err = GridCoords("rectangle", grid.size)
+	assert.NoError(err)
+	buf.Reset()
+	s := (&RenderConfig{CSS: true}).style()
+	s.labels = []string{"a", "", "", ""}
+	assert.NoError(umatrixSVG(cbook, grid, "euclidean", "CSS", &buf, s))
+	out = buf.String()
+	assert.NotContains(out, "style=\"")
+	assert.Equal(4, strings.Count(out, `class="unit s`))
+	assert.Contains(out, `class="label"`)
+	assert.Contains(out, "stroke:black;stroke-width:1}")
+}
+
+func TestSVGStreamLargeMap(t *testing.T) {
+	assert := assert.New(t)
+
+	// 300x300 map would need two distance matrices of 90000x90000 values if all unit distances were computed
+	dims := []int{300, 300}
+	units := dims[0] * dims[1]
+	cbook := mat64.NewDense(units, 1, nil)
+	for i := 0; i < units; i++ {
+		cbook.Set(i, 0, float64(i%7))
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	assert.NoError(UMatrixSVG(cbook, dims, "hexagon", "euclidean", "Large", ioutil.Discard, nil))
+	runtime.ReadMemStats(&after)
+	// the memory allocated while rendering grows linearly with the number of units
+	assert.True(after.TotalAlloc-before.TotalAlloc < 1<<29, "allocated: %d", after.TotalAlloc-before.TotalAlloc)
+}
@@ -0,0 +1,131 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// trajectoryStyle is the style of the trajectory segments
+const trajectoryStyle = "stroke:rgb(255,0,0);stroke-width:2"
+
+// Trajectory returns the ordered path of Best Match Units of the samples stored in data rows found using
+// the supplied distance metric. Consecutive samples mapped to the same unit are merged into a single path step.
+// It returns error if either data or codebook are nil or if their dimensions are mismatched.
+func Trajectory(codebook, data *mat64.Dense, metric string) ([]int, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	units, _, err := MapRows(codebook, data, metric)
+	if err != nil {
+		return nil, err
+	}
+	path := make([]int, 0, len(units))
+	for _, unit := range units {
+		if len(path) == 0 || path[len(path)-1] != unit {
+			path = append(path, unit)
+		}
+	}
+
+	return path, nil
+}
+
+// TrajectorySVG creates an SVG representation of the U-Matrix of the given codebook overlaid with the path
+// of Best Match Units of the ordered samples stored in data rows drawn as a polyline with arrowheads.
+// It accepts the same parameters as UMatrixSVG and data - the ordered samples whose trajectory is displayed.
+// It fails with error if the trajectory could not be computed or if the grid coordinates could not be computed.
+func TrajectorySVG(codebook, data *mat64.Dense, dims []int, uShape, metric, title string, writer io.Writer) error {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	path, err := Trajectory(codebook, data, metric)
+	if err != nil {
+		return err
+	}
+
+	return umatrixSVG(codebook, grid, metric, title, writer, unitStyle{trajectory: path})
+}
+
+// Trajectory generates SOM u-matrix in a given format and writes the output to w in the same way as UMatrix,
+// but the u-matrix is overlaid with the path of Best Match Units of the ordered samples stored in data rows.
+// Currently only "svg" and "png" formats are supported. It fails with error if the trajectory could not be computed.
+func (m Map) Trajectory(w io.Writer, data *mat64.Dense, format, title string) error {
+	switch format {
+	case "svg", "png":
+		path, err := Trajectory(m.codebook, data, m.metric)
+		if err != nil {
+			return err
+		}
+		s := m.render.style()
+		s.trajectory = path
+		if format == "png" {
+			return umatrixPNG(m.codebook, m.grid, m.metric, w, s)
+		}
+		return umatrixSVG(m.codebook, m.grid, m.metric, title, w, s)
+	}
+
+	return fmt.Errorf("invalid format %s", format)
+}
+
+// trajectorySegments returns the trajectory segments whose both end units have their centers in centers
+func (s unitStyle) trajectorySegments(centers map[int][2]float64) [][2][2]float64 {
+	var segments [][2][2]float64
+	for i := 1; i < len(s.trajectory); i++ {
+		a, okA := centers[s.trajectory[i-1]]
+		b, okB := centers[s.trajectory[i]]
+		if okA && okB {
+			segments = append(segments, [2][2]float64{a, b})
+		}
+	}
+	return segments
+}
+
+// trajectorySVG returns SVG lines and arrowheads of the trajectory segments between the unit centers
+func (s unitStyle) trajectorySVG(centers map[int][2]float64, size float64) []interface{} {
+	var elems []interface{}
+	for _, seg := range s.trajectorySegments(centers) {
+		a, b := seg[0], seg[1]
+		elems = append(elems, line{X1: a[0], Y1: a[1], X2: b[0], Y2: b[1], Class: "trajectory", Style: trajectoryStyle})
+		if head := arrowHead(a, b, size); head != nil {
+			polygonCoords := ""
+			for _, p := range head {
+				polygonCoords += fmt.Sprintf("%f,%f ", p[0], p[1])
+			}
+			elems = append(elems, polygon{
+				Points: []byte(polygonCoords),
+				Class:  "trajectory",
+				Style:  "fill:rgb(255,0,0);stroke:none",
+			})
+		}
+	}
+	return elems
+}
+
+// arrowHead returns the closed triangle of the arrowhead pointing from a to b with its tip in b.
+// The arrowhead size is derived from the unit size. It returns nil if a and b are the same points.
+func arrowHead(a, b [2]float64, size float64) [][2]float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return nil
+	}
+	dx, dy = dx/d, dy/d
+	length, width := 0.3*size, 0.12*size
+	baseX, baseY := b[0]-length*dx, b[1]-length*dy
+
+	return [][2]float64{
+		b,
+		{baseX - width*dy, baseY + width*dx},
+		{baseX + width*dy, baseY - width*dx},
+		b,
+	}
+}
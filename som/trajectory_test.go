@@ -0,0 +1,71 @@
+package som
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrajectory(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 10.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 0.1, 5.0, 9.0, 4.0})
+	path, err := Trajectory(cbook, data, "euclidean")
+	assert.NoError(err)
+	// consecutive samples mapped to the same unit are merged
+	assert.Equal([]int{0, 1, 2, 1}, path)
+	// nil parameters
+	path, err = Trajectory(nil, data, "euclidean")
+	assert.Nil(path)
+	assert.Error(err)
+	path, err = Trajectory(cbook, nil, "euclidean")
+	assert.Nil(path)
+	assert.Error(err)
+}
+
+func TestTrajectorySVG(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 5.0, 10.0})
+	data := mat64.NewDense(3, 1, []float64{0.0, 0.1, 9.0})
+	var buf bytes.Buffer
+	err := TrajectorySVG(cbook, data, []int{1, 3}, "rectangle", "euclidean", "Path", &buf)
+	assert.NoError(err)
+	svg := buf.String()
+	// a single segment from the first to the last unit with its arrowhead
+	assert.Equal(1, strings.Count(svg, "<line "))
+	assert.True(strings.Contains(svg, `<line x1="35" y1="35" x2="135" y2="35" style="stroke:rgb(255,0,0);stroke-width:2"></line>`))
+	assert.Equal(4, strings.Count(svg, "<polygon "))
+	assert.True(strings.Contains(svg, `<polygon points="135.000000,35.000000 120.000000,41.000000 120.000000,29.000000 135.000000,35.000000 "`))
+	// invalid parameters
+	assert.Error(TrajectorySVG(cbook, data, []int{1, 3}, "foobar", "euclidean", "Path", &buf))
+	assert.Error(TrajectorySVG(cbook, mat64.NewDense(1, 2, nil), []int{1, 3}, "rectangle", "euclidean", "Path", &buf))
+	// map trajectory
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	var mBuf bytes.Buffer
+	assert.NoError(m.Trajectory(&mBuf, data, "svg", "Path"))
+	assert.Equal(svg, mBuf.String())
+	var pngBuf bytes.Buffer
+	assert.NoError(m.Trajectory(&pngBuf, data, "png", "Path"))
+	assert.True(pngBuf.Len() > 0)
+	assert.Error(m.Trajectory(&mBuf, data, "bmp", "Path"))
+	assert.Error(m.Trajectory(&mBuf, mat64.NewDense(1, 2, nil), "svg", "Path"))
+}
+
+func TestArrowHead(t *testing.T) {
+	assert := assert.New(t)
+
+	head := arrowHead([2]float64{0.0, 0.0}, [2]float64{0.0, 10.0}, 10.0)
+	assert.Len(head, 4)
+	assert.Equal(head[0], head[3])
+	assert.InDeltaSlice([]float64{0.0, 10.0}, head[0][:], 1e-9)
+	assert.InDeltaSlice([]float64{-1.2, 7.0}, head[1][:], 1e-9)
+	assert.InDeltaSlice([]float64{1.2, 7.0}, head[2][:], 1e-9)
+	assert.Nil(arrowHead([2]float64{1.0, 1.0}, [2]float64{1.0, 1.0}, 10.0))
+}
@@ -0,0 +1,114 @@
+package som
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Trustworthiness measures how well the k nearest neighbours of every vector stored in embedding rows
+// match the k nearest neighbours of the corresponding vector stored in data rows. It penalizes samples
+// which are close in the embedding but not in the data space. Distances between data rows are computed
+// using the supplied metric, distances between embedding rows are euclidean. Trustworthiness is 1 when the
+// neighbourhoods are preserved perfectly and it decreases with every intruding neighbour.
+// It returns error if either data or embedding are nil, if they have different number of rows
+// or if k is not a positive integer smaller than half of the number of data rows.
+func Trustworthiness(data, embedding *mat64.Dense, k int, metric string) (float64, error) {
+	dataDist, embDist, err := neighbDistMxs(data, embedding, k, metric)
+	if err != nil {
+		return -1.0, err
+	}
+	return neighbPreservation(dataDist, embDist, k), nil
+}
+
+// Continuity measures how well the k nearest neighbours of every vector stored in data rows are preserved
+// in the embedding. It penalizes samples which are close in the data space but not in the embedding.
+// Continuity is 1 when the neighbourhoods are preserved perfectly. It fails in the same way as Trustworthiness.
+func Continuity(data, embedding *mat64.Dense, k int, metric string) (float64, error) {
+	dataDist, embDist, err := neighbDistMxs(data, embedding, k, metric)
+	if err != nil {
+		return -1.0, err
+	}
+	return neighbPreservation(embDist, dataDist, k), nil
+}
+
+// Trustworthiness computes trustworthiness of the map projection of data samples placed at their BMU coordinates.
+// It returns error if data is nil, if its dimension is different from the map codebook dimension or if k is invalid.
+func (m Map) Trustworthiness(data *mat64.Dense, k int) (float64, error) {
+	proj, err := m.Project(data, 1)
+	if err != nil {
+		return -1.0, err
+	}
+	return Trustworthiness(data, proj, k, m.metric)
+}
+
+// Continuity computes continuity of the map projection of data samples placed at their BMU coordinates.
+// It returns error if data is nil, if its dimension is different from the map codebook dimension or if k is invalid.
+func (m Map) Continuity(data *mat64.Dense, k int) (float64, error) {
+	proj, err := m.Project(data, 1)
+	if err != nil {
+		return -1.0, err
+	}
+	return Continuity(data, proj, k, m.metric)
+}
+
+// neighbDistMxs validates neighbourhood preservation parameters and returns distance matrices of data and embedding rows
+func neighbDistMxs(data, embedding *mat64.Dense, k int, metric string) (*mat64.Dense, *mat64.Dense, error) {
+	if data == nil {
+		return nil, nil, fmt.Errorf("invalid data supplied: %v", data)
+	}
+	if embedding == nil {
+		return nil, nil, fmt.Errorf("invalid embedding supplied: %v", embedding)
+	}
+	rows, _ := data.Dims()
+	if embRows, _ := embedding.Dims(); embRows != rows {
+		return nil, nil, fmt.Errorf("invalid number of embedding rows: %d, expected: %d", embRows, rows)
+	}
+	if k <= 0 || 2*k >= rows {
+		return nil, nil, fmt.Errorf("invalid number of neighbours: %d", k)
+	}
+	dataDist, err := DistanceMx(metric, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	embDist, err := DistanceMx("euclidean", embedding)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataDist, embDist, nil
+}
+
+// neighbPreservation penalizes every sample which is among k nearest neighbours in distance matrix b
+// but not in distance matrix a by its rank in a and returns the normalized score
+func neighbPreservation(a, b *mat64.Dense, k int) float64 {
+	n, _ := a.Dims()
+	var penalty float64
+	for i := 0; i < n; i++ {
+		aRanks := neighbRanks(a.RawRowView(i), i)
+		bRanks := neighbRanks(b.RawRowView(i), i)
+		for j, rank := range bRanks {
+			if rank <= k && aRanks[j] > k {
+				penalty += float64(aRanks[j] - k)
+			}
+		}
+	}
+	return 1.0 - 2.0/float64(n*k*(2*n-3*k-1))*penalty
+}
+
+// neighbRanks returns ranks of all samples by their distances dists to sample i: the closest sample has rank 1.
+// Ties are resolved in favour of the sample with smaller index. Sample i has rank 0.
+func neighbRanks(dists []float64, i int) []int {
+	idx := make([]int, 0, len(dists)-1)
+	for j := range dists {
+		if j != i {
+			idx = append(idx, j)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return dists[idx[a]] < dists[idx[b]] })
+	ranks := make([]int, len(dists))
+	for r, j := range idx {
+		ranks[j] = r + 1
+	}
+	return ranks
+}
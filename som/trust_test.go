@@ -0,0 +1,71 @@
+package som
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustworthinessContinuity(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(6, 1, []float64{0.0, 1.0, 2.0, 3.0, 4.0, 5.0})
+	// scaled embedding preserves all neighbourhoods
+	emb := mat64.NewDense(6, 1, []float64{0.0, 2.0, 4.0, 6.0, 8.0, 10.0})
+	tw, err := Trustworthiness(data, emb, 2, "euclidean")
+	assert.NoError(err)
+	assert.InDelta(1.0, tw, 1e-9)
+	c, err := Continuity(data, emb, 2, "euclidean")
+	assert.NoError(err)
+	assert.InDelta(1.0, c, 1e-9)
+	// shuffled embedding breaks the neighbourhoods
+	emb = mat64.NewDense(6, 1, []float64{0.0, 4.0, 1.0, 5.0, 2.0, 3.0})
+	tw, err = Trustworthiness(data, emb, 2, "euclidean")
+	assert.NoError(err)
+	assert.True(tw < 1.0)
+	c, err = Continuity(data, emb, 2, "euclidean")
+	assert.NoError(err)
+	assert.True(c < 1.0)
+	// invalid parameters
+	for _, k := range []int{0, 3} {
+		tw, err = Trustworthiness(data, emb, k, "euclidean")
+		assert.EqualError(err, fmt.Sprintf("invalid number of neighbours: %d", k))
+		assert.Equal(-1.0, tw)
+	}
+	c, err = Continuity(data, mat64.NewDense(5, 1, nil), 2, "euclidean")
+	assert.EqualError(err, "invalid number of embedding rows: 5, expected: 6")
+	assert.Equal(-1.0, c)
+	tw, err = Trustworthiness(nil, emb, 2, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, tw)
+	c, err = Continuity(data, nil, 2, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, c)
+}
+
+func TestMapTrustworthinessContinuity(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 6}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(6, 1, []float64{0.0, 1.0, 2.0, 3.0, 4.0, 5.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	data := mat64.NewDense(6, 1, []float64{0.1, 1.1, 2.1, 3.1, 4.1, 5.1})
+	tw, err := m.Trustworthiness(data, 2)
+	assert.NoError(err)
+	assert.InDelta(1.0, tw, 1e-9)
+	c, err := m.Continuity(data, 2)
+	assert.NoError(err)
+	assert.InDelta(1.0, c, 1e-9)
+	tw, err = m.Trustworthiness(mat64.NewDense(6, 2, nil), 2)
+	assert.Error(err)
+	assert.Equal(-1.0, tw)
+	c, err = m.Continuity(nil, 2)
+	assert.Error(err)
+	assert.Equal(-1.0, c)
+}
@@ -0,0 +1,72 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// NewTwoPhaseConfig returns the classic two-phase training configuration for a grid of the given size.
+// The ordering phase runs for a fifth of iters iterations with radius of half of the largest grid
+// dimension and learning rate 0.5. The tuning phase runs for the rest of iters iterations with
+// a quarter of the ordering radius, but at least MinRadius, and learning rate 0.05.
+// Both phases share the training configuration tc.
+// It returns error if tc is nil, size is empty or if iters is smaller than 2.
+func NewTwoPhaseConfig(tc *TrainConfig, size []int, iters int) (*TwoPhaseConfig, error) {
+	// training configuration must be supplied
+	if tc == nil {
+		return nil, fmt.Errorf("invalid training configuration: %v", tc)
+	}
+	if len(size) == 0 {
+		return nil, fmt.Errorf("invalid SOM grid dimensions supplied: %v", size)
+	}
+	// both phases need at least one iteration
+	if iters < 2 {
+		return nil, fmt.Errorf("invalid number of iterations: %d", iters)
+	}
+	maxDim := 0
+	for _, d := range size {
+		if d > maxDim {
+			maxDim = d
+		}
+	}
+	radius := math.Max(float64(maxDim)/2.0, MinRadius)
+	orderIters := iters / 5
+	if orderIters == 0 {
+		orderIters = 1
+	}
+
+	return &TwoPhaseConfig{
+		Train: tc,
+		Ordering: PhaseConfig{
+			Radius: radius,
+			LRate:  0.5,
+			Iters:  orderIters,
+		},
+		Tuning: PhaseConfig{
+			Radius: math.Max(radius/4.0, MinRadius),
+			LRate:  0.05,
+			Iters:  iters - orderIters,
+		},
+	}, nil
+}
+
+// TrainTwoPhase runs two-phase SOM training for a given data set. The map is first roughly ordered
+// by the ordering phase with a large radius and learning rate and then fine-tuned by the usually
+// much longer tuning phase with a small radius and learning rate.
+// It returns error if the supplied configuration is invalid or if any of the phases fails.
+func (m *Map) TrainTwoPhase(c *TwoPhaseConfig, data *mat64.Dense) error {
+	if err := validateTwoPhaseConfig(c); err != nil {
+		return err
+	}
+	for _, phase := range []PhaseConfig{c.Ordering, c.Tuning} {
+		tc := *c.Train
+		tc.Radius, tc.LRate = phase.Radius, phase.LRate
+		if err := m.Train(&tc, data, phase.Iters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
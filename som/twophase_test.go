@@ -0,0 +1,62 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTwoPhaseConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	tc := makeDefaultTrainConfig()
+	c, err := NewTwoPhaseConfig(tc, []int{10, 20}, 1000)
+	assert.NoError(err)
+	assert.Equal(PhaseConfig{Radius: 10.0, LRate: 0.5, Iters: 200}, c.Ordering)
+	assert.Equal(PhaseConfig{Radius: 2.5, LRate: 0.05, Iters: 800}, c.Tuning)
+	assert.NoError(validateTwoPhaseConfig(c))
+	// small grids never go below MinRadius
+	c, err = NewTwoPhaseConfig(tc, []int{1, 2}, 2)
+	assert.NoError(err)
+	assert.Equal(PhaseConfig{Radius: MinRadius, LRate: 0.5, Iters: 1}, c.Ordering)
+	assert.Equal(PhaseConfig{Radius: MinRadius, LRate: 0.05, Iters: 1}, c.Tuning)
+	// invalid parameters
+	c, err = NewTwoPhaseConfig(nil, []int{10, 20}, 1000)
+	assert.Nil(c)
+	assert.Error(err)
+	c, err = NewTwoPhaseConfig(tc, []int{}, 1000)
+	assert.Nil(c)
+	assert.Error(err)
+	c, err = NewTwoPhaseConfig(tc, []int{10, 20}, 1)
+	assert.Nil(c)
+	assert.Error(err)
+}
+
+func TestTrainTwoPhase(t *testing.T) {
+	assert := assert.New(t)
+
+	mapCfg := makeDefaultMapCfg()
+	mapCfg.Cb.Dim = 4
+	m, err := NewMap(mapCfg, dataMx)
+	assert.NotNil(m)
+	assert.NoError(err)
+	tc := makeDefaultTrainConfig()
+	c, err := NewTwoPhaseConfig(tc, mapCfg.Grid.Size, 100)
+	assert.NoError(err)
+	qErr0, err := m.QuantError(dataMx)
+	assert.NoError(err)
+	assert.NoError(m.TrainTwoPhase(c, dataMx))
+	qErr, err := m.QuantError(dataMx)
+	assert.NoError(err)
+	assert.True(qErr < qErr0)
+	// shared training configuration is not modified
+	assert.Equal(makeDefaultTrainConfig().Radius, tc.Radius)
+	assert.Equal(makeDefaultTrainConfig().LRate, tc.LRate)
+	// invalid phase
+	c.Tuning.Iters = 0
+	assert.EqualError(m.TrainTwoPhase(c, dataMx), "invalid tuning phase number of iterations: 0")
+	c.Ordering.Radius = 0.0
+	assert.EqualError(m.TrainTwoPhase(c, dataMx), "invalid ordering phase radius: 0.000000")
+	c.Train = nil
+	assert.Error(m.TrainTwoPhase(c, dataMx))
+}
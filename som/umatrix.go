@@ -0,0 +1,65 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// UMatrix computes the U-Matrix of the given codebook whose units are laid out on a planar grid of the given
+// dimensions and unit shape, i.e. the average distance of every codebook vector to the codebook vectors of its
+// neighbouring grid units computed using the supplied metric. The returned matrix has dims[0] rows: the value of the
+// i-th grid unit is stored in row i % dims[0] and column i / dims[0], which for 2D grids matches the grid layout.
+// 1D grids give a single column matrix and 3D grids are stored z-layer after z-layer.
+// It fails with error if the grid coordinates could not be computed or if the codebook does not match the grid.
+func UMatrix(codebook *mat64.Dense, dims []int, uShape, metric string) (*mat64.Dense, error) {
+	coords, err := GridCoords(uShape, dims)
+	if err != nil {
+		return nil, err
+	}
+	grid := &Grid{
+		size:   dims,
+		ushape: uShape,
+		gtype:  "planar",
+		coords: coords,
+	}
+	values, err := unitUMatrix(codebook, grid, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return unitMatrix(values, dims[0]), nil
+}
+
+// UMatrixValues returns the U-Matrix values of the map units: i-th slice item holds the average distance
+// of the i-th map unit codebook vector to the codebook vectors of its neighbouring units. Unlike UMatrix
+// it works with any map grid including spherical and graph grids.
+func (m Map) UMatrixValues() ([]float64, error) {
+	return unitUMatrix(m.codebook, m.grid, m.metric)
+}
+
+// unitUMatrix computes U-Matrix values of the grid units after checking the codebook matches the grid
+func unitUMatrix(codebook *mat64.Dense, grid *Grid, metric string) ([]float64, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if rows, _ := codebook.Dims(); rows != grid.Units() {
+		return nil, fmt.Errorf("invalid number of codebook vectors: %d, expected: %d", rows, grid.Units())
+	}
+	values, _, _, err := umatrixValues(codebook, grid, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// unitMatrix lays out the unit values stored column by column into a matrix with the given number of rows
+func unitMatrix(values []float64, rows int) *mat64.Dense {
+	cols := len(values) / rows
+	mx := mat64.NewDense(rows, cols, nil)
+	for unit, v := range values {
+		mx.Set(unit%rows, unit/rows, v)
+	}
+	return mx
+}
@@ -0,0 +1,59 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+	umatrix, err := UMatrix(cbook, []int{2, 2}, "rectangle", "euclidean")
+	assert.NoError(err)
+	rows, cols := umatrix.Dims()
+	assert.Equal(2, rows)
+	assert.Equal(2, cols)
+	// units are laid out the same way as on the grid
+	assert.InDeltaSlice([]float64{2.0, 4.0 / 3.0}, umatrix.RawRowView(0), 1e-9)
+	assert.InDeltaSlice([]float64{4.0 / 3.0, 2.0}, umatrix.RawRowView(1), 1e-9)
+	// 1D grids give a single column
+	umatrix, err = UMatrix(mat64.NewDense(3, 1, []float64{0.0, 1.0, 3.0}), []int{3}, "rectangle", "euclidean")
+	assert.NoError(err)
+	rows, cols = umatrix.Dims()
+	assert.Equal(3, rows)
+	assert.Equal(1, cols)
+	assert.InDeltaSlice([]float64{1.0, 1.5, 2.0}, mat64.Col(nil, 0, umatrix), 1e-9)
+	// invalid parameters
+	umatrix, err = UMatrix(nil, []int{2, 2}, "rectangle", "euclidean")
+	assert.Nil(umatrix)
+	assert.Error(err)
+	umatrix, err = UMatrix(cbook, []int{3, 2}, "rectangle", "euclidean")
+	assert.Nil(umatrix)
+	assert.Error(err)
+	umatrix, err = UMatrix(cbook, []int{2, 2}, "foobar", "euclidean")
+	assert.Nil(umatrix)
+	assert.Error(err)
+}
+
+func TestMapUMatrixValues(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{2, 2}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0}), grid: grid, metric: "euclidean"}
+	values, err := m.UMatrixValues()
+	assert.NoError(err)
+	assert.InDeltaSlice([]float64{2.0, 4.0 / 3.0, 4.0 / 3.0, 2.0}, values, 1e-9)
+	// values match the rendered U-Matrix
+	expected, _, _, err := umatrixValues(m.codebook, grid, "euclidean")
+	assert.NoError(err)
+	assert.Equal(expected, values)
+	// codebook does not match the grid
+	m.codebook = mat64.NewDense(3, 1, nil)
+	values, err = m.UMatrixValues()
+	assert.Nil(values)
+	assert.Error(err)
+}
@@ -0,0 +1,154 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// DaviesBouldin computes Davies-Bouldin index of the data partition induced by clustering of codebook vectors.
+// Every data sample stored in data rows belongs to the cluster of its BMU: unitClusters holds cluster IDs
+// of codebook vectors stored in codebook rows. Units with negative cluster IDs are treated as noise and
+// their data samples are ignored. Distances are computed using the supplied metric. Lower values mean
+// compact and well separated clusters. It returns error if either codebook or data are nil, if their dimensions
+// are mismatched, if the number of unit clusters is different from the number of codebook vectors
+// or if the data samples don't form at least two clusters. When the error is returned, the index is set to -1.0
+func DaviesBouldin(codebook, data *mat64.Dense, unitClusters []int, metric string) (float64, error) {
+	clusters, err := dataClusters(codebook, data, unitClusters, metric)
+	if err != nil {
+		return -1.0, err
+	}
+	_, cols := data.Dims()
+	// cluster centroids and average distances of cluster samples to their centroids
+	ids := []int{}
+	centroids := make(map[int][]float64)
+	scatter := make(map[int]float64)
+	for id, rows := range clusters {
+		ids = append(ids, id)
+		centroid := make([]float64, cols)
+		for _, row := range rows {
+			for j, val := range data.RawRowView(row) {
+				centroid[j] += val / float64(len(rows))
+			}
+		}
+		centroids[id] = centroid
+		for _, row := range rows {
+			d, err := Distance(metric, data.RawRowView(row), centroid)
+			if err != nil {
+				return -1.0, err
+			}
+			scatter[id] += d / float64(len(rows))
+		}
+	}
+	var db float64
+	for _, i := range ids {
+		worst := 0.0
+		for _, j := range ids {
+			if i == j {
+				continue
+			}
+			d, err := Distance(metric, centroids[i], centroids[j])
+			if err != nil {
+				return -1.0, err
+			}
+			worst = math.Max(worst, (scatter[i]+scatter[j])/d)
+		}
+		db += worst
+	}
+
+	return db / float64(len(ids)), nil
+}
+
+// Silhouette computes the average silhouette coefficient of the data partition induced by clustering of
+// codebook vectors in the same way as DaviesBouldin does. Silhouette of a data sample compares its average
+// distance to the other samples of its cluster with the average distance to the samples of the closest other cluster.
+// Samples of single-sample clusters have silhouette 0. Values close to 1 mean compact and well separated clusters.
+// It fails in the same way as DaviesBouldin.
+func Silhouette(codebook, data *mat64.Dense, unitClusters []int, metric string) (float64, error) {
+	clusters, err := dataClusters(codebook, data, unitClusters, metric)
+	if err != nil {
+		return -1.0, err
+	}
+	var total float64
+	var count int
+	for id, rows := range clusters {
+		for _, row := range rows {
+			count++
+			if len(rows) == 1 {
+				continue
+			}
+			a, err := meanDist(metric, data, row, rows)
+			if err != nil {
+				return -1.0, err
+			}
+			// len(rows)-1 accounts for zero distance of the sample to itself
+			a *= float64(len(rows)) / float64(len(rows)-1)
+			b := math.Inf(1)
+			for other, otherRows := range clusters {
+				if other == id {
+					continue
+				}
+				d, err := meanDist(metric, data, row, otherRows)
+				if err != nil {
+					return -1.0, err
+				}
+				b = math.Min(b, d)
+			}
+			if s := math.Max(a, b); s > 0 {
+				total += (b - a) / s
+			}
+		}
+	}
+
+	return total / float64(count), nil
+}
+
+// DaviesBouldin computes Davies-Bouldin index of the data partition induced by clustering of the map units.
+// It fails in the same way as DaviesBouldin function.
+func (m Map) DaviesBouldin(data *mat64.Dense, unitClusters []int) (float64, error) {
+	return DaviesBouldin(m.codebook, data, unitClusters, m.metric)
+}
+
+// Silhouette computes silhouette coefficient of the data partition induced by clustering of the map units.
+// It fails in the same way as Silhouette function.
+func (m Map) Silhouette(data *mat64.Dense, unitClusters []int) (float64, error) {
+	return Silhouette(m.codebook, data, unitClusters, m.metric)
+}
+
+// dataClusters maps cluster IDs to data rows which belong to the cluster of their BMU
+func dataClusters(codebook, data *mat64.Dense, unitClusters []int, metric string) (map[int][]int, error) {
+	if codebook == nil {
+		return nil, fmt.Errorf("invalid codebook supplied: %v", codebook)
+	}
+	if cRows, _ := codebook.Dims(); len(unitClusters) != cRows {
+		return nil, fmt.Errorf("invalid number of unit clusters: %d, expected: %d", len(unitClusters), cRows)
+	}
+	units, _, err := MapRows(codebook, data, metric)
+	if err != nil {
+		return nil, err
+	}
+	clusters := make(map[int][]int)
+	for row, unit := range units {
+		if id := unitClusters[unit]; id >= 0 {
+			clusters[id] = append(clusters[id], row)
+		}
+	}
+	if len(clusters) < 2 {
+		return nil, fmt.Errorf("insufficient number of data clusters: %d", len(clusters))
+	}
+	return clusters, nil
+}
+
+// meanDist returns the average distance of data row to the data rows
+func meanDist(metric string, data *mat64.Dense, row int, rows []int) (float64, error) {
+	var total float64
+	for _, r := range rows {
+		d, err := Distance(metric, data.RawRowView(row), data.RawRowView(r))
+		if err != nil {
+			return 0.0, err
+		}
+		total += d
+	}
+	return total / float64(len(rows)), nil
+}
@@ -0,0 +1,50 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaviesBouldinSilhouette(t *testing.T) {
+	assert := assert.New(t)
+
+	cbook := mat64.NewDense(3, 1, []float64{0.0, 10.0, 100.0})
+	data := mat64.NewDense(5, 1, []float64{0.0, 2.0, 10.0, 12.0, 100.0})
+	// the samples of the last unit are noise
+	unitClusters := []int{0, 1, -1}
+	db, err := DaviesBouldin(cbook, data, unitClusters, "euclidean")
+	assert.NoError(err)
+	assert.InDelta(0.2, db, 1e-9)
+	s, err := Silhouette(cbook, data, unitClusters, "euclidean")
+	assert.NoError(err)
+	assert.InDelta((18.0/11.0+14.0/9.0)/4.0, s, 1e-9)
+	// map indices
+	grid, err := NewGrid(&GridConfig{Size: []int{3}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{codebook: cbook, grid: grid, metric: "euclidean"}
+	db, err = m.DaviesBouldin(data, unitClusters)
+	assert.NoError(err)
+	assert.InDelta(0.2, db, 1e-9)
+	s, err = m.Silhouette(data, unitClusters)
+	assert.NoError(err)
+	assert.InDelta((18.0/11.0+14.0/9.0)/4.0, s, 1e-9)
+	// single cluster
+	db, err = DaviesBouldin(cbook, data, []int{0, 0, 0}, "euclidean")
+	assert.EqualError(err, "insufficient number of data clusters: 1")
+	assert.Equal(-1.0, db)
+	s, err = Silhouette(cbook, data, []int{0, 0, 0}, "euclidean")
+	assert.EqualError(err, "insufficient number of data clusters: 1")
+	assert.Equal(-1.0, s)
+	// invalid parameters
+	db, err = DaviesBouldin(cbook, data, []int{0, 1}, "euclidean")
+	assert.EqualError(err, "invalid number of unit clusters: 2, expected: 3")
+	assert.Equal(-1.0, db)
+	db, err = DaviesBouldin(nil, data, unitClusters, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, db)
+	s, err = Silhouette(cbook, nil, unitClusters, "euclidean")
+	assert.Error(err)
+	assert.Equal(-1.0, s)
+}
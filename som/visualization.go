@@ -0,0 +1,141 @@
+package som
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Visualization holds the data needed to render SOM in web frontends such as D3 or Plotly dashboards
+type Visualization struct {
+	// Grid describes the map grid
+	Grid VisualizationGrid `json:"grid"`
+	// Min and Max are the minimum and maximum U-Matrix value
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	// Units holds the visualization data of map units ordered in the same way as the codebook vectors
+	Units []VisualizationUnit `json:"units"`
+}
+
+// VisualizationGrid describes the map grid and the size of its rendered layers
+type VisualizationGrid struct {
+	// Dims holds the grid dimensions
+	Dims []int `json:"dims"`
+	// Shape is the shape of grid units
+	Shape string `json:"shape"`
+	// Type is the grid type: planar, sphere or graph
+	Type string `json:"type"`
+	// Width and Height are the size of the rendered grid layer in pixels, they are 0 if units have no polygons
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+}
+
+// VisualizationUnit holds the visualization data of a map unit
+type VisualizationUnit struct {
+	// Unit is the unit index
+	Unit int `json:"unit"`
+	// Coords holds the unit grid coordinates
+	Coords []float64 `json:"coords"`
+	// Polygon holds the closed polygon of the unit in pixels in the same way as it is rendered in SVG output.
+	// Planar units of 3D grids are placed in their z-layer. Sphere and graph units have no polygon
+	Polygon [][2]float64 `json:"polygon,omitempty"`
+	// UMatrix is the unit U-Matrix value
+	UMatrix float64 `json:"umatrix"`
+	// Hits is the number of data rows mapped to the unit, it is 0 if no data are supplied
+	Hits int `json:"hits"`
+	// Label is the unit label, it is omitted if it is empty
+	Label string `json:"label,omitempty"`
+}
+
+// Visualization returns visualization data of SOM: unit coordinates and polygons, U-Matrix values and
+// hit counts of data rows and labels. The unit size and U-Matrix smoothing are given by the map render
+// configuration. data and labels are optional: if data is nil, the hit counts are 0 and i-th label is
+// the label of i-th unit. It fails with error if there are more labels than units or if the U-Matrix
+// or the hits could not be computed.
+func (m Map) Visualization(data *mat64.Dense, labels []string) (*Visualization, error) {
+	units := m.grid.Units()
+	if len(labels) > units {
+		return nil, fmt.Errorf("invalid number of unit labels: %d, expected at most: %d", len(labels), units)
+	}
+	s := m.render.style()
+	umatrix, _, _, err := s.umatrixValues(m.codebook, m.grid, m.metric)
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]int, units)
+	if data != nil {
+		if hits, err = m.HitMap(data); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &Visualization{
+		Grid: VisualizationGrid{
+			Dims:  m.grid.size,
+			Shape: m.grid.ushape,
+			Type:  m.grid.gtype,
+		},
+		Min:   floats.Min(umatrix),
+		Max:   floats.Max(umatrix),
+		Units: make([]VisualizationUnit, units),
+	}
+	polygons := m.visualizationPolygons(&v.Grid, s)
+	for row := 0; row < units; row++ {
+		v.Units[row] = VisualizationUnit{
+			Unit:    row,
+			Coords:  mat64.Row(nil, row, m.grid.coords),
+			UMatrix: umatrix[row],
+			Hits:    hits[row],
+		}
+		if polygons != nil {
+			v.Units[row].Polygon = polygons[row]
+		}
+		if row < len(labels) {
+			v.Units[row].Label = labels[row]
+		}
+	}
+
+	return v, nil
+}
+
+// VisualizationJSON writes SOM visualization data returned by Visualization as JSON document to w.
+// It fails with error if the visualization data could not be computed or if the write to w fails.
+func (m Map) VisualizationJSON(w io.Writer, data *mat64.Dense, labels []string) error {
+	v, err := m.Visualization(data, labels)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(v)
+}
+
+// visualizationPolygons returns the polygons of planar grid units rendered using style s and sets the size
+// of the rendered grid layer to grid. It returns nil for sphere and graph grids.
+func (m Map) visualizationPolygons(grid *VisualizationGrid, s unitStyle) [][][2]float64 {
+	if m.grid.gtype == "sphere" || m.grid.gtype == "graph" {
+		return nil
+	}
+	mul, off := s.cellSize(), s.margin()
+	units := m.grid.Units()
+	polygons := make([][][2]float64, units)
+	// 1D grid units are a ribbon of adjacent squares
+	if len(m.grid.size) == 1 {
+		grid.Width, grid.Height = float64(units)*mul+2*off, mul+2*off
+		for row := 0; row < units; row++ {
+			x, y := mul*m.grid.coords.At(row, 0)+off, off
+			polygons[row] = [][2]float64{{x, y}, {x + mul, y}, {x + mul, y + mul}, {x, y + mul}, {x, y}}
+		}
+		return polygons
+	}
+	var xOff, yOff float64
+	grid.Width, grid.Height, xOff, yOff = layerBounds(m.grid.size, m.grid.ushape, mul, off)
+	for row := 0; row < units; row++ {
+		x := mul*m.grid.coords.At(row, 0) + xOff
+		y := mul*m.grid.coords.At(row, 1) + yOff
+		polygons[row] = unitPolygon(m.grid.size, m.grid.ushape, row, x, y, mul)
+	}
+	return polygons
+}
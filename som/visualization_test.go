@@ -0,0 +1,81 @@
+package som
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapVisualization(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	units := m.grid.Units()
+	v, err := m.Visualization(dataMx, []string{"first"})
+	assert.NoError(err)
+	assert.Equal(mSom.Grid.Size, v.Grid.Dims)
+	assert.Equal(mSom.Grid.UShape, v.Grid.Shape)
+	assert.Len(v.Units, units)
+	umatrix, err := m.UMatrixValues()
+	assert.NoError(err)
+	hits, err := m.HitMap(dataMx)
+	assert.NoError(err)
+	for i, u := range v.Units {
+		assert.Equal(i, u.Unit)
+		assert.Equal(umatrix[i], u.UMatrix)
+		assert.Equal(hits[i], u.Hits)
+		assert.Equal(mat64.Row(nil, i, m.grid.coords), u.Coords)
+		// polygons are closed and fit into the rendered layer
+		assert.Equal(u.Polygon[0], u.Polygon[len(u.Polygon)-1])
+		for _, p := range u.Polygon {
+			assert.True(p[0] >= 0 && p[0] <= v.Grid.Width)
+			assert.True(p[1] >= 0 && p[1] <= v.Grid.Height)
+		}
+	}
+	assert.Equal("first", v.Units[0].Label)
+	assert.Equal("", v.Units[1].Label)
+	// data and labels are optional
+	v, err = m.Visualization(nil, nil)
+	assert.NoError(err)
+	assert.Equal(0, v.Units[0].Hits)
+	// invalid parameters
+	_, err = m.Visualization(dataMx, make([]string, units+1))
+	assert.Error(err)
+	_, err = m.Visualization(mat64.NewDense(1, 1, []float64{1}), nil)
+	assert.Error(err)
+}
+
+func TestMapVisualizationJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMap(mSom, dataMx)
+	assert.NoError(err)
+	var buf bytes.Buffer
+	assert.NoError(m.VisualizationJSON(&buf, dataMx, []string{"first"}))
+	var doc map[string]interface{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	assert.Contains(doc, "grid")
+	assert.Contains(doc, "min")
+	units := doc["units"].([]interface{})
+	assert.Len(units, m.grid.Units())
+	unit := units[0].(map[string]interface{})
+	for _, key := range []string{"unit", "coords", "polygon", "umatrix", "hits", "label"} {
+		assert.Contains(unit, key)
+	}
+	assert.NotContains(units[1], "label")
+	// 1D grid units are squares
+	m1 := *m
+	m1.grid = &Grid{size: []int{3}, ushape: "rectangle", gtype: "planar"}
+	m1.grid.coords, err = GridCoords("rectangle", m1.grid.size)
+	assert.NoError(err)
+	m1.codebook = mat64.NewDense(3, 1, []float64{0, 1, 2})
+	v, err := m1.Visualization(nil, nil)
+	assert.NoError(err)
+	assert.Equal([][2]float64{{60, 10}, {110, 10}, {110, 60}, {60, 60}, {60, 10}}, v.Units[1].Polygon)
+	assert.Equal(170.0, v.Grid.Width)
+	assert.Error(m.VisualizationJSON(failingWriter{}, nil, nil))
+}
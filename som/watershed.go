@@ -0,0 +1,66 @@
+package som
+
+import (
+	"sort"
+)
+
+// Segmentation holds segmentation of the map into regions
+type Segmentation struct {
+	// Regions holds region IDs of all map units
+	Regions []int
+	// Boundaries holds pairs of neighbouring map units which belong to different regions
+	Boundaries [][2]int
+}
+
+// Watershed segments the map into regions by flooding its U-Matrix from local minima: the units are visited
+// in the order of increasing U-Matrix values and every unit joins the region of its lowest already flooded
+// neighbour. Units without flooded neighbours are local minima which start new regions. Ties are resolved
+// in favour of the units with smaller indices. Region IDs are numbered from 0 in the order the regions were started.
+// The regions can be rendered using ClusterUMatrix. It returns error if the U-Matrix could not be computed.
+func (m Map) Watershed() (*Segmentation, error) {
+	umatrix, _, _, err := umatrixValues(m.codebook, m.grid, m.metric)
+	if err != nil {
+		return nil, err
+	}
+	neighbs, err := unitNeighbours(m.grid)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]int, len(umatrix))
+	for i := range units {
+		units[i] = i
+	}
+	sort.SliceStable(units, func(a, b int) bool { return umatrix[units[a]] < umatrix[units[b]] })
+	regions := make([]int, len(umatrix))
+	for i := range regions {
+		regions[i] = -1
+	}
+	next := 0
+	for _, unit := range units {
+		lowest := -1
+		for _, n := range neighbs[unit] {
+			if regions[n] != -1 && (lowest == -1 || umatrix[n] < umatrix[lowest]) {
+				lowest = n
+			}
+		}
+		if lowest == -1 {
+			regions[unit] = next
+			next++
+			continue
+		}
+		regions[unit] = regions[lowest]
+	}
+	boundaries := [][2]int{}
+	for unit, ns := range neighbs {
+		for _, n := range ns {
+			if unit < n && regions[unit] != regions[n] {
+				boundaries = append(boundaries, [2]int{unit, n})
+			}
+		}
+	}
+
+	return &Segmentation{
+		Regions:    regions,
+		Boundaries: boundaries,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package som
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatershed(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&GridConfig{Size: []int{1, 6}, Type: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(6, 1, []float64{0.0, 1.0, 2.0, 10.0, 11.0, 12.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	seg, err := m.Watershed()
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 1, 1, 1}, seg.Regions)
+	assert.Equal([][2]int{{2, 3}}, seg.Boundaries)
+	// regions can be rendered
+	var buf bytes.Buffer
+	assert.NoError(m.ClusterUMatrix(&buf, seg.Regions, "svg", "Watershed"))
+	assert.Contains(buf.String(), "Watershed")
+}
+
+func TestWatershedGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	// two triangles connected by a single edge
+	graph := mat64.NewDense(6, 6, []float64{
+		0, 1, 1, 0, 0, 0,
+		1, 0, 1, 0, 0, 0,
+		1, 1, 0, 1, 0, 0,
+		0, 0, 1, 0, 1, 1,
+		0, 0, 0, 1, 0, 1,
+		0, 0, 0, 1, 1, 0,
+	})
+	grid, err := NewGrid(&GridConfig{Type: "graph", Graph: graph})
+	assert.NoError(err)
+	m := &Map{
+		codebook: mat64.NewDense(6, 1, []float64{0.0, 0.0, 1.0, 10.0, 11.0, 11.0}),
+		grid:     grid,
+		metric:   "euclidean",
+	}
+	seg, err := m.Watershed()
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 1, 1, 1}, seg.Regions)
+	assert.Equal([][2]int{{2, 3}}, seg.Boundaries)
+}